@@ -0,0 +1,266 @@
+// Package caldav mirrors tasks to a CalDAV task list (RFC 4791) as VTODOs
+// and keeps both sides in sync
+package caldav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrPreconditionFailed is returned when a resource changed on the server
+// since its ETag was read
+var ErrPreconditionFailed = errors.New("resource changed on the server")
+
+// ErrNotFound is returned when a resource doesn't exist on the server
+var ErrNotFound = errors.New("resource not found on the server")
+
+// Object is a calendar resource on the server
+type Object struct {
+	Href string // Path of the resource on the server
+	ETag string
+	Data string // iCalendar data
+}
+
+// Client reads and writes the resources of one calendar collection
+type Client struct {
+	URL      string // Collection URL
+	Username string
+	Password string
+	HTTP     *http.Client
+}
+
+// NewClient creates a client for the calendar collection at collectionURL
+func NewClient(collectionURL, username, password string) (*Client, error) {
+	u, err := url.Parse(collectionURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid CalDAV URL: %s", collectionURL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &Client{
+		URL:      u.String(),
+		Username: username,
+		Password: password,
+		HTTP:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// calendarQuery asks for the ETag and data of every VTODO in the collection
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <D:getetag/>
+    <C:calendar-data/>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VTODO"/>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+// multistatus is the body of a 207 Multi-Status response
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				ETag         string `xml:"DAV: getetag"`
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// ListTodos returns every resource in the collection containing a VTODO
+func (c *Client) ListTodos() ([]Object, error) {
+	req, err := c.newRequest("REPORT", c.URL, strings.NewReader(calendarQuery))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Depth", "1")
+	req.Header.Set("Content-Type", `application/xml; charset="utf-8"`)
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("CalDAV request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, statusError("REPORT", c.URL, resp)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("invalid REPORT response: %w", err)
+	}
+
+	var objects []Object
+	for _, r := range ms.Responses {
+		for _, ps := range r.Propstat {
+			if !strings.Contains(ps.Status, " 200 ") || ps.Prop.CalendarData == "" {
+				continue
+			}
+			objects = append(objects, Object{
+				Href: c.hrefPath(r.Href),
+				ETag: ps.Prop.ETag,
+				Data: ps.Prop.CalendarData,
+			})
+		}
+	}
+	return objects, nil
+}
+
+// Get fetches a single resource
+func (c *Client) Get(href string) (*Object, error) {
+	req, err := c.newRequest(http.MethodGet, c.resolve(href), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("CalDAV request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError("GET", href, resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", href, err)
+	}
+	return &Object{Href: href, ETag: resp.Header.Get("ETag"), Data: string(data)}, nil
+}
+
+// Put stores a resource and returns its new ETag. With an empty etag the
+// resource must not exist yet; otherwise it must still have that ETag.
+// Either failed precondition returns ErrPreconditionFailed.
+func (c *Client) Put(href, data, etag string) (string, error) {
+	req, err := c.newRequest(http.MethodPut, c.resolve(href), strings.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	if etag == "" {
+		req.Header.Set("If-None-Match", "*")
+	} else {
+		req.Header.Set("If-Match", etag)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("CalDAV request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+	case http.StatusPreconditionFailed:
+		return "", ErrPreconditionFailed
+	default:
+		return "", statusError("PUT", href, resp)
+	}
+
+	// Servers that rewrite the data may leave out the ETag; fetch it
+	if newETag := resp.Header.Get("ETag"); newETag != "" {
+		return newETag, nil
+	}
+	obj, err := c.Get(href)
+	if err != nil {
+		return "", err
+	}
+	return obj.ETag, nil
+}
+
+// Delete removes a resource if it still has the given ETag
+func (c *Client) Delete(href, etag string) error {
+	req, err := c.newRequest(http.MethodDelete, c.resolve(href), nil)
+	if err != nil {
+		return err
+	}
+	if etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("CalDAV request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	case http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	default:
+		return statusError("DELETE", href, resp)
+	}
+}
+
+// NewHref returns the path for a new resource with the given UID
+func (c *Client) NewHref(uid string) string {
+	u, _ := url.Parse(c.URL)
+	return path.Join(u.EscapedPath(), url.PathEscape(uid)+".ics")
+}
+
+// newRequest builds a request with the client's credentials
+func (c *Client) newRequest(method, target string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	return req, nil
+}
+
+// resolve turns an href into an absolute URL on the server
+func (c *Client) resolve(href string) string {
+	base, err := url.Parse(c.URL)
+	if err != nil {
+		return href
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// hrefPath normalizes an href from a response to a server path, so hrefs
+// compare equal however the server wrote them
+func (c *Client) hrefPath(href string) string {
+	u, err := url.Parse(c.resolve(href))
+	if err != nil {
+		return href
+	}
+	return u.EscapedPath()
+}
+
+// statusError describes an unexpected response
+func statusError(method, target string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	msg := strings.TrimSpace(string(body))
+	if msg == "" {
+		return fmt.Errorf("%s %s: %s", method, target, resp.Status)
+	}
+	return fmt.Errorf("%s %s: %s: %s", method, target, resp.Status, msg)
+}
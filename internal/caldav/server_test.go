@@ -0,0 +1,155 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pdxmph/denote-tasks/internal/ical"
+)
+
+// Server is an in-memory stand-in for a CalDAV server. Any path ending in
+// "/" acts as a calendar collection holding the resources below it. It
+// implements just what Client uses (REPORT, PROPFIND, GET, PUT and DELETE
+// with ETag preconditions), for the sync tests.
+type Server struct {
+	mu      sync.Mutex
+	objects map[string]*Object // Keyed by path
+	version int                // Source of ETags
+}
+
+// NewServer creates an empty stand-in server
+func NewServer() *Server {
+	return &Server{objects: make(map[string]*Object)}
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := r.URL.EscapedPath()
+	switch r.Method {
+	case "OPTIONS":
+		w.Header().Set("DAV", "1, calendar-access")
+		w.Header().Set("Allow", "OPTIONS, GET, PUT, DELETE, PROPFIND, REPORT")
+	case "REPORT", "PROPFIND":
+		s.serveCollection(w, p, r.Method == "REPORT")
+	case http.MethodGet:
+		obj, ok := s.objects[p]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("ETag", obj.ETag)
+		io.WriteString(w, obj.Data)
+	case http.MethodPut:
+		s.put(w, r, p)
+	case http.MethodDelete:
+		obj, ok := s.objects[p]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && match != obj.ETag {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		delete(s.objects, p)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// put stores a resource, honoring If-Match and If-None-Match
+func (s *Server) put(w http.ResponseWriter, r *http.Request, p string) {
+	if strings.HasSuffix(p, "/") {
+		http.Error(w, "cannot PUT to a collection", http.StatusMethodNotAllowed)
+		return
+	}
+
+	existing, exists := s.objects[p]
+	if r.Header.Get("If-None-Match") == "*" && exists {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	if match := r.Header.Get("If-Match"); match != "" && (!exists || match != existing.ETag) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := ical.Parse(string(data)); err != nil {
+		http.Error(w, "invalid calendar data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.version++
+	obj := &Object{Href: p, ETag: fmt.Sprintf(`"%d"`, s.version), Data: string(data)}
+	s.objects[p] = obj
+
+	w.Header().Set("ETag", obj.ETag)
+	if exists {
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+// davResponse is one response element of a multistatus body
+type davResponse struct {
+	Href         string `xml:"D:href"`
+	Status       string `xml:"D:propstat>D:status"`
+	ETag         string `xml:"D:propstat>D:prop>D:getetag"`
+	CalendarData string `xml:"D:propstat>D:prop>C:calendar-data,omitempty"`
+}
+
+// serveCollection lists the resources below a collection path
+func (s *Server) serveCollection(w http.ResponseWriter, p string, withData bool) {
+	if !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+
+	var paths []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, p) && !strings.Contains(key[len(p):], "/") {
+			paths = append(paths, key)
+		}
+	}
+	sort.Strings(paths)
+
+	body := struct {
+		XMLName   xml.Name      `xml:"D:multistatus"`
+		DAV       string        `xml:"xmlns:D,attr"`
+		CalDAV    string        `xml:"xmlns:C,attr"`
+		Responses []davResponse `xml:"D:response"`
+	}{DAV: "DAV:", CalDAV: "urn:ietf:params:xml:ns:caldav"}
+
+	for _, key := range paths {
+		obj := s.objects[key]
+		// The REPORT asks only for VTODOs
+		if withData && !strings.Contains(obj.Data, "BEGIN:VTODO") {
+			continue
+		}
+		resp := davResponse{Href: key, Status: "HTTP/1.1 200 OK", ETag: obj.ETag}
+		if withData {
+			resp.CalendarData = obj.Data
+		}
+		body.Responses = append(body.Responses, resp)
+	}
+
+	w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(body)
+}
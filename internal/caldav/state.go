@@ -0,0 +1,73 @@
+package caldav
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+// StateFileName is the sync state stored in the notes directory
+const StateFileName = ".denote-tasks-sync.json"
+
+// stateVersion is bumped whenever the state layout changes
+const stateVersion = 1
+
+// SyncedTask is what a task looked like, locally and on the server, when it
+// was last synced
+type SyncedTask struct {
+	UID      string    `json:"uid"`
+	Href     string    `json:"href"`
+	ETag     string    `json:"etag"`
+	Fields   Fields    `json:"fields"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// State maps Denote IDs to their last-synced state for one collection
+type State struct {
+	Version int                    `json:"version"`
+	URL     string                 `json:"url"`
+	Tasks   map[string]*SyncedTask `json:"tasks"`
+}
+
+// LoadState reads the sync state for a directory. A missing state, or one
+// recorded for a different collection URL, starts out empty.
+func LoadState(dir, collectionURL string) (*State, error) {
+	state := &State{
+		Version: stateVersion,
+		URL:     collectionURL,
+		Tasks:   make(map[string]*SyncedTask),
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, StateFileName))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+
+	var loaded State
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return nil, fmt.Errorf("failed to parse sync state %s: %w", StateFileName, err)
+	}
+	if loaded.Version != stateVersion || loaded.URL != collectionURL || loaded.Tasks == nil {
+		return state, nil
+	}
+	return &loaded, nil
+}
+
+// Save writes the sync state to the directory
+func (s *State) Save(dir string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sync state: %w", err)
+	}
+	if err := denote.WriteFileAtomic(filepath.Join(dir, StateFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	return nil
+}
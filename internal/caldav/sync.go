@@ -0,0 +1,594 @@
+package caldav
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/ical"
+	"github.com/pdxmph/denote-tasks/internal/task"
+)
+
+// Conflict policies for tasks changed both locally and on the server
+const (
+	PolicyNewest = "newest" // Keep the side modified last
+	PolicyLocal  = "local"  // Local changes win
+	PolicyRemote = "remote" // Server changes win
+	PolicySkip   = "skip"   // Leave both sides alone and report the conflict
+)
+
+// Policies lists the valid conflict policies
+var Policies = []string{PolicyNewest, PolicyLocal, PolicyRemote, PolicySkip}
+
+// IsValidPolicy checks if a conflict policy is valid
+func IsValidPolicy(policy string) bool {
+	for _, p := range Policies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// Change actions
+const (
+	ActionCreate   = "create"   // Local task added to the server
+	ActionPush     = "push"     // Local changes sent to the server
+	ActionPull     = "pull"     // Server changes applied locally
+	ActionImport   = "import"   // Server task created locally
+	ActionDelete   = "delete"   // Server task deleted after the local file was removed
+	ActionDrop     = "drop"     // Local task dropped after it was deleted on the server
+	ActionConflict = "conflict" // Changed on both sides and left alone
+)
+
+// Change is one action taken (or, in a dry run, to be taken) by a sync
+type Change struct {
+	Action   string
+	DenoteID string
+	Title    string
+	Detail   string
+}
+
+// Result summarizes a sync run
+type Result struct {
+	Changes []Change
+	Errors  []error
+}
+
+// Count returns the number of changes with the given action
+func (r *Result) Count(action string) int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Options configure a sync run
+type Options struct {
+	Policy string // Conflict policy; PolicyNewest if empty
+	Area   string // Only start syncing tasks in this area
+	DryRun bool   // Report changes without making them
+
+	FileType string // Denote file type of imported tasks; markdown-yaml if empty
+}
+
+// Fields are the task properties mirrored to a VTODO
+type Fields struct {
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	Priority  string `json:"priority,omitempty"`
+	DueDate   string `json:"due_date,omitempty"`
+	StartDate string `json:"start_date,omitempty"`
+	Area      string `json:"area,omitempty"`
+}
+
+// TaskFields returns the synced fields of a task
+func TaskFields(meta *denote.TaskMetadata) Fields {
+	status := meta.Status
+	if status == "" {
+		status = denote.TaskStatusOpen
+	}
+	return Fields{
+		Title:     meta.Title,
+		Status:    status,
+		Priority:  meta.Priority,
+		DueDate:   meta.DueDate,
+		StartDate: meta.StartDate,
+		Area:      meta.Area,
+	}
+}
+
+// todoFields reads the synced fields from a VTODO. currentStatus is kept
+// when the VTODO status maps to it.
+func todoFields(todo *ical.Component, currentStatus string) Fields {
+	if currentStatus == "" {
+		currentStatus = denote.TaskStatusOpen
+	}
+	f := Fields{
+		Title:     todo.Text("SUMMARY"),
+		Status:    ical.TaskStatusFromICal(todo.Value("STATUS"), currentStatus),
+		Priority:  ical.PriorityFromICal(todo.Value("PRIORITY")),
+		DueDate:   ical.DateFromICal(todo.Value("DUE")),
+		StartDate: ical.DateFromICal(todo.Value("DTSTART")),
+	}
+	if categories := todo.Value("CATEGORIES"); categories != "" {
+		f.Area = ical.UnescapeText(firstCategory(categories))
+	}
+	return f
+}
+
+// apply copies the fields into task metadata
+func (f Fields) apply(meta *denote.TaskMetadata) {
+	meta.Title = f.Title
+	meta.SetStatus(f.Status, time.Now())
+	meta.Priority = f.Priority
+	meta.DueDate = f.DueDate
+	meta.StartDate = f.StartDate
+	meta.Area = f.Area
+}
+
+// diff lists the names of the fields that differ between f and other
+func (f Fields) diff(other Fields) string {
+	var names []string
+	if f.Title != other.Title {
+		names = append(names, "title")
+	}
+	if f.Status != other.Status {
+		names = append(names, "status")
+	}
+	if f.Priority != other.Priority {
+		names = append(names, "priority")
+	}
+	if f.DueDate != other.DueDate {
+		names = append(names, "due")
+	}
+	if f.StartDate != other.StartDate {
+		names = append(names, "start")
+	}
+	if f.Area != other.Area {
+		names = append(names, "area")
+	}
+	return strings.Join(names, ", ")
+}
+
+// firstCategory returns the first value of an escaped CATEGORIES list
+func firstCategory(categories string) string {
+	for i := 0; i < len(categories); i++ {
+		switch categories[i] {
+		case '\\':
+			i++
+		case ',':
+			return categories[:i]
+		}
+	}
+	return categories
+}
+
+// remoteTodo is a VTODO resource on the server
+type remoteTodo struct {
+	Object
+	cal     *ical.Component
+	todo    *ical.Component
+	uid     string
+	claimed bool // Matched to a local task or state entry
+}
+
+// syncer holds the state of one sync run
+type syncer struct {
+	client *Client
+	dir    string
+	opts   Options
+	state  *State
+	now    time.Time
+	result *Result
+
+	remotes []*remoteTodo
+	byHref  map[string]*remoteTodo
+	byUID   map[string]*remoteTodo
+}
+
+// Sync reconciles the tasks in dir with the server collection. Tasks synced
+// before are always kept in sync; other open tasks are added when they
+// match opts.Area. The state is saved even when some tasks fail, which are
+// reported in the result.
+func Sync(client *Client, dir string, tasks []*denote.Task, opts Options) (*Result, error) {
+	if opts.Policy == "" {
+		opts.Policy = PolicyNewest
+	}
+	if !IsValidPolicy(opts.Policy) {
+		return nil, fmt.Errorf("invalid conflict policy: %s (valid: %s)", opts.Policy, strings.Join(Policies, ", "))
+	}
+
+	state, err := LoadState(dir, client.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := client.ListTodos()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &syncer{
+		client: client,
+		dir:    dir,
+		opts:   opts,
+		state:  state,
+		now:    time.Now(),
+		result: &Result{},
+		byHref: make(map[string]*remoteTodo),
+		byUID:  make(map[string]*remoteTodo),
+	}
+
+	for _, obj := range objects {
+		cal, err := ical.Parse(obj.Data)
+		if err != nil {
+			s.result.Errors = append(s.result.Errors, fmt.Errorf("skipping %s: %w", obj.Href, err))
+			continue
+		}
+		todos := cal.Find("VTODO")
+		if len(todos) == 0 {
+			continue
+		}
+		r := &remoteTodo{Object: obj, cal: cal, todo: todos[0], uid: todos[0].Value("UID")}
+		s.remotes = append(s.remotes, r)
+		s.byHref[r.Href] = r
+		if r.uid != "" {
+			s.byUID[r.uid] = r
+		}
+	}
+
+	// Work in Denote ID order so runs are repeatable
+	sorted := make([]*denote.Task, len(tasks))
+	copy(sorted, tasks)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].File.ID < sorted[j].File.ID
+	})
+
+	local := make(map[string]bool)
+	for _, t := range sorted {
+		local[t.File.ID] = true
+		s.syncTask(t)
+	}
+
+	var removed []string
+	for id := range state.Tasks {
+		if !local[id] {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	for _, id := range removed {
+		s.syncRemoved(id)
+	}
+
+	for _, r := range s.remotes {
+		if !r.claimed {
+			s.importTodo(r)
+		}
+	}
+
+	if !opts.DryRun {
+		if err := state.Save(dir); err != nil {
+			return s.result, err
+		}
+	}
+	return s.result, nil
+}
+
+// syncTask reconciles one local task with the server
+func (s *syncer) syncTask(t *denote.Task) {
+	id := t.File.ID
+	local := TaskFields(&t.TaskMetadata)
+
+	st := s.state.Tasks[id]
+	if st == nil {
+		uid := ical.UID(id)
+		r := s.byUID[uid]
+		if r == nil || r.claimed {
+			if s.wantsNew(t) {
+				s.create(t, uid, local)
+			}
+			return
+		}
+
+		// Already on the server without a state entry, e.g. after the
+		// state file was removed
+		r.claimed = true
+		remote := todoFields(r.todo, local.Status)
+		if remote == local {
+			s.record(id, r, r.ETag, local)
+			return
+		}
+		s.resolve(t, r, local, remote)
+		return
+	}
+
+	r := s.byHref[st.Href]
+	if r == nil {
+		r = s.byUID[st.UID]
+	}
+	if r == nil || r.claimed {
+		s.remoteDeleted(t, st, local)
+		return
+	}
+	r.claimed = true
+
+	remote := todoFields(r.todo, st.Fields.Status)
+	localChanged := local != st.Fields
+	remoteChanged := r.ETag != st.ETag && remote != st.Fields
+
+	switch {
+	case !localChanged && !remoteChanged:
+		// The server copy may have changed in ways sync ignores
+		st.Href = r.Href
+		st.ETag = r.ETag
+	case localChanged && !remoteChanged:
+		s.push(t, r, local, local.diff(st.Fields))
+	case remoteChanged && !localChanged:
+		s.pull(t, r, remote, remote.diff(st.Fields))
+	case local == remote:
+		s.record(id, r, r.ETag, local)
+	default:
+		s.resolve(t, r, local, remote)
+	}
+}
+
+// remoteDeleted handles a synced task that is gone from the server. If it
+// changed locally since the last sync it is sent again; otherwise it is
+// dropped locally.
+func (s *syncer) remoteDeleted(t *denote.Task, st *SyncedTask, local Fields) {
+	if local != st.Fields {
+		delete(s.state.Tasks, t.File.ID)
+		s.create(t, st.UID, local)
+		return
+	}
+
+	delete(s.state.Tasks, t.File.ID)
+	if local.Status == denote.TaskStatusDone || local.Status == denote.TaskStatusDropped {
+		return
+	}
+
+	if !s.opts.DryRun {
+		err := task.ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+			meta.SetStatus(denote.TaskStatusDropped, s.now)
+			meta.StopTimer(s.now)
+			return nil
+		})
+		if err != nil {
+			s.fail(t, err)
+			return
+		}
+	}
+	s.add(ActionDrop, t.File.ID, local.Title, "deleted on server")
+}
+
+// syncRemoved handles a synced task whose file is gone. The server copy is
+// deleted unless it changed since the last sync, in which case it is
+// imported again.
+func (s *syncer) syncRemoved(id string) {
+	st := s.state.Tasks[id]
+	delete(s.state.Tasks, id)
+
+	r := s.byHref[st.Href]
+	if r == nil {
+		r = s.byUID[st.UID]
+	}
+	if r == nil || r.claimed {
+		return
+	}
+	if r.ETag != st.ETag && todoFields(r.todo, st.Fields.Status) != st.Fields {
+		return
+	}
+
+	r.claimed = true
+	if !s.opts.DryRun {
+		if err := s.client.Delete(r.Href, r.ETag); err != nil {
+			s.result.Errors = append(s.result.Errors, fmt.Errorf("%s: %w", st.Fields.Title, err))
+			return
+		}
+	}
+	s.add(ActionDelete, id, st.Fields.Title, "removed locally")
+}
+
+// importTodo creates a local task for a VTODO added on the server. Finished
+// VTODOs and those outside the sync area are left alone.
+func (s *syncer) importTodo(r *remoteTodo) {
+	fields := todoFields(r.todo, denote.TaskStatusOpen)
+	if fields.Status == denote.TaskStatusDone || fields.Status == denote.TaskStatusDropped {
+		return
+	}
+	if s.opts.Area != "" && fields.Area != s.opts.Area {
+		return
+	}
+	if fields.Title == "" {
+		fields.Title = "Untitled task"
+	}
+
+	if s.opts.DryRun {
+		s.add(ActionImport, "", fields.Title, "")
+		return
+	}
+
+	t, err := task.CreateTaskAs(s.dir, s.opts.FileType, fields.Title, "", nil, fields.Area)
+	if err != nil {
+		s.result.Errors = append(s.result.Errors, fmt.Errorf("failed to import %s: %w", fields.Title, err))
+		return
+	}
+	if err := task.ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+		fields.apply(meta)
+		return nil
+	}); err != nil {
+		s.fail(t, err)
+		return
+	}
+
+	s.record(t.File.ID, r, r.ETag, fields)
+	s.add(ActionImport, t.File.ID, fields.Title, "")
+}
+
+// resolve settles a task changed on both sides according to the policy
+func (s *syncer) resolve(t *denote.Task, r *remoteTodo, local, remote Fields) {
+	winner := s.opts.Policy
+	if winner == PolicyNewest {
+		winner = PolicyLocal
+		if modified, ok := remoteModified(r.todo); ok && modified.After(t.ModTime) {
+			winner = PolicyRemote
+		}
+	}
+
+	conflict := "conflict on " + local.diff(remote)
+	switch winner {
+	case PolicyLocal:
+		s.push(t, r, local, conflict+", kept local")
+	case PolicyRemote:
+		s.pull(t, r, remote, conflict+", kept server")
+	default:
+		s.add(ActionConflict, t.File.ID, local.Title, conflict)
+	}
+}
+
+// create adds a local task to the server
+func (s *syncer) create(t *denote.Task, uid string, fields Fields) {
+	if !s.opts.DryRun {
+		href := s.client.NewHref(uid)
+		etag, err := s.client.Put(href, todoData(nil, uid, fields, s.now), "")
+		if err != nil {
+			s.fail(t, err)
+			return
+		}
+		s.state.Tasks[t.File.ID] = &SyncedTask{UID: uid, Href: href, ETag: etag, Fields: fields, SyncedAt: s.now}
+	}
+	s.add(ActionCreate, t.File.ID, fields.Title, "")
+}
+
+// push sends local fields to the server copy of a task
+func (s *syncer) push(t *denote.Task, r *remoteTodo, fields Fields, detail string) {
+	if !s.opts.DryRun {
+		etag, err := s.client.Put(r.Href, todoData(r, r.uid, fields, s.now), r.ETag)
+		if errors.Is(err, ErrPreconditionFailed) {
+			err = fmt.Errorf("changed on the server during sync; run sync again")
+		}
+		if err != nil {
+			s.fail(t, err)
+			return
+		}
+		s.record(t.File.ID, r, etag, fields)
+	}
+	s.add(ActionPush, t.File.ID, fields.Title, detail)
+}
+
+// pull applies server fields to a local task. Completing a recurring task
+// creates its next instance, as marking it done locally would.
+func (s *syncer) pull(t *denote.Task, r *remoteTodo, fields Fields, detail string) {
+	if !s.opts.DryRun {
+		wasDone := t.TaskMetadata.Status == denote.TaskStatusDone
+		err := task.ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+			fields.apply(meta)
+			if meta.Status != denote.TaskStatusOpen {
+				meta.StopTimer(s.now)
+			}
+			return nil
+		})
+		if err != nil {
+			s.fail(t, err)
+			return
+		}
+		s.record(t.File.ID, r, r.ETag, fields)
+		fields.apply(&t.TaskMetadata)
+
+		if fields.Status == denote.TaskStatusDone && !wasDone && t.TaskMetadata.Recurrence != "" {
+			if next, err := task.CreateNextInstance(t, s.now); err != nil {
+				s.fail(t, err)
+			} else {
+				detail += fmt.Sprintf("; next occurrence is task ID %d", next.TaskMetadata.IndexID)
+			}
+		}
+	}
+	s.add(ActionPull, t.File.ID, fields.Title, detail)
+}
+
+// record saves the synced state of a task
+func (s *syncer) record(id string, r *remoteTodo, etag string, fields Fields) {
+	uid := r.uid
+	if uid == "" {
+		uid = ical.UID(id)
+	}
+	s.state.Tasks[id] = &SyncedTask{UID: uid, Href: r.Href, ETag: etag, Fields: fields, SyncedAt: s.now}
+}
+
+// wantsNew reports whether a task not synced before should be added to the
+// server
+func (s *syncer) wantsNew(t *denote.Task) bool {
+	if t.TaskMetadata.Status == denote.TaskStatusDone || t.TaskMetadata.Status == denote.TaskStatusDropped {
+		return false
+	}
+	return s.opts.Area == "" || t.TaskMetadata.Area == s.opts.Area
+}
+
+// add records a change
+func (s *syncer) add(action, id, title, detail string) {
+	s.result.Changes = append(s.result.Changes, Change{Action: action, DenoteID: id, Title: title, Detail: detail})
+}
+
+// fail records an error for a task
+func (s *syncer) fail(t *denote.Task, err error) {
+	s.result.Errors = append(s.result.Errors, fmt.Errorf("task ID %d (%s): %w", t.TaskMetadata.IndexID, t.TaskMetadata.Title, err))
+}
+
+// remoteModified returns when a VTODO was last modified
+func remoteModified(todo *ical.Component) (time.Time, bool) {
+	if t, ok := ical.ParseTimeValue(todo.Value("LAST-MODIFIED")); ok {
+		return t, true
+	}
+	return ical.ParseTimeValue(todo.Value("DTSTAMP"))
+}
+
+// todoData renders fields as a calendar holding one VTODO. When updating
+// an existing resource, properties sync doesn't manage (alarms, notes added
+// on a phone) are kept.
+func todoData(r *remoteTodo, uid string, fields Fields, now time.Time) string {
+	var cal, todo *ical.Component
+	if r != nil {
+		cal, todo = r.cal, r.todo
+	} else {
+		cal = ical.NewCalendar()
+		todo = ical.NewComponent("VTODO")
+		todo.Add("UID", uid)
+		cal.Children = append(cal.Children, todo)
+	}
+
+	stamp := ical.TimeValue(now)
+	todo.Set("DTSTAMP", stamp)
+	todo.Set("LAST-MODIFIED", stamp)
+	todo.Set("SUMMARY", ical.EscapeText(fields.Title))
+	todo.Set("STATUS", ical.TaskStatus(fields.Status))
+
+	priority := ""
+	if p := ical.Priority(fields.Priority); p > 0 {
+		priority = strconv.Itoa(p)
+	}
+	todo.Set("PRIORITY", priority)
+
+	setDate(todo, "DTSTART", fields.StartDate)
+	setDate(todo, "DUE", fields.DueDate)
+
+	todo.Set("CATEGORIES", ical.EscapeText(fields.Area))
+	return cal.String()
+}
+
+// setDate sets a DATE property, or removes it when date is empty
+func setDate(todo *ical.Component, prop, date string) {
+	if value, ok := ical.DateValue(date); ok {
+		todo.Set(prop, value, "VALUE", "DATE")
+	} else {
+		todo.Remove(prop)
+	}
+}
@@ -0,0 +1,287 @@
+package caldav
+
+import (
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/ical"
+	"github.com/pdxmph/denote-tasks/internal/task"
+)
+
+// syncEnv is a notes directory and a stand-in server to sync it with
+type syncEnv struct {
+	t      *testing.T
+	dir    string
+	client *Client
+}
+
+func newSyncEnv(t *testing.T) *syncEnv {
+	t.Helper()
+	srv := httptest.NewServer(NewServer())
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(srv.URL+"/tasks/", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &syncEnv{t: t, dir: t.TempDir(), client: client}
+}
+
+// tasks scans the notes directory
+func (e *syncEnv) tasks() []*denote.Task {
+	e.t.Helper()
+	tasks, err := (&denote.Scanner{BaseDir: e.dir, NoIndex: true}).FindTasks()
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return tasks
+}
+
+// task returns the only local task with title
+func (e *syncEnv) task(title string) *denote.Task {
+	e.t.Helper()
+	for _, t := range e.tasks() {
+		if t.TaskMetadata.Title == title {
+			return t
+		}
+	}
+	e.t.Fatalf("no local task %q", title)
+	return nil
+}
+
+// sync runs a sync and fails the test on any error
+func (e *syncEnv) sync(policy string) *Result {
+	e.t.Helper()
+	res, err := Sync(e.client, e.dir, e.tasks(), Options{Policy: policy})
+	if err != nil {
+		e.t.Fatalf("Sync: %v", err)
+	}
+	if len(res.Errors) > 0 {
+		e.t.Fatalf("sync errors: %v", res.Errors)
+	}
+	return res
+}
+
+// newTask creates a local task
+func (e *syncEnv) newTask(title string) *denote.Task {
+	e.t.Helper()
+	t, err := task.CreateTaskAs(e.dir, "", title, "", nil, "")
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return t
+}
+
+// modify changes a local task, moving its modification time to modTime
+func (e *syncEnv) modify(t *denote.Task, modTime time.Time, fn func(meta *denote.TaskMetadata)) {
+	e.t.Helper()
+	err := task.ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+		fn(meta)
+		return nil
+	})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	if err := os.Chtimes(t.File.Path, modTime, modTime); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+// remote returns the only VTODO on the server with the given summary
+func (e *syncEnv) remote(summary string) (Object, *ical.Component, *ical.Component) {
+	e.t.Helper()
+	objects, err := e.client.ListTodos()
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	for _, obj := range objects {
+		cal, err := ical.Parse(obj.Data)
+		if err != nil {
+			e.t.Fatal(err)
+		}
+		if todo := cal.Find("VTODO")[0]; todo.Text("SUMMARY") == summary {
+			return obj, cal, todo
+		}
+	}
+	e.t.Fatalf("no VTODO %q on the server", summary)
+	return Object{}, nil, nil
+}
+
+// modifyRemote changes a VTODO on the server as of modified
+func (e *syncEnv) modifyRemote(summary string, modified time.Time, fn func(todo *ical.Component)) {
+	e.t.Helper()
+	obj, cal, todo := e.remote(summary)
+	fn(todo)
+	todo.Set("LAST-MODIFIED", ical.TimeValue(modified))
+	if _, err := e.client.Put(obj.Href, cal.String(), obj.ETag); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+// remoteCount returns the number of VTODOs on the server
+func (e *syncEnv) remoteCount() int {
+	e.t.Helper()
+	objects, err := e.client.ListTodos()
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return len(objects)
+}
+
+// assertChanges checks the number of changes of each action in res
+func assertChanges(t *testing.T, res *Result, want map[string]int) {
+	t.Helper()
+	for _, action := range []string{ActionCreate, ActionPush, ActionPull, ActionImport, ActionDelete, ActionDrop, ActionConflict} {
+		if got := res.Count(action); got != want[action] {
+			t.Errorf("%s changes = %d, want %d (%+v)", action, got, want[action], res.Changes)
+		}
+	}
+}
+
+func TestSyncCreateAndImport(t *testing.T) {
+	e := newSyncEnv(t)
+	e.newTask("Write report")
+	finished := e.newTask("Already finished")
+	e.modify(finished, time.Now(), func(meta *denote.TaskMetadata) {
+		meta.Status = denote.TaskStatusDone
+	})
+
+	res := e.sync("")
+	assertChanges(t, res, map[string]int{ActionCreate: 1})
+	if _, _, todo := e.remote("Write report"); todo.Value("STATUS") != "NEEDS-ACTION" {
+		t.Errorf("STATUS = %q, want NEEDS-ACTION", todo.Value("STATUS"))
+	}
+
+	// A VTODO added on the server is imported
+	cal := ical.NewCalendar()
+	todo := ical.NewComponent("VTODO")
+	todo.Add("UID", "phone-1")
+	todo.Add("SUMMARY", "Call the bank")
+	todo.Add("PRIORITY", "1")
+	cal.Children = append(cal.Children, todo)
+	if _, err := e.client.Put(e.client.NewHref("phone-1"), cal.String(), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	res = e.sync("")
+	assertChanges(t, res, map[string]int{ActionImport: 1})
+	if got := e.task("Call the bank").TaskMetadata.Priority; got != "p1" {
+		t.Errorf("imported priority = %q, want p1", got)
+	}
+
+	// Nothing left to do
+	assertChanges(t, e.sync(""), nil)
+	if n := e.remoteCount(); n != 2 {
+		t.Errorf("server has %d VTODOs, want 2", n)
+	}
+}
+
+func TestSyncPushAndPull(t *testing.T) {
+	e := newSyncEnv(t)
+	e.newTask("Plan trip")
+	e.newTask("Book hotel")
+	e.sync("")
+
+	// Local change goes to the server
+	e.modify(e.task("Plan trip"), time.Now(), func(meta *denote.TaskMetadata) {
+		meta.Priority = "p2"
+		meta.DueDate = "2030-05-01"
+	})
+	res := e.sync("")
+	assertChanges(t, res, map[string]int{ActionPush: 1})
+	_, _, todo := e.remote("Plan trip")
+	if todo.Value("PRIORITY") != "5" || todo.Value("DUE") != "20300501" {
+		t.Errorf("pushed PRIORITY=%q DUE=%q, want 5 and 20300501", todo.Value("PRIORITY"), todo.Value("DUE"))
+	}
+
+	// Server change comes back
+	e.modifyRemote("Book hotel", time.Now(), func(todo *ical.Component) {
+		todo.Set("SUMMARY", "Book hotel in Lisbon")
+		todo.Set("STATUS", "COMPLETED")
+	})
+	res = e.sync("")
+	assertChanges(t, res, map[string]int{ActionPull: 1})
+	if got := e.task("Book hotel in Lisbon").TaskMetadata.Status; got != denote.TaskStatusDone {
+		t.Errorf("pulled status = %q, want done", got)
+	}
+
+	assertChanges(t, e.sync(""), nil)
+}
+
+func TestSyncConflictPolicies(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		policy      string
+		remoteNewer bool
+		wantTitle   string // Local title after the sync
+		wantSummary string // Server summary after the sync
+		wantChange  string
+	}{
+		{PolicyLocal, true, "Local title", "Local title", ActionPush},
+		{PolicyRemote, false, "Remote title", "Remote title", ActionPull},
+		{PolicyNewest, true, "Remote title", "Remote title", ActionPull},
+		{PolicyNewest, false, "Local title", "Local title", ActionPush},
+		{PolicySkip, true, "Local title", "Remote title", ActionConflict},
+	}
+
+	for _, tt := range tests {
+		name := tt.policy
+		if tt.remoteNewer {
+			name += "/remote-newer"
+		}
+		t.Run(name, func(t *testing.T) {
+			e := newSyncEnv(t)
+			e.newTask("Original")
+			e.sync("")
+
+			localTime, remoteTime := now.Add(time.Hour), now.Add(-time.Hour)
+			if tt.remoteNewer {
+				localTime, remoteTime = remoteTime, localTime
+			}
+			e.modify(e.task("Original"), localTime, func(meta *denote.TaskMetadata) {
+				meta.Title = "Local title"
+			})
+			e.modifyRemote("Original", remoteTime, func(todo *ical.Component) {
+				todo.Set("SUMMARY", "Remote title")
+			})
+
+			res := e.sync(tt.policy)
+			assertChanges(t, res, map[string]int{tt.wantChange: 1})
+			e.task(tt.wantTitle)
+			e.remote(tt.wantSummary)
+		})
+	}
+}
+
+func TestSyncDeletes(t *testing.T) {
+	e := newSyncEnv(t)
+	e.newTask("Deleted on server")
+	removed := e.newTask("Removed locally")
+	e.sync("")
+
+	// Deleted on the server: dropped locally
+	obj, _, _ := e.remote("Deleted on server")
+	if err := e.client.Delete(obj.Href, obj.ETag); err != nil {
+		t.Fatal(err)
+	}
+	res := e.sync("")
+	assertChanges(t, res, map[string]int{ActionDrop: 1})
+	if got := e.task("Deleted on server").TaskMetadata.Status; got != denote.TaskStatusDropped {
+		t.Errorf("status = %q, want dropped", got)
+	}
+
+	// Removed locally: deleted on the server
+	if err := os.Remove(removed.File.Path); err != nil {
+		t.Fatal(err)
+	}
+	res = e.sync("")
+	assertChanges(t, res, map[string]int{ActionDelete: 1})
+	if n := e.remoteCount(); n != 0 {
+		t.Errorf("server has %d VTODOs, want 0", n)
+	}
+
+	assertChanges(t, e.sync(""), nil)
+}
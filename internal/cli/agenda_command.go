@@ -0,0 +1,320 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pdxmph/denote-tasks/internal/config"
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+// agendaSection is one group of tasks in the agenda
+type agendaSection struct {
+	Key   string // Field in agendaJSON
+	Title string
+	Color *color.Color
+	Tasks []*denote.Task
+}
+
+// taskJSON is a task in JSON output
+type taskJSON struct {
+	ID        int    `json:"id"`
+	DenoteID  string `json:"denote_id"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	Priority  string `json:"priority,omitempty"`
+	DueDate   string `json:"due_date,omitempty"`
+	StartDate string `json:"start_date,omitempty"`
+	Area      string `json:"area,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+	Project   string `json:"project,omitempty"`
+	Completed string `json:"completed,omitempty"` // When done tasks were completed
+	Path      string `json:"path"`
+}
+
+// agendaJSON is the agenda's JSON output
+type agendaJSON struct {
+	Date              string     `json:"date"`
+	Overdue           []taskJSON `json:"overdue"`
+	DueToday          []taskJSON `json:"due_today"`
+	StartingToday     []taskJSON `json:"starting_today"`
+	DueSoon           []taskJSON `json:"due_soon"`
+	RecentlyCompleted []taskJSON `json:"recently_completed"`
+}
+
+// AgendaCommand creates the daily agenda command
+func AgendaCommand(cfg *config.Config) *Command {
+	var days int
+
+	cmd := &Command{
+		Name:  "agenda",
+		Usage: "denote-tasks agenda [--days N]",
+		Description: `Show today's agenda
+
+Prints unfinished tasks that are overdue, due today, starting today or due
+within soon_horizon days, then tasks completed in the last --days days.
+Tasks are listed in the first section they belong to. Completion times
+are taken from file modification times, since tasks don't record when
+they were done. With --json the sections are printed as one JSON object.`,
+		Flags: flag.NewFlagSet("agenda", flag.ExitOnError),
+	}
+
+	cmd.Flags.IntVar(&days, "days", 7, "Show tasks completed within this many days (0 to hide)")
+
+	cmd.Run = func(c *Command, args []string) error {
+		if days < 0 {
+			return fmt.Errorf("invalid --days: %d", days)
+		}
+
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+
+		projectNames := make(map[string]string) // ID -> Title
+		for _, p := range result.Projects {
+			projectNames[p.File.ID] = p.ProjectMetadata.Title
+		}
+
+		if globalFlags.NoColor || color.NoColor {
+			color.NoColor = true
+		}
+
+		sections := buildAgenda(result.Tasks, cfg.SoonHorizon, days, time.Now())
+
+		if globalFlags.JSON {
+			return printAgendaJSON(sections, projectNames)
+		}
+
+		empty := true
+		for _, s := range sections {
+			if len(s.Tasks) == 0 {
+				continue
+			}
+			if !empty {
+				fmt.Println()
+			}
+			empty = false
+			fmt.Println(s.Color.Sprintf("%s (%d):", s.Title, len(s.Tasks)))
+			for _, t := range s.Tasks {
+				fmt.Println(agendaLine(t, projectNames))
+			}
+		}
+		if empty && !globalFlags.Quiet {
+			fmt.Println("Nothing on the agenda")
+		}
+
+		return nil
+	}
+
+	return cmd
+}
+
+// buildAgenda sorts tasks into the agenda's sections. Each unfinished task
+// goes in the first section it qualifies for.
+func buildAgenda(tasks []*denote.Task, horizon, completedDays int, now time.Time) []*agendaSection {
+	today := now.Format("2006-01-02")
+	overdue := &agendaSection{Key: "overdue", Title: "Overdue", Color: color.New(color.FgRed, color.Bold)}
+	dueToday := &agendaSection{Key: "due_today", Title: "Due Today", Color: color.New(color.FgYellow, color.Bold)}
+	starting := &agendaSection{Key: "starting_today", Title: "Starting Today", Color: color.New(color.FgCyan, color.Bold)}
+	soon := &agendaSection{Key: "due_soon", Title: fmt.Sprintf("Due Within %d Days", horizon), Color: color.New(color.Bold)}
+	completed := &agendaSection{Key: "recently_completed", Title: "Recently Completed", Color: color.New(color.FgGreen, color.Bold)}
+
+	since := now.AddDate(0, 0, -completedDays)
+	for _, t := range tasks {
+		if globalFlags.Area != "" && t.TaskMetadata.Area != globalFlags.Area {
+			continue
+		}
+
+		switch meta := t.TaskMetadata; {
+		case meta.Status == denote.TaskStatusDone:
+			if completedDays > 0 && completedAt(t).After(since) {
+				completed.Tasks = append(completed.Tasks, t)
+			}
+		case meta.Status == denote.TaskStatusDropped:
+		case denote.IsOverdue(meta.DueDate):
+			overdue.Tasks = append(overdue.Tasks, t)
+		case meta.DueDate == today:
+			dueToday.Tasks = append(dueToday.Tasks, t)
+		case meta.StartDate == today:
+			starting.Tasks = append(starting.Tasks, t)
+		case denote.IsDueSoon(meta.DueDate, horizon):
+			soon.Tasks = append(soon.Tasks, t)
+		}
+	}
+
+	// Dated sections go oldest due date first, then by priority
+	byDue := func(tasks []*denote.Task) {
+		sort.SliceStable(tasks, func(i, j int) bool {
+			a, b := tasks[i].TaskMetadata, tasks[j].TaskMetadata
+			if a.DueDate != b.DueDate {
+				return a.DueDate < b.DueDate
+			}
+			return agendaLess(tasks[i], tasks[j])
+		})
+	}
+	byDue(overdue.Tasks)
+	byDue(soon.Tasks)
+	sort.SliceStable(dueToday.Tasks, func(i, j int) bool { return agendaLess(dueToday.Tasks[i], dueToday.Tasks[j]) })
+	sort.SliceStable(starting.Tasks, func(i, j int) bool { return agendaLess(starting.Tasks[i], starting.Tasks[j]) })
+
+	// Most recently completed first
+	sort.SliceStable(completed.Tasks, func(i, j int) bool {
+		return completedAt(completed.Tasks[i]).After(completedAt(completed.Tasks[j]))
+	})
+
+	return []*agendaSection{overdue, dueToday, starting, soon, completed}
+}
+
+// completedAt returns when a done task was completed. Tasks finished before
+// completion times were recorded fall back to the file's modification time.
+func completedAt(t *denote.Task) time.Time {
+	if completed, ok := t.TaskMetadata.CompletedTime(); ok {
+		return completed
+	}
+	return t.ModTime
+}
+
+// agendaLess orders tasks by priority, then title
+func agendaLess(a, b *denote.Task) bool {
+	pa, pb := priorityRank(a.TaskMetadata.Priority), priorityRank(b.TaskMetadata.Priority)
+	if pa != pb {
+		return pa < pb
+	}
+	return strings.ToLower(a.TaskMetadata.Title) < strings.ToLower(b.TaskMetadata.Title)
+}
+
+// priorityRank orders p1 first and no priority last
+func priorityRank(p string) int {
+	switch p {
+	case "p1":
+		return 1
+	case "p2":
+		return 2
+	case "p3":
+		return 3
+	}
+	return 4
+}
+
+// agendaLine formats a task like a list line: ID, status, priority, due
+// date, title, area and project
+func agendaLine(t *denote.Task, projectNames map[string]string) string {
+	status := "○"
+	switch t.TaskMetadata.Status {
+	case denote.TaskStatusDone:
+		status = "✓"
+	case denote.TaskStatusPaused:
+		status = "⏸"
+	case denote.TaskStatusDelegated:
+		status = "→"
+	default:
+		if t.IsBlocked() {
+			status = "⊘"
+		}
+	}
+
+	priority := "    "
+	switch p := t.TaskMetadata.Priority; p {
+	case "p1":
+		priority = color.New(color.FgRed, color.Bold).Sprintf("[%s]", p)
+	case "p2":
+		priority = color.New(color.FgYellow).Sprintf("[%s]", p)
+	case "p3":
+		priority = fmt.Sprintf("[%s]", p)
+	}
+
+	due := "            "
+	if t.TaskMetadata.DueDate != "" {
+		due = fmt.Sprintf("[%s]", t.TaskMetadata.DueDate)
+		if t.TaskMetadata.Status != denote.TaskStatusDone && denote.IsOverdue(t.TaskMetadata.DueDate) {
+			due = color.New(color.FgRed, color.Bold).Sprint(due)
+		}
+	}
+
+	title := t.TaskMetadata.Title
+	if title == "" {
+		title = t.File.Title
+	}
+	if len(title) > 50 {
+		title = title[:47] + "..."
+	}
+
+	area := t.TaskMetadata.Area
+	if len(area) > 10 {
+		area = area[:7] + "..."
+	}
+
+	project := ""
+	if id := t.TaskMetadata.ProjectID; id != "" {
+		if name := projectNames[id]; name != "" {
+			project = "→ " + name
+		} else {
+			project = "→ " + id
+		}
+	}
+
+	line := fmt.Sprintf("%3d %s %s %s  %-50s %-10s %s",
+		t.TaskMetadata.IndexID, status, priority, due, title, area, project)
+	return strings.TrimRight(line, " ")
+}
+
+// printAgendaJSON prints the sections as one object keyed by section
+func printAgendaJSON(sections []*agendaSection, projectNames map[string]string) error {
+	out := agendaJSON{Date: time.Now().Format("2006-01-02")}
+	fields := map[string]*[]taskJSON{
+		"overdue":            &out.Overdue,
+		"due_today":          &out.DueToday,
+		"starting_today":     &out.StartingToday,
+		"due_soon":           &out.DueSoon,
+		"recently_completed": &out.RecentlyCompleted,
+	}
+
+	for _, s := range sections {
+		tasks := make([]taskJSON, 0, len(s.Tasks))
+		for _, t := range s.Tasks {
+			tasks = append(tasks, newTaskJSON(t, projectNames))
+		}
+		*fields[s.Key] = tasks
+	}
+
+	return printJSON(out)
+}
+
+// newTaskJSON converts a task for JSON output
+func newTaskJSON(t *denote.Task, projectNames map[string]string) taskJSON {
+	meta := t.TaskMetadata
+	out := taskJSON{
+		ID:        meta.IndexID,
+		DenoteID:  t.File.ID,
+		Title:     meta.Title,
+		Status:    meta.Status,
+		Priority:  meta.Priority,
+		DueDate:   meta.DueDate,
+		StartDate: meta.StartDate,
+		Area:      meta.Area,
+		ProjectID: meta.ProjectID,
+		Project:   projectNames[meta.ProjectID],
+		Path:      t.File.Path,
+	}
+	if out.Status == "" {
+		out.Status = denote.TaskStatusOpen
+	}
+	if meta.Status == denote.TaskStatusDone {
+		out.Completed = completedAt(t).Format(time.RFC3339)
+	}
+	return out
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -3,8 +3,10 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/pdxmph/denote-tasks/internal/config"
+	"github.com/pdxmph/denote-tasks/internal/denote"
 	"github.com/pdxmph/denote-tasks/internal/tui"
 )
 
@@ -30,6 +32,16 @@ func Run(cfg *config.Config, args []string) error {
 		cfg.NotesDirectory = globalFlags.Dir
 	}
 
+	denote.NotesRoot = cfg.NotesDirectory
+	denote.DefaultScanOptions = denote.ScanOptions{
+		Recursive:      cfg.Scan.Recursive,
+		Include:        cfg.Scan.Include,
+		Exclude:        cfg.Scan.Exclude,
+		FollowSymlinks: cfg.Scan.FollowSymlinks,
+	}
+
+	cfg.TUI.NoColor = globalFlags.NoColor
+
 	// If no arguments or just --tui, launch TUI
 	if len(remaining) == 0 || globalFlags.TUI {
 		if globalFlags.TUI || len(os.Args) == 1 {
@@ -53,6 +65,9 @@ Task Commands (implicit):
   update     Update task metadata
   done       Mark tasks as done
   log        Add log entry to task
+  check      List or toggle checklist items
+  start      Start tracking time on a task
+  stop       Stop the running timer
 
 Project Commands:
   project new      Create a new project
@@ -62,7 +77,17 @@ Project Commands:
   project tasks    Show tasks for a project
 
 Other Commands:
-  completion  Generate shell completions
+  agenda         Show overdue, today's, upcoming and recently done tasks
+  search TERMS   Search titles, tags and note bodies
+  report         Compare tracked time with estimates
+  import FORMAT  Import tasks (taskwarrior, todotxt)
+  export FORMAT  Export tasks and projects (ics, taskwarrior, todotxt)
+  sync           Two-way sync with a CalDAV task list
+  undo           Undo the last change (--list to show history)
+  redo           Redo the last undone change
+  doctor         Check for and repair inconsistencies
+  index rebuild  Rebuild the metadata index cache
+  completion     Generate shell completions
 
 Global Options:
   --tui, -t      Launch TUI interface
@@ -80,12 +105,27 @@ Global Options:
 		root.Subcommands = append(root.Subcommands, cmd)
 	}
 	
-	// Add project and completion commands
+	// Add project, agenda, search, report, import/export, sync, undo, doctor, index and completion commands
 	root.Subcommands = append(root.Subcommands, 
 		ProjectCommand(cfg),
+		AgendaCommand(cfg),
+		SearchCommand(cfg),
+		ReportCommand(cfg),
+		ImportCommand(cfg),
+		ExportCommand(cfg),
+		SyncCommand(cfg),
+		UndoCommand(cfg),
+		RedoCommand(cfg),
+		DoctorCommand(cfg),
+		IndexCommand(cfg),
 		CompletionCommand(cfg),
 	)
 
-	// Execute command
-	return root.Execute(remaining)
+	// Record file changes so the command can be undone
+	op := denote.BeginOperation(cfg.NotesDirectory, strings.Join(remaining, " "))
+	err = root.Execute(remaining)
+	if commitErr := op.Commit(); commitErr != nil && !globalFlags.Quiet {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record undo history: %v\n", commitErr)
+	}
+	return err
 }
\ No newline at end of file
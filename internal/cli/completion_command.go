@@ -29,13 +29,13 @@ func CompletionCommand(cfg *config.Config) *Command {
 
 			switch args[0] {
 			case "task-ids":
-				return outputTaskIDs(files)
+				return outputTaskIDs(scanner, files)
 			case "project-ids":
-				return outputProjectIDs(files)
+				return outputProjectIDs(scanner, files)
 			case "areas":
-				return outputAreas(files)
+				return outputAreas(scanner, files)
 			case "tags":
-				return outputTags(files)
+				return outputTags(scanner, files)
 			default:
 				return fmt.Errorf("unknown completion type: %s", args[0])
 			}
@@ -45,13 +45,13 @@ func CompletionCommand(cfg *config.Config) *Command {
 	return cmd
 }
 
-func outputTaskIDs(files []denote.File) error {
+func outputTaskIDs(scanner *denote.Scanner, files []denote.File) error {
 	var ids []int
 	seen := make(map[int]bool)
 
 	for _, file := range files {
 		if file.IsTask() {
-			task, err := denote.ParseTaskFile(file.Path)
+			task, err := scanner.LoadTask(file.Path)
 			if err == nil && task.TaskMetadata.IndexID > 0 {
 				if !seen[task.TaskMetadata.IndexID] {
 					ids = append(ids, task.TaskMetadata.IndexID)
@@ -68,12 +68,12 @@ func outputTaskIDs(files []denote.File) error {
 	return nil
 }
 
-func outputProjectIDs(files []denote.File) error {
+func outputProjectIDs(scanner *denote.Scanner, files []denote.File) error {
 	projects := make(map[string]string) // ID -> Title
 
 	for _, file := range files {
 		if file.IsProject() {
-			project, err := denote.ParseProjectFile(file.Path)
+			project, err := scanner.LoadProject(file.Path)
 			if err == nil {
 				title := project.ProjectMetadata.Title
 				if title == "" {
@@ -97,17 +97,17 @@ func outputProjectIDs(files []denote.File) error {
 	return nil
 }
 
-func outputAreas(files []denote.File) error {
+func outputAreas(scanner *denote.Scanner, files []denote.File) error {
 	areas := make(map[string]bool)
 
 	for _, file := range files {
 		if file.IsTask() {
-			task, err := denote.ParseTaskFile(file.Path)
+			task, err := scanner.LoadTask(file.Path)
 			if err == nil && task.TaskMetadata.Area != "" {
 				areas[task.TaskMetadata.Area] = true
 			}
 		} else if file.IsProject() {
-			project, err := denote.ParseProjectFile(file.Path)
+			project, err := scanner.LoadProject(file.Path)
 			if err == nil && project.ProjectMetadata.Area != "" {
 				areas[project.ProjectMetadata.Area] = true
 			}
@@ -127,7 +127,7 @@ func outputAreas(files []denote.File) error {
 	return nil
 }
 
-func outputTags(files []denote.File) error {
+func outputTags(scanner *denote.Scanner, files []denote.File) error {
 	tags := make(map[string]bool)
 
 	for _, file := range files {
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pdxmph/denote-tasks/internal/config"
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+// doctorMaxPasses bounds --fix re-checks; fixing one problem (such as a
+// missing type) can reveal others in the same file
+const doctorMaxPasses = 3
+
+// DoctorCommand creates the doctor command for checking and repairing the
+// notes directory
+func DoctorCommand(cfg *config.Config) *Command {
+	var fix bool
+
+	cmd := &Command{
+		Name:  "doctor",
+		Usage: "denote-tasks doctor [--fix]",
+		Description: `Check task and project files for problems
+
+Reports duplicate or missing index_ids, project_ids that point to no project,
+frontmatter tags missing from the filename, invalid status, priority and
+estimate values, malformed dates, missing frontmatter and an ID counter that
+is behind the highest index_id. Without --fix nothing is changed.`,
+		Flags: flag.NewFlagSet("doctor", flag.ExitOnError),
+	}
+
+	cmd.Flags.BoolVar(&fix, "fix", false, "Repair problems that can be fixed automatically")
+
+	cmd.Run = func(c *Command, args []string) error {
+		if !fix {
+			report, err := denote.Diagnose(cfg.NotesDirectory)
+			if err != nil {
+				return fmt.Errorf("failed to check directory: %v", err)
+			}
+			printDoctorReport(report)
+			if report.Fixable() > 0 && !globalFlags.Quiet {
+				fmt.Println("\nRun 'denote-tasks doctor --fix' to repair fixable problems")
+			}
+			return nil
+		}
+
+		fixed := 0
+		for pass := 0; pass < doctorMaxPasses; pass++ {
+			report, err := denote.Diagnose(cfg.NotesDirectory)
+			if err != nil {
+				return fmt.Errorf("failed to check directory: %v", err)
+			}
+			if report.Fixable() == 0 {
+				break
+			}
+
+			for _, p := range report.Problems {
+				if !p.Fixable() {
+					continue
+				}
+				if err := p.Fix(); err != nil {
+					fmt.Fprintf(os.Stderr, "✗ %s: %v\n", filepath.Base(p.Path), err)
+					continue
+				}
+				fixed++
+				if !globalFlags.Quiet {
+					fmt.Printf("✓ %s: %s\n", filepath.Base(p.Path), p.Action)
+				}
+			}
+		}
+
+		report, err := denote.Diagnose(cfg.NotesDirectory)
+		if err != nil {
+			return fmt.Errorf("failed to check directory: %v", err)
+		}
+
+		if !globalFlags.Quiet {
+			if fixed > 0 {
+				fmt.Printf("\nFixed %d problems\n", fixed)
+			}
+			if len(report.Problems) > 0 {
+				fmt.Println()
+			}
+		}
+		printDoctorReport(report)
+
+		return nil
+	}
+
+	return cmd
+}
+
+// printDoctorReport lists the problems found by a check
+func printDoctorReport(report *denote.DoctorReport) {
+	if globalFlags.Quiet {
+		for _, p := range report.Problems {
+			fmt.Printf("%s\t%s\t%s\n", p.Kind, p.Path, p.Message)
+		}
+		return
+	}
+
+	if len(report.Problems) == 0 {
+		fmt.Printf("Checked %d files, no problems found\n", report.Checked)
+		return
+	}
+
+	fmt.Printf("Checked %d files, found %d problems (%d fixable)\n",
+		report.Checked, len(report.Problems), report.Fixable())
+
+	for _, p := range report.Problems {
+		fmt.Printf("\n%-17s %s\n", p.Kind, filepath.Base(p.Path))
+		fmt.Printf("  %s\n", p.Message)
+		if p.Fixable() {
+			fmt.Printf("  fix: %s\n", p.Action)
+		} else {
+			fmt.Println("  fix: edit the file by hand")
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pdxmph/denote-tasks/internal/config"
+	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/ical"
+	"github.com/pdxmph/denote-tasks/internal/taskwarrior"
+	"github.com/pdxmph/denote-tasks/internal/todotxt"
+)
+
+// ExportCommand creates the export command
+func ExportCommand(cfg *config.Config) *Command {
+	cmd := &Command{
+		Name:        "export",
+		Usage:       "denote-tasks export <format> [options]",
+		Description: "Export tasks and projects to other formats",
+	}
+
+	cmd.Subcommands = []*Command{
+		exportICSCommand(cfg),
+		exportTaskwarriorCommand(cfg),
+		exportTodotxtCommand(cfg),
+	}
+
+	return cmd
+}
+
+// exportICSCommand writes tasks and projects with dates as an iCalendar feed
+func exportICSCommand(cfg *config.Config) *Command {
+	var (
+		filter     taskFilter
+		output     string
+		events     bool
+		noProjects bool
+	)
+
+	cmd := &Command{
+		Name:  "ics",
+		Usage: "denote-tasks export ics [options]",
+		Description: `Export tasks and projects with due or start dates as iCalendar
+
+Entries are VTODOs by default, or all-day VEVENTs with --events for calendar
+apps that don't show tasks. UIDs are derived from Denote IDs and timestamps
+from file modification times, so regenerating the feed only changes entries
+whose files changed. Accepts the same filters as list.`,
+		Flags: flag.NewFlagSet("export-ics", flag.ExitOnError),
+	}
+
+	filter.addFlags(cmd.Flags)
+	cmd.Flags.StringVar(&output, "o", "", "Write to file instead of stdout")
+	cmd.Flags.StringVar(&output, "output", "", "Write to file instead of stdout")
+	cmd.Flags.BoolVar(&events, "events", false, "Export all-day events instead of todos")
+	cmd.Flags.BoolVar(&noProjects, "no-projects", false, "Leave out projects")
+
+	cmd.Run = func(c *Command, args []string) error {
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+		if err := filter.prepare(result); err != nil {
+			return err
+		}
+
+		// Needed for the blocked and ready filters
+		denote.ResolveDependencies(result.Tasks)
+
+		projectNames := make(map[string]string) // ID -> Title
+		for _, p := range result.Projects {
+			projectNames[p.File.ID] = p.ProjectMetadata.Title
+		}
+
+		var entries []*ical.Component
+		for _, t := range result.Tasks {
+			if t.TaskMetadata.DueDate == "" && t.TaskMetadata.StartDate == "" {
+				continue
+			}
+			if !filter.matchTask(cfg, t) {
+				continue
+			}
+			if events {
+				entries = append(entries, ical.TaskToVEVENT(t, projectNames[t.TaskMetadata.ProjectID]))
+			} else {
+				entries = append(entries, ical.TaskToVTODO(t, projectNames[t.TaskMetadata.ProjectID]))
+			}
+		}
+
+		if !noProjects {
+			for _, p := range result.Projects {
+				if p.ProjectMetadata.DueDate == "" && p.ProjectMetadata.StartDate == "" {
+					continue
+				}
+				if !filter.matchProject(cfg, p) {
+					continue
+				}
+				if events {
+					entries = append(entries, ical.ProjectToVEVENT(p))
+				} else {
+					entries = append(entries, ical.ProjectToVTODO(p))
+				}
+			}
+		}
+
+		// Drop entries whose dates didn't parse, and order by UID for
+		// stable output
+		cal := ical.NewCalendar()
+		for _, entry := range entries {
+			if entry != nil {
+				cal.Children = append(cal.Children, entry)
+			}
+		}
+		sort.Slice(cal.Children, func(i, j int) bool {
+			return cal.Children[i].Value("UID") < cal.Children[j].Value("UID")
+		})
+
+		data := []byte(cal.String())
+		if output == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+
+		return writeExport(output, data, len(cal.Children))
+	}
+
+	return cmd
+}
+
+// exportTaskwarriorCommand writes tasks as a Taskwarrior JSON export
+func exportTaskwarriorCommand(cfg *config.Config) *Command {
+	var (
+		filter taskFilter
+		output string
+	)
+
+	cmd := &Command{
+		Name:  "taskwarrior",
+		Usage: "denote-tasks export taskwarrior [options]",
+		Description: `Export tasks in Taskwarrior JSON format, for "task import"
+
+Priorities map to H/M/L, projects to Taskwarrior projects by title and log
+entries to annotations. Tasks imported from Taskwarrior keep their UUID;
+others get one derived from their Denote ID, so importing the file again
+updates tasks instead of duplicating them. Area, estimate and statuses
+Taskwarrior lacks are kept in extra attributes, and importing the file back
+with "denote-tasks import taskwarrior" updates the tasks in place.
+Accepts the same filters as list.`,
+		Flags: flag.NewFlagSet("export-taskwarrior", flag.ExitOnError),
+	}
+
+	filter.addFlags(cmd.Flags)
+	cmd.Flags.StringVar(&output, "o", "", "Write to file instead of stdout")
+	cmd.Flags.StringVar(&output, "output", "", "Write to file instead of stdout")
+
+	cmd.Run = func(c *Command, args []string) error {
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+		if err := filter.prepare(result); err != nil {
+			return err
+		}
+		denote.ResolveDependencies(result.Tasks)
+
+		var tasks []*denote.Task
+		for _, t := range result.Tasks {
+			if filter.matchTask(cfg, t) {
+				tasks = append(tasks, t)
+			}
+		}
+		sort.Slice(tasks, func(i, j int) bool {
+			return tasks[i].File.ID < tasks[j].File.ID
+		})
+
+		exported, err := taskwarrior.Export(tasks, result)
+		if err != nil {
+			return err
+		}
+
+		// One task per line, as Taskwarrior writes its exports
+		var buf bytes.Buffer
+		buf.WriteString("[\n")
+		for i, tw := range exported {
+			line, err := json.Marshal(tw)
+			if err != nil {
+				return fmt.Errorf("failed to encode task: %v", err)
+			}
+			buf.Write(line)
+			if i < len(exported)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("]\n")
+
+		if output == "" {
+			_, err := os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		return writeExport(output, buf.Bytes(), len(exported))
+	}
+
+	return cmd
+}
+
+// exportTodotxtCommand writes tasks as a todo.txt file
+func exportTodotxtCommand(cfg *config.Config) *Command {
+	var (
+		filter taskFilter
+		output string
+	)
+
+	cmd := &Command{
+		Name:  "todotxt",
+		Usage: "denote-tasks export todotxt [options]",
+		Description: `Export tasks in todo.txt format
+
+Priorities map to (A)/(B)/(C), projects to +project by title, the area to
+an @context and due/start dates to due: and t: tags. Done and dropped tasks
+are marked "x". Each line carries a denote: tag, so importing the file back
+with "denote-tasks import todotxt" updates tasks instead of duplicating
+them. Accepts the same filters as list.`,
+		Flags: flag.NewFlagSet("export-todotxt", flag.ExitOnError),
+	}
+
+	filter.addFlags(cmd.Flags)
+	cmd.Flags.StringVar(&output, "o", "", "Write to file instead of stdout")
+	cmd.Flags.StringVar(&output, "output", "", "Write to file instead of stdout")
+
+	cmd.Run = func(c *Command, args []string) error {
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+		if err := filter.prepare(result); err != nil {
+			return err
+		}
+		denote.ResolveDependencies(result.Tasks)
+
+		projectNames := make(map[string]string) // ID -> Title
+		for _, p := range result.Projects {
+			projectNames[p.File.ID] = p.ProjectMetadata.Title
+		}
+
+		var tasks []*denote.Task
+		for _, t := range result.Tasks {
+			if filter.matchTask(cfg, t) {
+				tasks = append(tasks, t)
+			}
+		}
+		sort.Slice(tasks, func(i, j int) bool {
+			return tasks[i].File.ID < tasks[j].File.ID
+		})
+
+		var buf strings.Builder
+		for _, t := range tasks {
+			buf.WriteString(todotxt.FromTask(t, projectNames[t.TaskMetadata.ProjectID]).String())
+			buf.WriteString("\n")
+		}
+
+		data := []byte(buf.String())
+		if output == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		return writeExport(output, data, len(tasks))
+	}
+
+	return cmd
+}
+
+// writeExport writes an export to a file, leaving it untouched if it is
+// already up to date
+func writeExport(output string, data []byte, count int) error {
+	if existing, err := os.ReadFile(output); err == nil && bytes.Equal(existing, data) {
+		if !globalFlags.Quiet {
+			fmt.Printf("%s is up to date (%d entries)\n", output, count)
+		}
+		return nil
+	}
+	if err := denote.WriteFileAtomic(output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", output, err)
+	}
+	if !globalFlags.Quiet {
+		fmt.Printf("Wrote %d entries to %s\n", count, output)
+	}
+	return nil
+}
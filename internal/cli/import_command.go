@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pdxmph/denote-tasks/internal/config"
+	"github.com/pdxmph/denote-tasks/internal/taskwarrior"
+	"github.com/pdxmph/denote-tasks/internal/todotxt"
+)
+
+// ImportCommand creates the import command
+func ImportCommand(cfg *config.Config) *Command {
+	cmd := &Command{
+		Name:        "import",
+		Usage:       "denote-tasks import <format> <file>",
+		Description: "Import tasks from other task managers",
+	}
+
+	cmd.Subcommands = []*Command{
+		importTaskwarriorCommand(cfg),
+		importTodotxtCommand(cfg),
+	}
+
+	return cmd
+}
+
+// importTaskwarriorCommand creates tasks from a Taskwarrior JSON export
+func importTaskwarriorCommand(cfg *config.Config) *Command {
+	cmd := &Command{
+		Name:  "taskwarrior",
+		Usage: "denote-tasks import taskwarrior <file.json>",
+		Description: `Import tasks from a Taskwarrior JSON export ("task export > file.json")
+
+Description, status, priority (H/M/L → p1/p2/p3), due, scheduled, project,
+tags and annotations are imported. Projects become project files linked by
+project_id, annotations become log entries and dependencies are kept.
+Tasks are matched by UUID, so importing a task again updates it instead
+of adding a duplicate. Recurring task templates are skipped; their pending
+instances are imported. Use - to read from stdin.`,
+	}
+
+	cmd.Run = func(c *Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("Taskwarrior JSON file required")
+		}
+
+		data, err := readImportFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		twTasks, err := taskwarrior.Parse(data)
+		if err != nil {
+			return err
+		}
+
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+
+		imported, err := taskwarrior.Import(cfg.NotesDirectory, cfg.Tasks.FileType, twTasks, result)
+		if imported != nil && !globalFlags.Quiet {
+			for _, p := range imported.Projects {
+				fmt.Printf("Created project ID %d: %s\n", p.ProjectMetadata.IndexID, p.ProjectMetadata.Title)
+			}
+			for _, t := range imported.Tasks {
+				fmt.Printf("Imported task ID %d: %s\n", t.TaskMetadata.IndexID, t.TaskMetadata.Title)
+			}
+			for _, t := range imported.Updated {
+				fmt.Printf("Updated task ID %d: %s\n", t.TaskMetadata.IndexID, t.TaskMetadata.Title)
+			}
+			for _, s := range imported.Skipped {
+				fmt.Printf("Skipped: %s\n", s)
+			}
+			fmt.Printf("\nImported %d tasks, updated %d and created %d projects (%d skipped)\n",
+				len(imported.Tasks), len(imported.Updated), len(imported.Projects), len(imported.Skipped))
+		}
+		return err
+	}
+
+	return cmd
+}
+
+// importTodotxtCommand creates tasks from a todo.txt file
+func importTodotxtCommand(cfg *config.Config) *Command {
+	cmd := &Command{
+		Name:  "todotxt",
+		Usage: "denote-tasks import todotxt <todo.txt>",
+		Description: `Import tasks from a todo.txt file
+
+Priorities (A)/(B)/(C) map to p1/p2/p3 (lower letters to p3), "x" lines to
+done tasks and due:/t: tags to due and start dates. The first +project is
+matched against existing project titles (+home-garden matches "Home
+Garden") or created as a new project. The first @context becomes the area
+and any others become tags. Lines exported with a denote: tag update that
+task in place. Use - to read from stdin.`,
+	}
+
+	cmd.Run = func(c *Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("todo.txt file required")
+		}
+
+		data, err := readImportFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+
+		imported, err := todotxt.Import(cfg.NotesDirectory, cfg.Tasks.FileType, todotxt.Parse(string(data)), result)
+		if imported != nil && !globalFlags.Quiet {
+			for _, p := range imported.Projects {
+				fmt.Printf("Created project ID %d: %s\n", p.ProjectMetadata.IndexID, p.ProjectMetadata.Title)
+			}
+			for _, t := range imported.Created {
+				fmt.Printf("Imported task ID %d: %s\n", t.TaskMetadata.IndexID, t.TaskMetadata.Title)
+			}
+			for _, t := range imported.Updated {
+				fmt.Printf("Updated task ID %d: %s\n", t.TaskMetadata.IndexID, t.TaskMetadata.Title)
+			}
+			for _, s := range imported.Skipped {
+				fmt.Printf("Skipped: %s\n", s)
+			}
+			fmt.Printf("\nImported %d tasks, updated %d and created %d projects (%d skipped)\n",
+				len(imported.Created), len(imported.Updated), len(imported.Projects), len(imported.Skipped))
+		}
+		return err
+	}
+
+	return cmd
+}
+
+// readImportFile reads an import file, or stdin for "-"
+func readImportFile(path string) ([]byte, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %v", err)
+		}
+		return data, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pdxmph/denote-tasks/internal/config"
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+// IndexCommand creates the index command for managing the metadata cache
+func IndexCommand(cfg *config.Config) *Command {
+	cmd := &Command{
+		Name:        "index",
+		Usage:       "denote-tasks index <command>",
+		Description: "Manage the metadata index cache",
+	}
+
+	cmd.Subcommands = []*Command{
+		indexRebuildCommand(cfg),
+		indexClearCommand(cfg),
+	}
+
+	return cmd
+}
+
+// indexRebuildCommand re-parses every file and rewrites the index
+func indexRebuildCommand(cfg *config.Config) *Command {
+	cmd := &Command{
+		Name:        "rebuild",
+		Usage:       "denote-tasks index rebuild",
+		Description: "Rebuild the metadata index from all task and project files",
+	}
+
+	cmd.Run = func(c *Command, args []string) error {
+		count, err := denote.RebuildIndex(cfg.NotesDirectory)
+		if err != nil {
+			return fmt.Errorf("failed to rebuild index: %v", err)
+		}
+
+		if !globalFlags.Quiet {
+			fmt.Printf("Indexed %d files\n", count)
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+// indexClearCommand removes the index file
+func indexClearCommand(cfg *config.Config) *Command {
+	cmd := &Command{
+		Name:        "clear",
+		Usage:       "denote-tasks index clear",
+		Description: "Remove the metadata index (it is recreated on the next scan)",
+	}
+
+	cmd.Run = func(c *Command, args []string) error {
+		path := filepath.Join(cfg.NotesDirectory, denote.IndexFileName)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove index: %v", err)
+		}
+
+		if !globalFlags.Quiet {
+			fmt.Println("Index cleared")
+		}
+		return nil
+	}
+
+	return cmd
+}
@@ -40,6 +40,7 @@ func projectNewCommand(cfg *config.Config) *Command {
 		area      string
 		startDate string
 		tags      string
+		fileType  string
 	)
 
 	cmd := &Command{
@@ -55,6 +56,7 @@ func projectNewCommand(cfg *config.Config) *Command {
 	cmd.Flags.StringVar(&startDate, "start", "", "Start date (YYYY-MM-DD or natural language)")
 	cmd.Flags.StringVar(&area, "area", "", "Project area")
 	cmd.Flags.StringVar(&tags, "tags", "", "Comma-separated tags")
+	cmd.Flags.StringVar(&fileType, "file-type", cfg.Tasks.FileType, "File type (markdown-yaml, markdown-toml, org, text)")
 
 	cmd.Run = func(c *Command, args []string) error {
 		if len(args) == 0 {
@@ -73,41 +75,46 @@ func projectNewCommand(cfg *config.Config) *Command {
 		}
 
 		// Create the project
-		projectFile, err := task.CreateProject(cfg.NotesDirectory, title, "", tagList)
+		projectFile, err := task.CreateProjectAs(cfg.NotesDirectory, fileType, title, "", tagList)
 		if err != nil {
 			return fmt.Errorf("failed to create project: %v", err)
 		}
 
 		// Update metadata if provided
-		needsUpdate := false
-		if priority != "" {
-			projectFile.ProjectMetadata.Priority = priority
-			needsUpdate = true
-		}
+		var dueDate, start string
 		if due != "" {
 			parsed, err := denote.ParseNaturalDate(due)
 			if err != nil {
 				return fmt.Errorf("invalid due date: %v", err)
 			}
-			projectFile.ProjectMetadata.DueDate = parsed
-			needsUpdate = true
+			dueDate = parsed
 		}
 		if startDate != "" {
 			parsed, err := denote.ParseNaturalDate(startDate)
 			if err != nil {
 				return fmt.Errorf("invalid start date: %v", err)
 			}
-			projectFile.ProjectMetadata.StartDate = parsed
-			needsUpdate = true
-		}
-		if area != "" {
-			projectFile.ProjectMetadata.Area = area
-			needsUpdate = true
+			start = parsed
 		}
 
 		// Write back if we have updates
-		if needsUpdate {
-			if err := updateProjectFile(projectFile.Path, projectFile.ProjectMetadata); err != nil {
+		if priority != "" || dueDate != "" || start != "" || area != "" {
+			err := task.ModifyProjectFile(projectFile.Path, func(meta *denote.ProjectMetadata) error {
+				if priority != "" {
+					meta.Priority = priority
+				}
+				if dueDate != "" {
+					meta.DueDate = dueDate
+				}
+				if start != "" {
+					meta.StartDate = start
+				}
+				if area != "" {
+					meta.Area = area
+				}
+				return nil
+			})
+			if err != nil {
 				return fmt.Errorf("failed to update project metadata: %v", err)
 			}
 		}
@@ -553,52 +560,58 @@ func projectUpdateCommand(cfg *config.Config) *Command {
 				continue
 			}
 
-			// Apply updates
-			changed := false
-			if priority != "" {
-				p.ProjectMetadata.Priority = priority
-				changed = true
-			}
+			// Check the new values before touching the file
+			var parsedDue, parsedStart string
 			if due != "" {
-				parsedDue, err := denote.ParseNaturalDate(due)
+				parsedDue, err = denote.ParseNaturalDate(due)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Invalid due date for project ID %d: %v\n", id, err)
 					continue
 				}
-				p.ProjectMetadata.DueDate = parsedDue
-				changed = true
 			}
 			if startDate != "" {
-				parsedStart, err := denote.ParseNaturalDate(startDate)
+				parsedStart, err = denote.ParseNaturalDate(startDate)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Invalid start date for project ID %d: %v\n", id, err)
 					continue
 				}
-				p.ProjectMetadata.StartDate = parsedStart
-				changed = true
 			}
-			if area != "" {
-				p.ProjectMetadata.Area = area
-				changed = true
+			if status != "" && !denote.IsValidProjectStatus(status) {
+				fmt.Fprintf(os.Stderr, "Invalid status for project ID %d: %s\n", id, status)
+				continue
 			}
-			if status != "" {
-				if !denote.IsValidProjectStatus(status) {
-					fmt.Fprintf(os.Stderr, "Invalid status for project ID %d: %s\n", id, status)
-					continue
-				}
-				p.ProjectMetadata.Status = status
-				changed = true
+
+			if priority == "" && due == "" && startDate == "" && area == "" && status == "" {
+				continue
 			}
 
-			if changed {
-				if err := updateProjectFile(p.File.Path, p.ProjectMetadata); err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to update project ID %d: %v\n", id, err)
-					continue
+			// Apply updates to the metadata as it is on disk
+			err := task.ModifyProjectFile(p.File.Path, func(meta *denote.ProjectMetadata) error {
+				if priority != "" {
+					meta.Priority = priority
+				}
+				if parsedDue != "" {
+					meta.DueDate = parsedDue
 				}
-				updated++
-				if !globalFlags.Quiet {
-					fmt.Printf("Updated project ID %d: %s\n", id, p.ProjectMetadata.Title)
+				if parsedStart != "" {
+					meta.StartDate = parsedStart
 				}
+				if area != "" {
+					meta.Area = area
+				}
+				if status != "" {
+					meta.Status = status
+				}
+				p.ProjectMetadata = *meta
+				return nil
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to update project ID %d: %v\n", id, err)
+				continue
+			}
+			updated++
+			if !globalFlags.Quiet {
+				fmt.Printf("Updated project ID %d: %s\n", id, p.ProjectMetadata.Title)
 			}
 		}
 
@@ -693,31 +706,3 @@ func sortProjectTasks(tasks []*denote.Task, sortBy string, reverse bool) {
 		return less
 	})
 }
-
-// updateProjectFile updates the project metadata in a file
-func updateProjectFile(path string, metadata denote.ProjectMetadata) error {
-	// Read the current file
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-
-	// Parse existing frontmatter to get the content
-	fm, err := denote.ParseFrontmatterFile(content)
-	if err != nil {
-		return fmt.Errorf("failed to parse frontmatter: %w", err)
-	}
-
-	// Write updated content
-	newContent, err := denote.WriteFrontmatterFile(metadata, fm.Content)
-	if err != nil {
-		return fmt.Errorf("failed to write frontmatter: %w", err)
-	}
-
-	// Write to file
-	if err := os.WriteFile(path, newContent, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	return nil
-}
\ No newline at end of file
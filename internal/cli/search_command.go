@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pdxmph/denote-tasks/internal/config"
+	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/search"
+)
+
+// SearchCommand creates the full-text search command
+func SearchCommand(cfg *config.Config) *Command {
+	var (
+		limit int
+		open  bool
+	)
+
+	cmd := &Command{
+		Name:  "search",
+		Usage: "denote-tasks search [options] <terms>",
+		Description: `Search task and project titles, tags and bodies
+
+Every term must occur for a file to match, and a term also matches
+longer words starting with it. Results are ranked, with matches in
+titles counting for more than matches in tags or the body, and show
+the line where the terms occur.`,
+		Flags: flag.NewFlagSet("search", flag.ExitOnError),
+	}
+
+	cmd.Flags.IntVar(&limit, "limit", 20, "Maximum results to show (0 for all)")
+	cmd.Flags.IntVar(&limit, "n", 20, "Maximum results to show (short)")
+	cmd.Flags.BoolVar(&open, "open", false, "Only open tasks and active projects")
+
+	cmd.Run = func(c *Command, args []string) error {
+		terms := strings.Join(args, " ")
+		if len(search.Terms(terms)) == 0 {
+			return fmt.Errorf("search terms required")
+		}
+
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+
+		// Only index what could be shown
+		var tasks []*denote.Task
+		byPath := make(map[string]int) // Path -> index ID
+		for _, t := range result.Tasks {
+			if globalFlags.Area != "" && t.TaskMetadata.Area != globalFlags.Area {
+				continue
+			}
+			if open && t.TaskMetadata.Status != denote.TaskStatusOpen && t.TaskMetadata.Status != "" {
+				continue
+			}
+			tasks = append(tasks, t)
+			byPath[t.File.Path] = t.TaskMetadata.IndexID
+		}
+		var projects []*denote.Project
+		for _, p := range result.Projects {
+			if globalFlags.Area != "" && p.ProjectMetadata.Area != globalFlags.Area {
+				continue
+			}
+			if open && p.ProjectMetadata.Status != denote.ProjectStatusActive && p.ProjectMetadata.Status != "" {
+				continue
+			}
+			projects = append(projects, p)
+			byPath[p.File.Path] = p.ProjectMetadata.IndexID
+		}
+
+		results := search.Build(tasks, projects).Search(terms)
+		if len(results) == 0 {
+			if !globalFlags.Quiet {
+				fmt.Println("No matches")
+			}
+			return nil
+		}
+
+		if globalFlags.NoColor || color.NoColor {
+			color.NoColor = true
+		}
+		matchColor := color.New(color.FgYellow, color.Bold)
+		projectColor := color.New(color.FgMagenta)
+
+		total := len(results)
+		if limit > 0 && len(results) > limit {
+			results = results[:limit]
+		}
+
+		if !globalFlags.Quiet {
+			if len(results) < total {
+				fmt.Printf("Matches (%d of %d):\n\n", len(results), total)
+			} else {
+				fmt.Printf("Matches (%d):\n\n", total)
+			}
+		}
+
+		for _, r := range results {
+			kind := "task"
+			title := r.Doc.Title
+			if r.Doc.File.IsProject() {
+				kind = "project"
+				title = projectColor.Sprint(title)
+			}
+			fmt.Printf("%3d %-7s %s\n", byPath[r.Doc.File.Path], kind, title)
+
+			// The title match is already on show
+			if r.Snippet.Field == search.FieldTitle {
+				continue
+			}
+			where := "tags"
+			if r.Snippet.Field == search.FieldBody {
+				where = fmt.Sprintf("line %d", r.Snippet.Line)
+			}
+			fmt.Printf("    %s: %s\n", where, highlight(r.Snippet, matchColor))
+		}
+
+		return nil
+	}
+
+	return cmd
+}
+
+// highlight colors the matching words in a snippet
+func highlight(s search.Snippet, c *color.Color) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range s.Matches {
+		b.WriteString(s.Text[last:m[0]])
+		b.WriteString(c.Sprint(s.Text[m[0]:m[1]]))
+		last = m[1]
+	}
+	b.WriteString(s.Text[last:])
+	return b.String()
+}
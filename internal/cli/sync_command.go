@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pdxmph/denote-tasks/internal/caldav"
+	"github.com/pdxmph/denote-tasks/internal/config"
+)
+
+// syncActions lists sync actions in output order with their symbol and
+// past-tense label
+var syncActions = []struct {
+	action, symbol, label string
+}{
+	{caldav.ActionCreate, "↑", "added"},
+	{caldav.ActionPush, "↑", "pushed"},
+	{caldav.ActionPull, "↓", "pulled"},
+	{caldav.ActionImport, "↓", "imported"},
+	{caldav.ActionDelete, "✗", "deleted"},
+	{caldav.ActionDrop, "✗", "dropped"},
+	{caldav.ActionConflict, "!", "conflicts"},
+}
+
+// SyncCommand creates the sync command
+func SyncCommand(cfg *config.Config) *Command {
+	var (
+		url    string
+		policy string
+		dryRun bool
+	)
+
+	cmd := &Command{
+		Name:  "sync",
+		Usage: "denote-tasks sync [options]",
+		Description: `Two-way sync of tasks with a CalDAV task list
+
+Title, status, priority, due and start dates and area (as CATEGORIES) are
+mirrored to VTODOs. Open tasks are added to the server, tasks added on the
+server are imported, and changes on either side are carried over. ETags and
+the last-synced state of each task are kept in .denote-tasks-sync.json in
+the task directory. Tasks changed on both sides are resolved by the
+conflict policy: newest, local, remote or skip.
+
+Settings come from the [caldav] section of the config file.`,
+		Flags: flag.NewFlagSet("sync", flag.ExitOnError),
+	}
+
+	cmd.Flags.StringVar(&url, "url", "", "Calendar collection URL (overrides config)")
+	cmd.Flags.StringVar(&policy, "policy", "", "Conflict policy: newest, local, remote, skip")
+	cmd.Flags.BoolVar(&dryRun, "dry-run", false, "Show what would change without changing anything")
+
+	cmd.Run = func(c *Command, args []string) error {
+		if url == "" {
+			url = cfg.CalDAV.URL
+		}
+		if url == "" {
+			return fmt.Errorf("no CalDAV URL: set url in the [caldav] config section or use --url")
+		}
+		if policy == "" {
+			policy = cfg.CalDAV.ConflictPolicy
+		}
+		area := cfg.CalDAV.Area
+		if area == "" {
+			area = globalFlags.Area
+		}
+
+		password := cfg.CalDAV.Password
+		if password == "" {
+			password = os.Getenv("DENOTE_TASKS_CALDAV_PASSWORD")
+		}
+
+		client, err := caldav.NewClient(url, cfg.CalDAV.Username, password)
+		if err != nil {
+			return err
+		}
+
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+
+		res, err := caldav.Sync(client, cfg.NotesDirectory, result.Tasks, caldav.Options{
+			Policy: policy,
+			Area:   area,
+			DryRun: dryRun,
+
+			FileType: cfg.Tasks.FileType,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !globalFlags.Quiet {
+			for _, a := range syncActions {
+				for _, change := range res.Changes {
+					if change.Action != a.action {
+						continue
+					}
+					line := fmt.Sprintf("%s %-9s %s", a.symbol, a.label, change.Title)
+					if change.Detail != "" {
+						line += " (" + change.Detail + ")"
+					}
+					fmt.Println(line)
+				}
+			}
+			printSyncSummary(client.URL, res, dryRun)
+		}
+
+		for _, e := range res.Errors {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", e)
+		}
+		if len(res.Errors) > 0 {
+			return fmt.Errorf("%d errors during sync", len(res.Errors))
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+// printSyncSummary prints the counts of each kind of change
+func printSyncSummary(url string, res *caldav.Result, dryRun bool) {
+	if len(res.Changes) == 0 {
+		fmt.Printf("Already in sync with %s\n", url)
+		return
+	}
+
+	var parts []string
+	for _, a := range syncActions {
+		if n := res.Count(a.action); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, a.label))
+		}
+	}
+
+	verb := "Synced with"
+	if dryRun {
+		verb = "Dry run against"
+	}
+	fmt.Printf("\n%s %s: %s\n", verb, url, strings.Join(parts, ", "))
+}
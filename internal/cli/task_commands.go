@@ -1,16 +1,19 @@
 package cli
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/pdxmph/denote-tasks/internal/config"
 	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/query"
 	"github.com/pdxmph/denote-tasks/internal/task"
 )
 
@@ -25,9 +28,13 @@ func TaskCommand(cfg *config.Config) *Command {
 	cmd.Subcommands = []*Command{
 		taskNewCommand(cfg),
 		taskListCommand(cfg),
+		taskShowCommand(cfg),
 		taskUpdateCommand(cfg),
 		taskDoneCommand(cfg),
 		taskLogCommand(cfg),
+		taskCheckCommand(cfg),
+		taskStartCommand(cfg),
+		taskStopCommand(cfg),
 		taskEditCommand(cfg),
 		taskDeleteCommand(cfg),
 	}
@@ -44,6 +51,8 @@ func taskNewCommand(cfg *config.Config) *Command {
 		project  string
 		estimate int
 		tags     string
+		recur    string
+		fileType string
 	)
 
 	cmd := &Command{
@@ -60,12 +69,20 @@ func taskNewCommand(cfg *config.Config) *Command {
 	cmd.Flags.StringVar(&project, "project", "", "Project name or ID")
 	cmd.Flags.IntVar(&estimate, "estimate", 0, "Time estimate")
 	cmd.Flags.StringVar(&tags, "tags", "", "Comma-separated tags")
+	cmd.Flags.StringVar(&recur, "recur", "", "Recurrence (e.g. \"every 2w\", \"monthly on 15\", \"3d after done\")")
+	cmd.Flags.StringVar(&fileType, "file-type", cfg.Tasks.FileType, "File type (markdown-yaml, markdown-toml, org, text)")
 
 	cmd.Run = func(c *Command, args []string) error {
 		if len(args) == 0 {
 			return fmt.Errorf("title required")
 		}
 
+		if recur != "" {
+			if _, err := denote.ParseRecurrence(recur); err != nil {
+				return fmt.Errorf("invalid recurrence: %v", err)
+			}
+		}
+
 		title := strings.Join(args, " ")
 
 		// Parse tags
@@ -88,36 +105,33 @@ func taskNewCommand(cfg *config.Config) *Command {
 		}
 
 		// Create the task
-		taskFile, err := task.CreateTask(cfg.NotesDirectory, title, "", tagList, area)
+		taskFile, err := task.CreateTaskAs(cfg.NotesDirectory, fileType, title, "", tagList, area)
 		if err != nil {
 			return fmt.Errorf("failed to create task: %v", err)
 		}
 
 		// Update metadata if provided
-		if priority != "" || dueDate != "" || project != "" || estimate > 0 {
-			// Read the task
-			t, err := denote.ParseTaskFile(taskFile.Path)
+		if priority != "" || dueDate != "" || project != "" || estimate > 0 || recur != "" {
+			err := task.ModifyTaskFile(taskFile.Path, func(meta *denote.TaskMetadata) error {
+				if priority != "" {
+					meta.Priority = priority
+				}
+				if dueDate != "" {
+					meta.DueDate = dueDate
+				}
+				if project != "" {
+					// TODO: Look up project by name/ID
+					meta.ProjectID = project
+				}
+				if estimate > 0 {
+					meta.Estimate = estimate
+				}
+				if recur != "" {
+					meta.Recurrence = recur
+				}
+				return nil
+			})
 			if err != nil {
-				return fmt.Errorf("failed to read created task: %v", err)
-			}
-
-			// Update fields
-			if priority != "" {
-				t.TaskMetadata.Priority = priority
-			}
-			if dueDate != "" {
-				t.TaskMetadata.DueDate = dueDate
-			}
-			if project != "" {
-				// TODO: Look up project by name/ID
-				t.TaskMetadata.ProjectID = project
-			}
-			if estimate > 0 {
-				t.TaskMetadata.Estimate = estimate
-			}
-
-			// Write back
-			if err := task.UpdateTaskFile(taskFile.Path, t.TaskMetadata); err != nil {
 				return fmt.Errorf("failed to update task metadata: %v", err)
 			}
 		}
@@ -138,18 +152,195 @@ func taskNewCommand(cfg *config.Config) *Command {
 	return cmd
 }
 
+// taskFilter holds the task selection flags shared by list and export
+type taskFilter struct {
+	all      bool
+	area     string
+	status   string
+	priority string
+	project  string
+	overdue  bool
+	soon     bool
+	blocked  bool
+	ready    bool
+	where    string
+
+	query    *query.Query
+	projects map[string]string // Project titles by Denote ID, for the query
+}
+
+// addFlags registers the filter flags on a flag set
+func (f *taskFilter) addFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.all, "all", false, "Show all tasks (default: open only)")
+	fs.StringVar(&f.area, "area", "", "Filter by area")
+	fs.StringVar(&f.status, "status", "", "Filter by status")
+	fs.StringVar(&f.priority, "p", "", "Filter by priority (p1, p2, p3)")
+	fs.StringVar(&f.priority, "priority", "", "Filter by priority (p1, p2, p3)")
+	fs.StringVar(&f.project, "project", "", "Filter by project")
+	fs.BoolVar(&f.overdue, "overdue", false, "Show only overdue tasks")
+	fs.BoolVar(&f.soon, "soon", false, "Show tasks due soon")
+	fs.BoolVar(&f.blocked, "blocked", false, "Show only tasks waiting on dependencies")
+	fs.BoolVar(&f.ready, "ready", false, "Show only open tasks with no pending dependencies")
+	fs.BoolVar(&f.all, "a", false, "Show all tasks (short)")
+	fs.StringVar(&f.where, "where", "", "Filter by query (e.g. \"priority<=p2 and due<+7d\")")
+}
+
+// prepare parses the --where query against the scanned projects. It must
+// be called before matching.
+func (f *taskFilter) prepare(result *denote.ScanResult) error {
+	if f.where == "" {
+		return nil
+	}
+	q, err := parseWhere(f.where)
+	if err != nil {
+		return err
+	}
+	f.query = q
+	f.projects = query.ProjectTitles(result.Projects)
+	return nil
+}
+
+// parseWhere parses a --where query, pointing at the problem on error
+func parseWhere(where string) (*query.Query, error) {
+	q, err := query.Parse(where)
+	if err != nil {
+		var parseErr *query.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("invalid query: %v\n  %s", err, strings.ReplaceAll(parseErr.Caret(), "\n", "\n  "))
+		}
+		return nil, fmt.Errorf("invalid query: %v", err)
+	}
+	return q, nil
+}
+
+// applyView fills in the filters a view sets, except those given as flags.
+// A view query is combined with --where.
+func (f *taskFilter) applyView(view config.ViewConfig, set map[string]bool) {
+	if view.Area != "" && !set["area"] {
+		f.area = view.Area
+	}
+	if view.Priority != "" && !set["p"] && !set["priority"] {
+		f.priority = view.Priority
+	}
+	if !set["status"] {
+		if view.State == "active" {
+			// Open or delegated, as in the TUI
+			f.where = joinQueries("status=open or status=delegated", f.where)
+		} else if view.State != "" {
+			f.status = view.State
+		}
+	}
+	if view.Soon {
+		f.soon = true
+	}
+	f.where = joinQueries(view.Where, f.where)
+}
+
+// joinQueries combines two queries with and; either may be empty
+func joinQueries(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return "(" + a + ") and (" + b + ")"
+}
+
+// flagsSet returns the names of the flags given on the command line
+func flagsSet(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+// filterArea returns the command area filter, falling back to the global one
+func (f *taskFilter) filterArea() string {
+	if f.area != "" {
+		return f.area
+	}
+	return globalFlags.Area
+}
+
+// matchTask reports whether a task passes the filter. Dependencies must
+// already be resolved for the blocked and ready filters. A query replaces
+// the default of showing only open tasks.
+func (f *taskFilter) matchTask(cfg *config.Config, t *denote.Task) bool {
+	if f.query != nil && !f.query.MatchTask(t, f.projects) {
+		return false
+	}
+	if !f.all && f.status == "" && f.where == "" && t.TaskMetadata.Status != denote.TaskStatusOpen && t.TaskMetadata.Status != "" {
+		return false
+	}
+	if f.status != "" && t.TaskMetadata.Status != f.status {
+		return false
+	}
+	if area := f.filterArea(); area != "" && t.TaskMetadata.Area != area {
+		return false
+	}
+	if f.priority != "" && t.TaskMetadata.Priority != f.priority {
+		return false
+	}
+	if f.project != "" && t.TaskMetadata.ProjectID != f.project {
+		return false
+	}
+	if f.overdue && !denote.IsOverdue(t.TaskMetadata.DueDate) {
+		return false
+	}
+	if f.soon && !denote.IsDueSoon(t.TaskMetadata.DueDate, cfg.SoonHorizon) {
+		return false
+	}
+	if f.blocked && !t.IsBlocked() {
+		return false
+	}
+	if f.ready && t.IsBlocked() {
+		return false
+	}
+	return true
+}
+
+// matchProject reports whether a project passes the filter. Without --all,
+// only active projects match; the dependency filters never match projects.
+func (f *taskFilter) matchProject(cfg *config.Config, p *denote.Project) bool {
+	if f.blocked || f.ready {
+		return false
+	}
+	if f.query != nil && !f.query.MatchProject(p) {
+		return false
+	}
+	if !f.all && f.status == "" && f.where == "" && p.ProjectMetadata.Status != denote.ProjectStatusActive && p.ProjectMetadata.Status != "" {
+		return false
+	}
+	if f.status != "" && p.ProjectMetadata.Status != f.status {
+		return false
+	}
+	if area := f.filterArea(); area != "" && p.ProjectMetadata.Area != area {
+		return false
+	}
+	if f.priority != "" && p.ProjectMetadata.Priority != f.priority {
+		return false
+	}
+	if f.project != "" && p.File.ID != f.project {
+		return false
+	}
+	if f.overdue && !denote.IsOverdue(p.ProjectMetadata.DueDate) {
+		return false
+	}
+	if f.soon && !denote.IsDueSoon(p.ProjectMetadata.DueDate, cfg.SoonHorizon) {
+		return false
+	}
+	return true
+}
+
 // taskListCommand lists tasks
 func taskListCommand(cfg *config.Config) *Command {
 	var (
-		all      bool
-		area     string
-		status   string
-		priority string
-		project  string
-		overdue  bool
-		soon     bool
+		filter   taskFilter
 		sortBy   string
 		reverse  bool
+		viewName string
 	)
 
 	cmd := &Command{
@@ -159,19 +350,12 @@ func taskListCommand(cfg *config.Config) *Command {
 		Flags:       flag.NewFlagSet("task-list", flag.ExitOnError),
 	}
 
-	cmd.Flags.BoolVar(&all, "all", false, "Show all tasks (default: open only)")
-	cmd.Flags.StringVar(&area, "area", "", "Filter by area")
-	cmd.Flags.StringVar(&status, "status", "", "Filter by status")
-	cmd.Flags.StringVar(&priority, "p", "", "Filter by priority (p1, p2, p3)")
-	cmd.Flags.StringVar(&priority, "priority", "", "Filter by priority (p1, p2, p3)")
-	cmd.Flags.StringVar(&project, "project", "", "Filter by project")
-	cmd.Flags.BoolVar(&overdue, "overdue", false, "Show only overdue tasks")
-	cmd.Flags.BoolVar(&soon, "soon", false, "Show tasks due soon")
+	filter.addFlags(cmd.Flags)
 	cmd.Flags.StringVar(&sortBy, "sort", "modified", "Sort by: modified, priority, due, created")
 	cmd.Flags.BoolVar(&reverse, "reverse", false, "Reverse sort order")
+	cmd.Flags.StringVar(&viewName, "view", "", "Apply a view from the config")
 	
 	// Convenience flags
-	cmd.Flags.BoolVar(&all, "a", false, "Show all tasks (short)")
 	cmd.Flags.StringVar(&sortBy, "s", "modified", "Sort by (short)")
 	cmd.Flags.BoolVar(&reverse, "r", false, "Reverse sort (short)")
 
@@ -182,76 +366,62 @@ func taskListCommand(cfg *config.Config) *Command {
 			return fmt.Errorf("TUI integration not yet implemented")
 		}
 
-		// Otherwise, list tasks in CLI
-		scanner := denote.NewScanner(cfg.NotesDirectory)
-		files, err := scanner.FindAllTaskAndProjectFiles()
-		if err != nil {
-			return fmt.Errorf("failed to scan directory: %v", err)
-		}
-
-		// First pass: collect all projects for name lookup
-		projectNames := make(map[string]string) // ID -> Title
-		for _, file := range files {
-			if file.IsProject() {
-				p, err := denote.ParseProjectFile(file.Path)
-				if err == nil {
-					projectNames[file.ID] = p.ProjectMetadata.Title
-				}
+		// Views fill in what the flags leave out
+		var view config.ViewConfig
+		if viewName != "" {
+			var err error
+			if view, err = cfg.View(viewName); err != nil {
+				return err
 			}
-		}
-
-		// Second pass: filter to tasks only
-		var tasks []denote.Task
-		for _, file := range files {
-			if !file.IsTask() {
-				continue
+			set := flagsSet(c.Flags)
+			filter.applyView(view, set)
+			if view.Sort != "" && !set["sort"] && !set["s"] {
+				sortBy = view.Sort
 			}
-
-			// Parse task metadata
-			t, err := denote.ParseTaskFile(file.Path)
-			if err != nil {
-				continue // Skip files we can't parse
-			}
-
-			// Apply filters
-			if !all && status == "" && t.TaskMetadata.Status != denote.TaskStatusOpen && t.TaskMetadata.Status != "" {
-				continue
-			}
-
-			if status != "" && t.TaskMetadata.Status != status {
-				continue
+			if view.Order != "" && !set["reverse"] && !set["r"] {
+				reverse = view.Order == "reverse"
 			}
+		}
 
-			// Use command-specific area filter or fall back to global
-			filterArea := area
-			if filterArea == "" {
-				filterArea = globalFlags.Area
-			}
-			if filterArea != "" && t.TaskMetadata.Area != filterArea {
-				continue
-			}
+		// Otherwise, list tasks in CLI
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
 
-			if priority != "" && t.TaskMetadata.Priority != priority {
-				continue
-			}
+		if err := filter.prepare(result); err != nil {
+			return err
+		}
 
-			if project != "" && t.TaskMetadata.ProjectID != project {
-				continue
-			}
+		// Collect all projects for name lookup
+		projectNames := make(map[string]string) // ID -> Title
+		for _, p := range result.Projects {
+			projectNames[p.File.ID] = p.ProjectMetadata.Title
+		}
 
-			if overdue && !denote.IsOverdue(t.TaskMetadata.DueDate) {
-				continue
+		allTasks := result.Tasks
+		cycles := denote.ResolveDependencies(allTasks)
+		if !globalFlags.Quiet {
+			for _, cycle := range cycles {
+				fmt.Fprintf(os.Stderr, "Warning: dependency cycle: %s\n", formatDependencyCycle(allTasks, cycle))
 			}
+		}
 
-			if soon && !denote.IsDueSoon(t.TaskMetadata.DueDate, cfg.SoonHorizon) {
-				continue
+		// Apply filters
+		var tasks []denote.Task
+		for _, t := range allTasks {
+			if filter.matchTask(cfg, t) {
+				tasks = append(tasks, *t)
 			}
-
-			tasks = append(tasks, *t)
 		}
 
-		// Sort tasks
-		sortTasks(tasks, sortBy, reverse)
+		// Sort tasks, keeping groups together
+		sortTasks(tasks, sortBy, reverse, projectNames)
+		if view.Group != "" {
+			sort.SliceStable(tasks, func(i, j int) bool {
+				return groupLess(taskGroup(&tasks[i], view.Group, projectNames), taskGroup(&tasks[j], view.Group, projectNames))
+			})
+		}
 
 		// Display tasks
 		if globalFlags.JSON {
@@ -276,7 +446,19 @@ func taskListCommand(cfg *config.Config) *Command {
 		}
 
 		// Display tasks with clean, TUI-like formatting
+		group := "\x00"
 		for _, t := range tasks {
+			if view.Group != "" {
+				if g := taskGroup(&t, view.Group, projectNames); g != group {
+					if group != "\x00" {
+						fmt.Println()
+					}
+					group = g
+					fmt.Println(groupLabel(g, view.Group) + ":")
+				}
+			}
+
+
 			// Status icon
 			status := "○"
 			switch t.TaskMetadata.Status {
@@ -288,6 +470,10 @@ func taskListCommand(cfg *config.Config) *Command {
 				status = "→"
 			case denote.TaskStatusDropped:
 				status = "⨯"
+			default:
+				if t.IsBlocked() {
+					status = "⊘"
+				}
 			}
 
 			// Priority with padding
@@ -315,14 +501,19 @@ func taskListCommand(cfg *config.Config) *Command {
 				}
 			}
 
-			// Title - truncate to 50 chars
+			// Title - truncate to 50 chars, keeping checklist progress visible
 			title := t.TaskMetadata.Title
 			if title == "" {
 				title = t.File.Title
 			}
-			if len(title) > 50 {
-				title = title[:47] + "..."
+			progress := ""
+			if done, total := denote.ChecklistProgress(t.Checklist); total > 0 {
+				progress = fmt.Sprintf(" [%d/%d]", done, total)
 			}
+			if len(title)+len(progress) > 50 {
+				title = title[:47-len(progress)] + "..."
+			}
+			title += progress
 
 			// Area - truncate to 10 chars
 			area := ""
@@ -346,15 +537,45 @@ func taskListCommand(cfg *config.Config) *Command {
 
 			// Build the line with fixed-width columns
 			// Format: ID Status Priority Due Title(50) Area(10) Project
-			line := fmt.Sprintf("%3d %s %s %s  %-50s %-10s %s",
-				t.TaskMetadata.IndexID,
-				status,
-				priority,
-				due,
-				title,
-				area,
-				projectName,
-			)
+			var line string
+			if len(view.Columns) == 0 {
+				line = fmt.Sprintf("%3d %s %s %s  %-50s %-10s %s",
+					t.TaskMetadata.IndexID,
+					status,
+					priority,
+					due,
+					title,
+					area,
+					projectName,
+				)
+			} else {
+				estimate := "    "
+				if t.TaskMetadata.Estimate > 0 {
+					estimate = fmt.Sprintf("[%2d]", t.TaskMetadata.Estimate)
+				}
+				var tags []string
+				for _, tag := range append(append([]string{}, t.File.Tags...), t.TaskMetadata.Tags...) {
+					if tag != "task" {
+						tags = append(tags, tag)
+					}
+				}
+				cells := map[string]string{
+					"id":       fmt.Sprintf("%3d", t.TaskMetadata.IndexID),
+					"status":   status,
+					"priority": priority,
+					"estimate": estimate,
+					"due":      due,
+					"title":    fmt.Sprintf("%-50s", title),
+					"tags":     fmt.Sprintf("%-20s", strings.Join(tags, ",")),
+					"area":     fmt.Sprintf("%-10s", area),
+					"project":  projectName,
+				}
+				var parts []string
+				for _, column := range view.Columns {
+					parts = append(parts, cells[column])
+				}
+				line = strings.TrimRight(strings.Join(parts, " "), " ")
+			}
 
 			// Apply line coloring for done tasks
 			if t.TaskMetadata.Status == denote.TaskStatusDone {
@@ -370,8 +591,217 @@ func taskListCommand(cfg *config.Config) *Command {
 	return cmd
 }
 
-// sortTasks sorts tasks by the specified field
-func sortTasks(tasks []denote.Task, sortBy string, reverse bool) {
+// taskDetailsJSON is the show command's JSON output
+type taskDetailsJSON struct {
+	taskJSON
+	Assignee   string         `json:"assignee,omitempty"`
+	Estimate   int            `json:"estimate,omitempty"`
+	Recurrence string         `json:"recurrence,omitempty"`
+	Tags       []string       `json:"tags,omitempty"`
+	Checklist  *checklistJSON `json:"checklist,omitempty"`
+	Blocked    bool           `json:"blocked"`
+	DependsOn  []taskJSON     `json:"depends_on"`
+	Blocks     []taskJSON     `json:"blocks"` // Tasks whose depends_on names this one
+}
+
+// checklistJSON is a task's checklist progress
+type checklistJSON struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+func taskShowCommand(cfg *config.Config) *Command {
+	cmd := &Command{
+		Name:        "show",
+		Usage:       "denote-tasks task show <task-id>",
+		Description: "Show task details, including dependencies in both directions",
+	}
+
+	cmd.Run = func(c *Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("task ID required")
+		}
+
+		// Parse task ID
+		taskNum, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid task ID: %s", args[0])
+		}
+
+		// Get all tasks
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+
+		t := findTaskByIndexID(result.Tasks, taskNum)
+		if t == nil {
+			return fmt.Errorf("task with ID %d not found", taskNum)
+		}
+		denote.ResolveDependencies(result.Tasks)
+
+		// Blocks is the reverse of depends_on and is never stored
+		meta := t.TaskMetadata
+		var dependencies []*denote.Task
+		for _, id := range meta.DependsOn {
+			for _, dep := range result.Tasks {
+				if dep.File.ID == id {
+					dependencies = append(dependencies, dep)
+					break
+				}
+			}
+		}
+		dependents := denote.FindDependents(result.Tasks, t.File.ID)
+
+		projectNames := query.ProjectTitles(result.Projects)
+		var tags []string
+		for _, tag := range append(append([]string{}, t.File.Tags...), meta.Tags...) {
+			if tag != "task" {
+				tags = append(tags, tag)
+			}
+		}
+		done, total := denote.ChecklistProgress(t.Checklist)
+
+		if globalFlags.JSON {
+			out := taskDetailsJSON{
+				taskJSON:   newTaskJSON(t, projectNames),
+				Assignee:   meta.Assignee,
+				Estimate:   meta.Estimate,
+				Recurrence: meta.Recurrence,
+				Tags:       tags,
+				Blocked:    t.IsBlocked(),
+				DependsOn:  make([]taskJSON, 0, len(dependencies)),
+				Blocks:     make([]taskJSON, 0, len(dependents)),
+			}
+			if total > 0 {
+				out.Checklist = &checklistJSON{Done: done, Total: total}
+			}
+			for _, dep := range dependencies {
+				out.DependsOn = append(out.DependsOn, newTaskJSON(dep, projectNames))
+			}
+			for _, dep := range dependents {
+				out.Blocks = append(out.Blocks, newTaskJSON(dep, projectNames))
+			}
+			return printJSON(out)
+		}
+
+		if globalFlags.NoColor || color.NoColor {
+			color.NoColor = true
+		}
+		labelColor := color.New(color.Bold)
+
+		field := func(label, value string) {
+			if value != "" {
+				fmt.Printf("%s %s\n", labelColor.Sprintf("%-11s", label+":"), value)
+			}
+		}
+
+		status := meta.Status
+		if status == "" {
+			status = denote.TaskStatusOpen
+		}
+		if t.IsBlocked() {
+			status += fmt.Sprintf(" (blocked by %d unfinished task(s))", len(t.BlockedBy))
+		}
+		project := meta.ProjectID
+		if name, ok := projectNames[meta.ProjectID]; ok {
+			project = name
+		}
+		estimate := ""
+		if meta.Estimate > 0 {
+			estimate = strconv.Itoa(meta.Estimate)
+		}
+
+		title := meta.Title
+		if title == "" {
+			title = t.File.Title
+		}
+		fmt.Printf("%d %s\n\n", meta.IndexID, title)
+		field("Status", status)
+		field("Priority", meta.Priority)
+		field("Due", meta.DueDate)
+		field("Start", meta.StartDate)
+		field("Area", meta.Area)
+		field("Project", project)
+		field("Assignee", meta.Assignee)
+		field("Estimate", estimate)
+		field("Recurrence", meta.Recurrence)
+		field("Tags", strings.Join(tags, ", "))
+		if total > 0 {
+			field("Checklist", fmt.Sprintf("%d/%d done", done, total))
+		}
+		field("File", t.File.Path)
+
+		printDependencies("Depends on", dependencies)
+		printDependencies("Blocks", dependents)
+
+		return nil
+	}
+
+	return cmd
+}
+
+// printDependencies lists related tasks under a heading, with their status
+func printDependencies(label string, tasks []*denote.Task) {
+	if len(tasks) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", color.New(color.Bold).Sprint(label))
+	for _, t := range tasks {
+		mark := "○"
+		switch t.TaskMetadata.Status {
+		case denote.TaskStatusDone:
+			mark = "✓"
+		case denote.TaskStatusDropped:
+			mark = "⨯"
+		}
+		fmt.Printf("  %s %3d %s\n", mark, t.TaskMetadata.IndexID, t.TaskMetadata.Title)
+	}
+}
+
+// scanDirectory loads all tasks and projects, warning about files that
+// could not be parsed cleanly
+func scanDirectory(cfg *config.Config) (*denote.ScanResult, error) {
+	scanner := denote.NewScanner(cfg.NotesDirectory)
+	result, err := scanner.Scan()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan directory: %v", err)
+	}
+
+	if !globalFlags.Quiet {
+		for _, scanErr := range result.Errors {
+			if scanErr.Skipped {
+				fmt.Fprintf(os.Stderr, "Warning: skipped %v\n", scanErr)
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", scanErr)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// formatDependencyCycle renders a cycle of Denote IDs using index IDs
+func formatDependencyCycle(tasks []*denote.Task, cycle []string) string {
+	indexIDs := make(map[string]int)
+	for _, t := range tasks {
+		indexIDs[t.File.ID] = t.TaskMetadata.IndexID
+	}
+
+	parts := make([]string, len(cycle))
+	for i, id := range cycle {
+		if indexID, ok := indexIDs[id]; ok {
+			parts[i] = strconv.Itoa(indexID)
+		} else {
+			parts[i] = id
+		}
+	}
+	return strings.Join(parts, " → ")
+}
+
+// sortTasks sorts tasks by the specified field. projectNames maps project
+// IDs to titles for sorting by project.
+func sortTasks(tasks []denote.Task, sortBy string, reverse bool, projectNames map[string]string) {
 	sort.Slice(tasks, func(i, j int) bool {
 		var less bool
 		
@@ -399,6 +829,18 @@ func sortTasks(tasks []denote.Task, sortBy string, reverse bool) {
 		case "created":
 			less = tasks[i].File.ID < tasks[j].File.ID
 			
+		case "title":
+			less = strings.ToLower(tasks[i].TaskMetadata.Title) < strings.ToLower(tasks[j].TaskMetadata.Title)
+			
+		case "estimate":
+			less = tasks[i].TaskMetadata.Estimate < tasks[j].TaskMetadata.Estimate
+			
+		case "project":
+			// Sort by project title (tasks without a project last)
+			pi := strings.ToLower(projectNames[tasks[i].TaskMetadata.ProjectID])
+			pj := strings.ToLower(projectNames[tasks[j].TaskMetadata.ProjectID])
+			less = pi != "" && (pj == "" || pi < pj)
+			
 		case "modified":
 			fallthrough
 		default:
@@ -412,6 +854,46 @@ func sortTasks(tasks []denote.Task, sortBy string, reverse bool) {
 	})
 }
 
+// taskGroup returns the value a task is grouped by, "" if it has none
+func taskGroup(t *denote.Task, group string, projectNames map[string]string) string {
+	switch group {
+	case "area":
+		return t.TaskMetadata.Area
+	case "project":
+		if t.TaskMetadata.ProjectID == "" {
+			return ""
+		}
+		if name := projectNames[t.TaskMetadata.ProjectID]; name != "" {
+			return name
+		}
+		return t.TaskMetadata.ProjectID
+	case "priority":
+		return t.TaskMetadata.Priority
+	case "status":
+		if t.TaskMetadata.Status == "" {
+			return denote.TaskStatusOpen
+		}
+		return t.TaskMetadata.Status
+	}
+	return ""
+}
+
+// groupLess orders groups alphabetically, with the empty group last
+func groupLess(a, b string) bool {
+	if a == "" || b == "" {
+		return a != "" && b == ""
+	}
+	return strings.ToLower(a) < strings.ToLower(b)
+}
+
+// groupLabel is the heading for a group
+func groupLabel(value, group string) string {
+	if value == "" {
+		return "No " + group
+	}
+	return value
+}
+
 // priorityValue converts priority to numeric value for sorting
 func priorityValue(p string) int {
 	switch p {
@@ -491,11 +973,14 @@ func taskUpdateCommand(cfg *config.Config) *Command {
 		project  string
 		estimate int
 		status   string
+		recur    string
+		depends  string
+		where    string
 	)
 
 	cmd := &Command{
 		Name:        "update",
-		Usage:       "denote-tasks task update [options] <task-ids>",
+		Usage:       "denote-tasks task update [options] <task-ids>\n       denote-tasks task update [options] --where QUERY",
 		Description: "Update task metadata",
 		Flags:       flag.NewFlagSet("task-update", flag.ExitOnError),
 	}
@@ -507,10 +992,39 @@ func taskUpdateCommand(cfg *config.Config) *Command {
 	cmd.Flags.StringVar(&project, "project", "", "Set project")
 	cmd.Flags.IntVar(&estimate, "estimate", -1, "Set time estimate")
 	cmd.Flags.StringVar(&status, "status", "", "Set status (open, done, paused, delegated, dropped)")
+	cmd.Flags.StringVar(&recur, "recur", "", "Set recurrence (\"none\" to clear)")
+	cmd.Flags.StringVar(&depends, "depends", "", "Set dependencies as task IDs (\"none\" to clear)")
+	cmd.Flags.StringVar(&where, "where", "", "Update every task matching a query instead of by ID")
 
 	cmd.Run = func(c *Command, args []string) error {
-		if len(args) == 0 {
-			return fmt.Errorf("task IDs required")
+		if len(args) == 0 && where == "" {
+			return fmt.Errorf("task IDs or --where required")
+		}
+		if len(args) > 0 && where != "" {
+			return fmt.Errorf("give task IDs or --where, not both")
+		}
+
+		var q *query.Query
+		if where != "" {
+			var err error
+			if q, err = parseWhere(where); err != nil {
+				return err
+			}
+		}
+
+		var dependsIDs []int
+		if depends != "" && depends != "none" {
+			ids, err := parseTaskIDs([]string{depends})
+			if err != nil {
+				return fmt.Errorf("invalid dependencies: %v", err)
+			}
+			dependsIDs = ids
+		}
+
+		if recur != "" && recur != "none" {
+			if _, err := denote.ParseRecurrence(recur); err != nil {
+				return fmt.Errorf("invalid recurrence: %v", err)
+			}
 		}
 
 		// Parse task IDs
@@ -520,75 +1034,127 @@ func taskUpdateCommand(cfg *config.Config) *Command {
 		}
 
 		// Get all tasks
-		scanner := denote.NewScanner(cfg.NotesDirectory)
-		files, err := scanner.FindAllTaskAndProjectFiles()
+		result, err := scanDirectory(cfg)
 		if err != nil {
-			return fmt.Errorf("failed to scan directory: %v", err)
+			return err
 		}
 
 		// Build index of tasks by index_id
 		tasksByID := make(map[int]*denote.Task)
-		for _, file := range files {
-			if !file.IsTask() {
-				continue
+		allTasks := result.Tasks
+		for _, t := range allTasks {
+			tasksByID[t.TaskMetadata.IndexID] = t
+		}
+
+		// Resolve dependency index IDs to Denote IDs
+		var dependsOn []string
+		for _, depID := range dependsIDs {
+			dep, ok := tasksByID[depID]
+			if !ok {
+				return fmt.Errorf("dependency task with ID %d not found", depID)
 			}
-			t, err := denote.ParseTaskFile(file.Path)
-			if err != nil {
-				continue
+			dependsOn = append(dependsOn, dep.File.ID)
+		}
+
+		// Select tasks by ID or query
+		var targets []*denote.Task
+		if q != nil {
+			projects := query.ProjectTitles(result.Projects)
+			for _, t := range allTasks {
+				if q.MatchTask(t, projects) {
+					targets = append(targets, t)
+				}
+			}
+		} else {
+			for _, id := range numbers {
+				t, ok := tasksByID[id]
+				if !ok {
+					fmt.Fprintf(os.Stderr, "Task with ID %d not found\n", id)
+					continue
+				}
+				targets = append(targets, t)
 			}
-			tasksByID[t.TaskMetadata.IndexID] = t
 		}
 
 		// Update each task
+		changed := priority != "" || due != "" || area != "" || project != "" ||
+			estimate >= 0 || status != "" || recur != "" || depends != ""
 		updated := 0
-		for _, id := range numbers {
-			t, ok := tasksByID[id]
-			if !ok {
-				fmt.Fprintf(os.Stderr, "Task with ID %d not found\n", id)
-				continue
+		for _, t := range targets {
+			if !changed {
+				break
 			}
+			id := t.TaskMetadata.IndexID
 
-			// Apply updates
-			changed := false
-			if priority != "" {
-				t.TaskMetadata.Priority = priority
-				changed = true
-			}
+			// Check the new values before touching the file
+			var parsedDue string
 			if due != "" {
-				parsedDue, err := denote.ParseNaturalDate(due)
+				parsedDue, err = denote.ParseNaturalDate(due)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Invalid due date for task ID %d: %v\n", id, err)
 					continue
 				}
-				t.TaskMetadata.DueDate = parsedDue
-				changed = true
-			}
-			if area != "" {
-				t.TaskMetadata.Area = area
-				changed = true
-			}
-			if project != "" {
-				t.TaskMetadata.ProjectID = project
-				changed = true
 			}
-			if estimate >= 0 {
-				t.TaskMetadata.Estimate = estimate
-				changed = true
+			cyclic := false
+			for _, depID := range dependsOn {
+				if denote.WouldCreateCycle(allTasks, t.File.ID, depID) {
+					cyclic = true
+					break
+				}
 			}
-			if status != "" {
-				t.TaskMetadata.Status = status
-				changed = true
+			if cyclic {
+				fmt.Fprintf(os.Stderr, "Dependencies for task ID %d would create a cycle\n", id)
+				continue
 			}
 
-			if changed {
-				if err := task.UpdateTaskFile(t.File.Path, t.TaskMetadata); err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to update task ID %d: %v\n", id, err)
-					continue
+			// Apply updates to the metadata as it is on disk
+			wasDone := false
+			err := task.ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+				wasDone = meta.Status == denote.TaskStatusDone
+				if priority != "" {
+					meta.Priority = priority
 				}
-				updated++
-				if !globalFlags.Quiet {
-					fmt.Printf("Updated task ID %d: %s\n", id, t.TaskMetadata.Title)
+				if parsedDue != "" {
+					meta.DueDate = parsedDue
+				}
+				if area != "" {
+					meta.Area = area
+				}
+				if project != "" {
+					meta.ProjectID = project
+				}
+				if estimate >= 0 {
+					meta.Estimate = estimate
+				}
+				if status != "" {
+					meta.SetStatus(status, time.Now())
+					if status != denote.TaskStatusOpen {
+						meta.StopTimer(time.Now())
+					}
+				}
+				if recur == "none" {
+					meta.Recurrence = ""
+				} else if recur != "" {
+					meta.Recurrence = recur
+				}
+				if depends == "none" {
+					meta.DependsOn = nil
+				} else if len(dependsOn) > 0 {
+					meta.DependsOn = dependsOn
 				}
+				t.TaskMetadata = *meta
+				return nil
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to update task ID %d: %v\n", id, err)
+				continue
+			}
+			updated++
+			if !globalFlags.Quiet {
+				fmt.Printf("Updated task ID %d: %s\n", id, t.TaskMetadata.Title)
+			}
+			if !wasDone && t.TaskMetadata.Status == denote.TaskStatusDone {
+				createNextOccurrence(t)
 			}
 		}
 
@@ -621,22 +1187,14 @@ func taskDoneCommand(cfg *config.Config) *Command {
 		}
 
 		// Get all tasks
-		scanner := denote.NewScanner(cfg.NotesDirectory)
-		files, err := scanner.FindAllTaskAndProjectFiles()
+		result, err := scanDirectory(cfg)
 		if err != nil {
-			return fmt.Errorf("failed to scan directory: %v", err)
+			return err
 		}
 
 		// Build index of tasks by index_id
 		tasksByID := make(map[int]*denote.Task)
-		for _, file := range files {
-			if !file.IsTask() {
-				continue
-			}
-			t, err := denote.ParseTaskFile(file.Path)
-			if err != nil {
-				continue
-			}
+		for _, t := range result.Tasks {
 			tasksByID[t.TaskMetadata.IndexID] = t
 		}
 
@@ -649,8 +1207,15 @@ func taskDoneCommand(cfg *config.Config) *Command {
 				continue
 			}
 
-			t.TaskMetadata.Status = denote.TaskStatusDone
-			if err := task.UpdateTaskFile(t.File.Path, t.TaskMetadata); err != nil {
+			wasDone := false
+			err := task.ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+				wasDone = meta.Status == denote.TaskStatusDone
+				meta.SetStatus(denote.TaskStatusDone, time.Now())
+				meta.StopTimer(time.Now())
+				t.TaskMetadata = *meta
+				return nil
+			})
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to mark task ID %d as done: %v\n", id, err)
 				continue
 			}
@@ -658,6 +1223,9 @@ func taskDoneCommand(cfg *config.Config) *Command {
 			if !globalFlags.Quiet {
 				fmt.Printf("✓ Task ID %d marked as done: %s\n", id, t.TaskMetadata.Title)
 			}
+			if !wasDone {
+				createNextOccurrence(t)
+			}
 		}
 
 		if updated == 0 && !globalFlags.Quiet {
@@ -670,6 +1238,28 @@ func taskDoneCommand(cfg *config.Config) *Command {
 	return cmd
 }
 
+// createNextOccurrence creates the next instance of a recurring task that
+// was just marked done
+func createNextOccurrence(t *denote.Task) {
+	if t.TaskMetadata.Recurrence == "" {
+		return
+	}
+
+	next, err := task.CreateNextInstance(t, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create next occurrence of task ID %d: %v\n", t.TaskMetadata.IndexID, err)
+		return
+	}
+
+	if !globalFlags.Quiet {
+		when := "due " + next.TaskMetadata.DueDate
+		if next.TaskMetadata.DueDate == "" {
+			when = "starts " + next.TaskMetadata.StartDate
+		}
+		fmt.Printf("↻ Next occurrence: task ID %d %s\n", next.TaskMetadata.IndexID, when)
+	}
+}
+
 func taskLogCommand(cfg *config.Config) *Command {
 	cmd := &Command{
 		Name:        "log",
@@ -692,25 +1282,16 @@ func taskLogCommand(cfg *config.Config) *Command {
 		message := strings.Join(args[1:], " ")
 
 		// Get all tasks
-		scanner := denote.NewScanner(cfg.NotesDirectory)
-		files, err := scanner.FindAllTaskAndProjectFiles()
+		result, err := scanDirectory(cfg)
 		if err != nil {
-			return fmt.Errorf("failed to scan directory: %v", err)
+			return err
 		}
 
 		// Find the task by index_id
-		for _, file := range files {
-			if !file.IsTask() {
-				continue
-			}
-			// Parse the task
-			task, err := denote.ParseTaskFile(file.Path)
-			if err != nil {
-				continue
-			}
+		for _, task := range result.Tasks {
 			if task.TaskMetadata.IndexID == taskNum {
 				// Add log entry
-				if err := denote.AddLogEntry(file.Path, message); err != nil {
+				if err := denote.AddLogEntry(task.File.Path, message); err != nil {
 					return fmt.Errorf("failed to add log entry: %v", err)
 				}
 
@@ -727,6 +1308,85 @@ func taskLogCommand(cfg *config.Config) *Command {
 	return cmd
 }
 
+func taskCheckCommand(cfg *config.Config) *Command {
+	cmd := &Command{
+		Name:        "check",
+		Usage:       "denote-tasks task check <task-id> [item-numbers]",
+		Description: "List a task's checklist or toggle checklist items",
+	}
+
+	cmd.Run = func(c *Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("task ID required")
+		}
+
+		// Parse task ID
+		taskNum, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid task ID: %s", args[0])
+		}
+
+		var items []int
+		if len(args) > 1 {
+			items, err = parseTaskIDs(args[1:])
+			if err != nil {
+				return fmt.Errorf("invalid item numbers: %v", err)
+			}
+		}
+
+		// Get all tasks
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+
+		t := findTaskByIndexID(result.Tasks, taskNum)
+		if t == nil {
+			return fmt.Errorf("task with ID %d not found", taskNum)
+		}
+		if len(t.Checklist) == 0 {
+			return fmt.Errorf("task ID %d has no checklist items", taskNum)
+		}
+
+		for _, n := range items {
+			item, err := denote.ToggleChecklistItem(t.File.Path, n)
+			if err != nil {
+				return fmt.Errorf("failed to toggle item %d: %v", n, err)
+			}
+			t.Checklist[n-1] = *item
+			if !globalFlags.Quiet {
+				mark := "☐"
+				if item.Done {
+					mark = "☑"
+				}
+				fmt.Printf("%s %s\n", mark, item.Text)
+			}
+		}
+
+		if globalFlags.Quiet {
+			return nil
+		}
+
+		done, total := denote.ChecklistProgress(t.Checklist)
+		if len(items) > 0 {
+			fmt.Printf("Task ID %d: %d/%d done\n", taskNum, done, total)
+			return nil
+		}
+
+		fmt.Printf("%s (%d/%d):\n", t.TaskMetadata.Title, done, total)
+		for i, item := range t.Checklist {
+			mark := " "
+			if item.Done {
+				mark = "x"
+			}
+			fmt.Printf("%3d [%s] %s%s\n", i+1, mark, strings.Repeat(" ", item.Indent), item.Text)
+		}
+		return nil
+	}
+
+	return cmd
+}
+
 func taskEditCommand(cfg *config.Config) *Command {
 	return &Command{
 		Name:        "edit",
@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pdxmph/denote-tasks/internal/config"
+	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/task"
+)
+
+// taskStartCommand starts a timer on a task
+func taskStartCommand(cfg *config.Config) *Command {
+	cmd := &Command{
+		Name:        "start",
+		Usage:       "denote-tasks task start <task-id>",
+		Description: "Start tracking time on a task (stops any other running timer)",
+	}
+
+	cmd.Run = func(c *Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("task ID required")
+		}
+
+		taskNum, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid task ID: %s", args[0])
+		}
+
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+
+		t := findTaskByIndexID(result.Tasks, taskNum)
+		if t == nil {
+			return fmt.Errorf("task with ID %d not found", taskNum)
+		}
+
+		now := time.Now()
+		stopped, err := task.StartTimer(t, result.Tasks, now)
+		if !globalFlags.Quiet {
+			for _, s := range stopped {
+				fmt.Printf("■ Stopped timer on task ID %d: %s (%s)\n", s.TaskMetadata.IndexID, s.TaskMetadata.Title,
+					denote.FormatDuration(s.RunningTimer().Duration(now)))
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to start timer: %v", err)
+		}
+
+		if !globalFlags.Quiet {
+			fmt.Printf("⏱ Started timer on task ID %d: %s\n", taskNum, t.TaskMetadata.Title)
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+// taskStopCommand stops a running timer
+func taskStopCommand(cfg *config.Config) *Command {
+	cmd := &Command{
+		Name:        "stop",
+		Usage:       "denote-tasks task stop [task-id]",
+		Description: "Stop the running timer (or the timer on the given task)",
+	}
+
+	cmd.Run = func(c *Command, args []string) error {
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+
+		running := denote.FindRunningTimers(result.Tasks)
+		if len(args) > 0 {
+			taskNum, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid task ID: %s", args[0])
+			}
+			t := findTaskByIndexID(result.Tasks, taskNum)
+			if t == nil {
+				return fmt.Errorf("task with ID %d not found", taskNum)
+			}
+			running = []*denote.Task{t}
+		}
+
+		if len(running) == 0 {
+			return fmt.Errorf("no timer running")
+		}
+
+		now := time.Now()
+		for _, t := range running {
+			elapsed, err := task.StopTimer(t.File.Path, now)
+			if err != nil {
+				return fmt.Errorf("failed to stop timer on task ID %d: %v", t.TaskMetadata.IndexID, err)
+			}
+			if !globalFlags.Quiet {
+				fmt.Printf("■ Stopped timer on task ID %d: %s (%s, %s total)\n", t.TaskMetadata.IndexID, t.TaskMetadata.Title,
+					denote.FormatDuration(elapsed), denote.FormatDuration(t.TrackedTime(now)))
+			}
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+// timeReportRow aggregates tracked time and estimates for one report line
+type timeReportRow struct {
+	Name     string
+	Tasks    int
+	Estimate int
+	Actual   time.Duration
+}
+
+// ReportCommand creates the report command comparing tracked time with
+// estimates
+func ReportCommand(cfg *config.Config) *Command {
+	var (
+		by  string
+		all bool
+	)
+
+	cmd := &Command{
+		Name:  "report",
+		Usage: "denote-tasks report [--by task|project|area] [--all]",
+		Description: `Compare tracked time with estimates
+
+Estimates are Fibonacci points, so the report shows tracked time per
+estimate point alongside the totals. Only tasks with tracked time are
+included unless --all is given.`,
+		Flags: flag.NewFlagSet("report", flag.ExitOnError),
+	}
+
+	cmd.Flags.StringVar(&by, "by", "task", "Group by: task, project, area")
+	cmd.Flags.BoolVar(&all, "all", false, "Include estimated tasks with no tracked time")
+
+	cmd.Run = func(c *Command, args []string) error {
+		if by != "task" && by != "project" && by != "area" {
+			return fmt.Errorf("invalid grouping: %s (valid: task, project, area)", by)
+		}
+
+		result, err := scanDirectory(cfg)
+		if err != nil {
+			return err
+		}
+
+		projectNames := make(map[string]string) // ID -> Title
+		for _, p := range result.Projects {
+			projectNames[p.File.ID] = p.ProjectMetadata.Title
+		}
+
+		now := time.Now()
+		rows := make(map[string]*timeReportRow)
+		var order []string
+		total := &timeReportRow{Name: "Total"}
+
+		for _, t := range result.Tasks {
+			if globalFlags.Area != "" && t.TaskMetadata.Area != globalFlags.Area {
+				continue
+			}
+
+			actual := t.TrackedTime(now)
+			if actual == 0 && (!all || t.TaskMetadata.Estimate == 0) {
+				continue
+			}
+
+			var key, name string
+			switch by {
+			case "project":
+				key = t.TaskMetadata.ProjectID
+				name = "(no project)"
+				if key != "" {
+					name = projectNames[key]
+					if name == "" {
+						name = key
+					}
+				}
+			case "area":
+				key = t.TaskMetadata.Area
+				name = key
+				if name == "" {
+					name = "(no area)"
+				}
+			default:
+				key = t.File.ID
+				name = fmt.Sprintf("%d %s", t.TaskMetadata.IndexID, t.TaskMetadata.Title)
+				if t.RunningTimer() != nil {
+					name += " ⏱"
+				}
+			}
+
+			row, ok := rows[key]
+			if !ok {
+				row = &timeReportRow{Name: name}
+				rows[key] = row
+				order = append(order, key)
+			}
+			for _, r := range []*timeReportRow{row, total} {
+				r.Tasks++
+				r.Estimate += t.TaskMetadata.Estimate
+				r.Actual += actual
+			}
+		}
+
+		if len(order) == 0 {
+			if !globalFlags.Quiet {
+				fmt.Println("No tracked time")
+			}
+			return nil
+		}
+
+		// Largest tracked time first
+		sort.SliceStable(order, func(i, j int) bool {
+			return rows[order[i]].Actual > rows[order[j]].Actual
+		})
+
+		fmt.Printf("%-50s %5s %8s %10s %10s\n", "Time by "+by, "Tasks", "Estimate", "Actual", "Per point")
+		for _, key := range order {
+			printTimeReportRow(rows[key])
+		}
+		fmt.Println()
+		printTimeReportRow(total)
+
+		return nil
+	}
+
+	return cmd
+}
+
+// printTimeReportRow prints one line of the time report
+func printTimeReportRow(row *timeReportRow) {
+	name := row.Name
+	if len(name) > 50 {
+		name = name[:47] + "..."
+	}
+
+	estimate := "-"
+	perPoint := "-"
+	if row.Estimate > 0 {
+		estimate = strconv.Itoa(row.Estimate)
+		perPoint = denote.FormatDuration(row.Actual / time.Duration(row.Estimate))
+	}
+
+	fmt.Printf("%-50s %5d %8s %10s %10s\n", name, row.Tasks, estimate, denote.FormatDuration(row.Actual), perPoint)
+}
+
+// findTaskByIndexID returns the task with the given index ID, or nil
+func findTaskByIndexID(tasks []*denote.Task, indexID int) *denote.Task {
+	for _, t := range tasks {
+		if t.TaskMetadata.IndexID == indexID {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/pdxmph/denote-tasks/internal/config"
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+// UndoCommand creates the undo command
+func UndoCommand(cfg *config.Config) *Command {
+	var (
+		list  bool
+		force bool
+	)
+
+	cmd := &Command{
+		Name:        "undo",
+		Usage:       "denote-tasks undo [--list] [--force]",
+		Description: "Undo the last change to task and project files",
+		Flags:       flag.NewFlagSet("undo", flag.ExitOnError),
+	}
+
+	cmd.Flags.BoolVar(&list, "list", false, "List undoable operations, newest first")
+	cmd.Flags.BoolVar(&force, "force", false, "Undo even if files were modified since")
+
+	cmd.Run = func(c *Command, args []string) error {
+		if list {
+			printJournal(denote.LoadJournal(cfg.NotesDirectory))
+			return nil
+		}
+
+		entry, err := denote.Undo(cfg.NotesDirectory, force)
+		if err != nil {
+			return journalError(err)
+		}
+
+		if !globalFlags.Quiet {
+			fmt.Printf("↶ Undid: %s (%s)\n", entry.Summary, fileCount(entry))
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+// RedoCommand creates the redo command
+func RedoCommand(cfg *config.Config) *Command {
+	var force bool
+
+	cmd := &Command{
+		Name:        "redo",
+		Usage:       "denote-tasks redo [--force]",
+		Description: "Redo the last undone change",
+		Flags:       flag.NewFlagSet("redo", flag.ExitOnError),
+	}
+
+	cmd.Flags.BoolVar(&force, "force", false, "Redo even if files were modified since")
+
+	cmd.Run = func(c *Command, args []string) error {
+		entry, err := denote.Redo(cfg.NotesDirectory, force)
+		if err != nil {
+			return journalError(err)
+		}
+
+		if !globalFlags.Quiet {
+			fmt.Printf("↷ Redid: %s (%s)\n", entry.Summary, fileCount(entry))
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+// journalError adds a hint to undo/redo conflicts
+func journalError(err error) error {
+	if errors.Is(err, denote.ErrJournalConflict) {
+		return fmt.Errorf("%v (use --force to discard that change)", err)
+	}
+	return err
+}
+
+// printJournal lists the undo and redo stacks, newest first
+func printJournal(j *denote.Journal) {
+	if len(j.Undo) == 0 && len(j.Redo) == 0 {
+		fmt.Println("Nothing to undo")
+		return
+	}
+
+	if len(j.Redo) > 0 {
+		fmt.Println("Redo:")
+		for i := len(j.Redo) - 1; i >= 0; i-- {
+			printJournalEntry(j.Redo[i])
+		}
+		fmt.Println()
+	}
+
+	if len(j.Undo) > 0 {
+		fmt.Println("Undo:")
+		for i := len(j.Undo) - 1; i >= 0; i-- {
+			printJournalEntry(j.Undo[i])
+		}
+	}
+}
+
+// printJournalEntry prints one journal line
+func printJournalEntry(entry *denote.JournalEntry) {
+	fmt.Printf("  %s  %-40s %s\n", entry.Time.Format("2006-01-02 15:04"), entry.Summary, fileCount(entry))
+}
+
+// fileCount describes how many files an entry touched
+func fileCount(entry *denote.JournalEntry) string {
+	if len(entry.Files) == 1 {
+		return "1 file"
+	}
+	return fmt.Sprintf("%d files", len(entry.Files))
+}
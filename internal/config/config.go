@@ -3,30 +3,147 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/BurntSushi/toml"
+	"github.com/pdxmph/denote-tasks/internal/query"
 )
 
 // Config represents the application configuration
 type Config struct {
-	NotesDirectory string       `toml:"notes_directory"` // Keep name for backward compatibility
-	Editor         string       `toml:"editor"`
-	DefaultArea    string       `toml:"default_area"`
-	SoonHorizon    int          `toml:"soon_horizon"`  // Days for "soon" filter, default 3
-	TUI            TUIConfig    `toml:"tui"`
-	Tasks          TasksConfig  `toml:"tasks"`
+	NotesDirectory string                `toml:"notes_directory"` // Keep name for backward compatibility
+	Editor         string                `toml:"editor"`
+	DefaultArea    string                `toml:"default_area"`
+	SoonHorizon    int                   `toml:"soon_horizon"`  // Days for "soon" filter, default 3
+	TUI            TUIConfig             `toml:"tui"`
+	Tasks          TasksConfig           `toml:"tasks"`
+	Scan           ScanConfig            `toml:"scan"`
+	CalDAV         CalDAVConfig          `toml:"caldav"`
+	Views          map[string]ViewConfig `toml:"views"` // Named views, by name
+	Keys           KeyBindings           `toml:"keys"`  // TUI key overrides
 }
 
 // TUIConfig represents TUI-specific settings
 type TUIConfig struct {
-	Theme string `toml:"theme"`
+	Theme   string            `toml:"theme"`  // default, dark, light, high-contrast, minimal
+	Colors  map[string]string `toml:"colors"` // Palette overrides, by role
+	NoColor bool              `toml:"-"`      // Set by --no-color
 }
 
 // TasksConfig represents task-specific settings
 type TasksConfig struct {
 	SortBy    string `toml:"sort_by"`    // due, priority, project, estimate, title, created, modified
 	SortOrder string `toml:"sort_order"` // normal, reverse
+	FileType  string `toml:"file_type"`  // New files: markdown-yaml, markdown-toml, org, text
+}
+
+// ScanConfig controls which files under notes_directory are read
+type ScanConfig struct {
+	Recursive      bool     `toml:"recursive"`       // Include subdirectories, default true
+	Include        []string `toml:"include"`         // If set, only paths matching these globs
+	Exclude        []string `toml:"exclude"`         // Skip paths matching these globs
+	FollowSymlinks bool     `toml:"follow_symlinks"` // Descend into symlinked directories
+}
+
+// KeyBindings maps TUI actions, such as "list.filter_menu", to the keys
+// that trigger them. In the config file an action takes one key or a list,
+// and an empty list unbinds it:
+//
+//	[keys]
+//	list.filter_menu = "ctrl+f"
+//	list.delete = ["x", "delete"]
+type KeyBindings map[string][]string
+
+// UnmarshalTOML reads the [keys] table, where dotted action names arrive
+// as nested tables
+func (k *KeyBindings) UnmarshalTOML(data interface{}) error {
+	table, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("keys must be a table")
+	}
+	*k = make(KeyBindings)
+	return k.add("", table)
+}
+
+func (k KeyBindings) add(prefix string, table map[string]interface{}) error {
+	for name, value := range table {
+		action := prefix + name
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if err := k.add(action+".", v); err != nil {
+				return err
+			}
+		case string:
+			k[action] = []string{v}
+		case []interface{}:
+			keys := make([]string, 0, len(v))
+			for _, key := range v {
+				s, ok := key.(string)
+				if !ok {
+					return fmt.Errorf("keys.%s: keys must be strings", action)
+				}
+				keys = append(keys, s)
+			}
+			k[action] = keys
+		default:
+			return fmt.Errorf("keys.%s: expected a key or a list of keys", action)
+		}
+	}
+	return nil
+}
+
+// ViewConfig is a named combination of filters and display settings,
+// used by `list --view` and the TUI view switcher
+type ViewConfig struct {
+	Where    string   `toml:"where"` // Query, as for --where
+	Area     string   `toml:"area"`
+	Priority string   `toml:"priority"` // p1, p2, p3
+	State    string   `toml:"state"`    // Task status, or "active" for open and delegated
+	Soon     bool     `toml:"soon"`     // Due within soon_horizon days
+	Sort     string   `toml:"sort"`     // As tasks sort_by
+	Order    string   `toml:"order"`    // normal, reverse
+	Group    string   `toml:"group"`    // area, project, priority, status
+	Columns  []string `toml:"columns"`  // Task columns to show, in order
+}
+
+// ViewGroups are the values of a view's group setting
+var ViewGroups = []string{"area", "project", "priority", "status"}
+
+// ViewColumns are the columns a view can show
+var ViewColumns = []string{"id", "status", "priority", "estimate", "due", "title", "tags", "area", "project"}
+
+// ViewNames returns the names of the configured views, sorted
+func (c *Config) ViewNames() []string {
+	names := make([]string, 0, len(c.Views))
+	for name := range c.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// View returns a configured view by name
+func (c *Config) View(name string) (ViewConfig, error) {
+	view, ok := c.Views[name]
+	if !ok {
+		if len(c.Views) == 0 {
+			return view, fmt.Errorf("unknown view %q (no views are configured)", name)
+		}
+		return view, fmt.Errorf("unknown view %q (configured: %s)", name, strings.Join(c.ViewNames(), ", "))
+	}
+	return view, nil
+}
+
+// CalDAVConfig represents CalDAV sync settings
+type CalDAVConfig struct {
+	URL            string `toml:"url"`             // Calendar collection URL
+	Username       string `toml:"username"`
+	Password       string `toml:"password"`        // Or set DENOTE_TASKS_CALDAV_PASSWORD
+	ConflictPolicy string `toml:"conflict_policy"` // newest, local, remote, skip
+	Area           string `toml:"area"`            // Only sync tasks in this area
 }
 
 // DefaultConfig returns default configuration
@@ -43,6 +160,13 @@ func DefaultConfig() *Config {
 		Tasks: TasksConfig{
 			SortBy:    "due",
 			SortOrder: "normal", // Closest due dates first
+			FileType:  "markdown-yaml",
+		},
+		Scan: ScanConfig{
+			Recursive: true,
+		},
+		CalDAV: CalDAVConfig{
+			ConflictPolicy: "newest",
 		},
 	}
 }
@@ -166,9 +290,78 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid tasks sort_order: %s (valid: normal, reverse)", c.Tasks.SortOrder)
 	}
 
+	switch c.Tasks.FileType {
+	case "", "markdown-yaml", "markdown-toml", "org", "text":
+	default:
+		return fmt.Errorf("invalid tasks file_type: %s (valid: markdown-yaml, markdown-toml, org, text)", c.Tasks.FileType)
+	}
+
+	for _, pattern := range append(append([]string{}, c.Scan.Include...), c.Scan.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid scan pattern: %s", pattern)
+		}
+	}
+
+	for _, name := range c.ViewNames() {
+		if err := c.Views[name].validate(); err != nil {
+			return fmt.Errorf("invalid view %s: %w", name, err)
+		}
+	}
+
+	switch c.CalDAV.ConflictPolicy {
+	case "", "newest", "local", "remote", "skip":
+	default:
+		return fmt.Errorf("invalid caldav conflict_policy: %s (valid: newest, local, remote, skip)", c.CalDAV.ConflictPolicy)
+	}
+
+	return nil
+}
+
+// validate checks a view's settings
+func (v ViewConfig) validate() error {
+	if v.Where != "" {
+		if _, err := query.Parse(v.Where); err != nil {
+			return fmt.Errorf("where: %w", err)
+		}
+	}
+	switch v.Priority {
+	case "", "p1", "p2", "p3":
+	default:
+		return fmt.Errorf("invalid priority: %s (valid: p1, p2, p3)", v.Priority)
+	}
+	switch v.State {
+	case "", "active", "open", "done", "paused", "delegated", "dropped":
+	default:
+		return fmt.Errorf("invalid state: %s (valid: active, open, done, paused, delegated, dropped)", v.State)
+	}
+	switch v.Sort {
+	case "", "due", "priority", "project", "estimate", "title", "created", "modified":
+	default:
+		return fmt.Errorf("invalid sort: %s (valid: due, priority, project, estimate, title, created, modified)", v.Sort)
+	}
+	if v.Order != "" && v.Order != "normal" && v.Order != "reverse" {
+		return fmt.Errorf("invalid order: %s (valid: normal, reverse)", v.Order)
+	}
+	if v.Group != "" && !contains(ViewGroups, v.Group) {
+		return fmt.Errorf("invalid group: %s (valid: %s)", v.Group, strings.Join(ViewGroups, ", "))
+	}
+	for _, column := range v.Columns {
+		if !contains(ViewColumns, column) {
+			return fmt.Errorf("invalid column: %s (valid: %s)", column, strings.Join(ViewColumns, ", "))
+		}
+	}
 	return nil
 }
 
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 // findConfigFile looks for config in standard locations
 func findConfigFile() string {
 	// Check XDG_CONFIG_HOME first
@@ -13,6 +13,8 @@ type FilterOptions struct {
 	Overdue   bool
 	DueToday  bool
 	DueWeek   bool
+	Blocked   bool // Tasks waiting on unfinished dependencies
+	Ready     bool // Open tasks with no unfinished dependencies
 }
 
 // ApplyFilters applies multiple filters to a task list
@@ -54,6 +56,14 @@ func ApplyFilters(tasks []*denote.Task, opts FilterOptions) []*denote.Task {
 		filtered = denote.FilterTasks(filtered, "week", "")
 	}
 
+	// Apply dependency filters (requires denote.ResolveDependencies)
+	if opts.Blocked {
+		filtered = denote.FilterTasks(filtered, "blocked", "")
+	}
+	if opts.Ready {
+		filtered = denote.FilterTasks(filtered, "ready", "")
+	}
+
 	return filtered
 }
 
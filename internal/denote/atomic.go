@@ -0,0 +1,104 @@
+package denote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// tempFile is the part of *os.File used while writing a temp file
+type tempFile interface {
+	Name() string
+	Write(b []byte) (int, error)
+	Sync() error
+	Close() error
+}
+
+// File system operations behind the functions in this file, replaced in
+// tests to inject failures
+var (
+	createTemp = func(dir, pattern string) (tempFile, error) { return os.CreateTemp(dir, pattern) }
+	rename     = os.Rename
+	remove     = os.Remove
+)
+
+// WriteFileAtomic writes data to path so that readers (and a crash) see
+// either the old contents or the new, never a partial file. The data is
+// written to a temp file in the same directory, synced and renamed over the
+// original. An existing file keeps its permissions; new files get perm.
+// If path is a symlink, its target is replaced and the link is kept.
+// The write is recorded in the active undo operation, if any.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	recordBeforeImage(path)
+	return writeFileAtomic(path, data, perm)
+}
+
+// writeFileAtomic is WriteFileAtomic without undo recording
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := createTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// Remove the temp file on any failure before the rename
+	renamed := false
+	defer func() {
+		if !renamed {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+
+	if err := rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	renamed = true
+
+	// Persist the rename itself; not all platforms support syncing a
+	// directory, so failures here are ignored
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
+
+// RenameFile renames a note, recording both paths in the active undo
+// operation, if any
+func RenameFile(oldPath, newPath string) error {
+	recordBeforeImage(oldPath)
+	recordBeforeImage(newPath)
+	return rename(oldPath, newPath)
+}
+
+// RemoveFile deletes a note, recording it in the active undo operation,
+// if any
+func RemoveFile(path string) error {
+	recordBeforeImage(path)
+	return remove(path)
+}
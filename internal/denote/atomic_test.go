@@ -0,0 +1,222 @@
+package denote
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var errInjected = errors.New("injected failure")
+
+// faultyFile wraps a temp file, failing the chosen operation
+type faultyFile struct {
+	tempFile
+	failWrite bool
+	failSync  bool
+}
+
+func (f *faultyFile) Write(b []byte) (int, error) {
+	if f.failWrite {
+		// Leave a partial write behind, as a full disk would
+		f.tempFile.Write(b[:len(b)/2])
+		return len(b) / 2, errInjected
+	}
+	return f.tempFile.Write(b)
+}
+
+func (f *faultyFile) Sync() error {
+	if f.failSync {
+		return errInjected
+	}
+	return f.tempFile.Sync()
+}
+
+// stub replaces a file system operation for the rest of the test
+func stub[T any](t *testing.T, target *T, fake T) {
+	t.Helper()
+	orig := *target
+	*target = fake
+	t.Cleanup(func() { *target = orig })
+}
+
+// assertUnchanged checks that path still holds want and that no temp
+// files were left in its directory
+func assertUnchanged(t *testing.T, path, want string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("original file: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("original file = %q, want %q", got, want)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("temp file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestWriteFileAtomicFailures(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T)
+	}{
+		{"create", func(t *testing.T) {
+			stub(t, &createTemp, func(dir, pattern string) (tempFile, error) {
+				return nil, errInjected
+			})
+		}},
+		{"write", func(t *testing.T) {
+			orig := createTemp
+			stub(t, &createTemp, func(dir, pattern string) (tempFile, error) {
+				f, err := orig(dir, pattern)
+				return &faultyFile{tempFile: f, failWrite: true}, err
+			})
+		}},
+		{"sync", func(t *testing.T) {
+			orig := createTemp
+			stub(t, &createTemp, func(dir, pattern string) (tempFile, error) {
+				f, err := orig(dir, pattern)
+				return &faultyFile{tempFile: f, failSync: true}, err
+			})
+		}},
+		{"rename", func(t *testing.T) {
+			stub(t, &rename, func(oldPath, newPath string) error {
+				return errInjected
+			})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "20240101T090000--note__task.md")
+			if err := os.WriteFile(path, []byte("original"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			tt.setup(t)
+
+			err := WriteFileAtomic(path, []byte("replacement contents"), 0644)
+			if !errors.Is(err, errInjected) {
+				t.Fatalf("WriteFileAtomic error = %v, want the injected failure", err)
+			}
+			assertUnchanged(t, path, "original")
+		})
+	}
+}
+
+func TestWriteFileAtomicNewFileFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "20240101T090000--new__task.md")
+	stub(t, &rename, func(oldPath, newPath string) error {
+		return errInjected
+	})
+
+	if err := WriteFileAtomic(path, []byte("data"), 0644); !errors.Is(err, errInjected) {
+		t.Fatalf("WriteFileAtomic error = %v, want the injected failure", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file exists after a failed write: %v", err)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestWriteFileAtomicPreservesMode(t *testing.T) {
+	dir := t.TempDir()
+
+	existing := filepath.Join(dir, "existing.md")
+	if err := os.WriteFile(existing, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(existing, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(existing, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(existing); info.Mode().Perm() != 0600 {
+		t.Errorf("existing file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	created := filepath.Join(dir, "created.md")
+	if err := WriteFileAtomic(created, []byte("new"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(created); info.Mode().Perm() != 0640 {
+		t.Errorf("new file mode = %v, want 0640", info.Mode().Perm())
+	}
+}
+
+func TestWriteFileAtomicSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.md")
+	link := filepath.Join(dir, "link.md")
+	if err := os.WriteFile(target, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := WriteFileAtomic(link, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("link was replaced by a regular file")
+	}
+	if got, _ := os.ReadFile(target); string(got) != "new" {
+		t.Errorf("target = %q, want %q", got, "new")
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestRenameFileFailure(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.md")
+	newPath := filepath.Join(dir, "new.md")
+	if err := os.WriteFile(oldPath, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stub(t, &rename, func(oldPath, newPath string) error {
+		return errInjected
+	})
+
+	if err := RenameFile(oldPath, newPath); !errors.Is(err, errInjected) {
+		t.Fatalf("RenameFile error = %v, want the injected failure", err)
+	}
+	assertUnchanged(t, oldPath, "data")
+	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+		t.Errorf("new path exists after a failed rename: %v", err)
+	}
+}
+
+func TestRemoveFileFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.md")
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stub(t, &remove, func(path string) error {
+		return errInjected
+	})
+
+	if err := RemoveFile(path); !errors.Is(err, errInjected) {
+		t.Fatalf("RemoveFile error = %v, want the injected failure", err)
+	}
+	assertUnchanged(t, path, "data")
+}
@@ -0,0 +1,87 @@
+package denote
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// checklistPattern matches Markdown task list items: "- [ ] text" or
+// "- [x] text", with any list marker and indentation
+var checklistPattern = regexp.MustCompile(`^(\s*[-*+]\s+\[)([ xX])(\]\s+)(.*)$`)
+
+// ChecklistItem is one "- [ ]" / "- [x]" item in a note body
+type ChecklistItem struct {
+	Text   string `json:"text"`
+	Done   bool   `json:"done"`
+	Indent int    `json:"indent,omitempty"` // Leading whitespace width, for nested items
+	Line   int    `json:"line"`             // Zero-based line number in the file
+}
+
+// ParseChecklist returns the checklist items in a file's content. Lines in
+// the frontmatter and inside fenced code blocks are ignored.
+func ParseChecklist(content string) []ChecklistItem {
+	lines := strings.Split(content, "\n")
+
+	var items []ChecklistItem
+	inFence := false
+	for i := FrontmatterLines(lines); i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		match := checklistPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		items = append(items, ChecklistItem{
+			Text:   strings.TrimSpace(match[4]),
+			Done:   match[2] != " ",
+			Indent: len(match[1]) - len(strings.TrimLeft(match[1], " \t")),
+			Line:   i,
+		})
+	}
+	return items
+}
+
+// ChecklistProgress returns the number of completed items and the total
+func ChecklistProgress(items []ChecklistItem) (done, total int) {
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+	return done, len(items)
+}
+
+// ToggleChecklistItem flips the n-th (1-based) checklist item in a file and
+// returns the item with its new state
+func ToggleChecklistItem(path string, n int) (*ChecklistItem, error) {
+	var item ChecklistItem
+	err := RewriteFile(path, func(content string) (string, error) {
+		items := ParseChecklist(content)
+		if n < 1 || n > len(items) {
+			return "", fmt.Errorf("no checklist item %d (task has %d)", n, len(items))
+		}
+		item = items[n-1]
+		item.Done = !item.Done
+
+		mark := " "
+		if item.Done {
+			mark = "x"
+		}
+		lines := strings.Split(content, "\n")
+		lines[item.Line] = checklistPattern.ReplaceAllString(lines[item.Line], "${1}"+mark+"${3}${4}")
+		return strings.Join(lines, "\n"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
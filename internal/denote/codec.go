@@ -0,0 +1,654 @@
+package denote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File types, as Denote names them
+const (
+	FileTypeMarkdownYAML = "markdown-yaml"
+	FileTypeOrg          = "org"
+	FileTypeText         = "text"
+)
+
+// FrontmatterCodec reads and writes one front matter syntax. Metadata goes
+// through YAML on the way in, so every syntax shares the same metadata
+// types and type detection. Markdown files can use either YAML or TOML;
+// new Markdown files get YAML unless a file type says otherwise.
+type FrontmatterCodec interface {
+	// FileType is the Denote file type name, such as "org"
+	FileType() string
+
+	// Extension is the file extension, including the dot
+	Extension() string
+
+	// HeaderLines returns the number of leading lines holding the front
+	// matter, or 0 if lines don't start with a complete header
+	HeaderLines(lines []string) int
+
+	// Decode converts header lines to a YAML document
+	Decode(header []string) (string, error)
+
+	// Encode renders metadata as a header ending in a newline. Fields of
+	// the original header (nil for a new file) that the metadata type
+	// doesn't define are kept.
+	Encode(metadata interface{}, original []string) (string, error)
+
+	// SetField sets a single field in content's header, or removes it if
+	// value is empty, leaving the rest of the file untouched. Content
+	// without a header gets one.
+	SetField(content, field, value string) string
+}
+
+// codecs lists the supported syntaxes in detection order
+var codecs = []FrontmatterCodec{
+	yamlCodec{},
+	tomlCodec{},
+	&lineCodec{
+		fileType:   FileTypeOrg,
+		extension:  ".org",
+		prefix:     "#+",
+		separator:  " ",
+		keyword:    regexp.MustCompile(`^#\+([\w-]+):\s*(.*?)\s*$`),
+		dateLayout: "[2006-01-02 Mon 15:04]",
+		tagsKey:    "filetags",
+	},
+	&lineCodec{
+		fileType:   FileTypeText,
+		extension:  ".txt",
+		separator:  "  ",
+		keyword:    regexp.MustCompile(`^([\w-]+):\s*(.*?)\s*$`),
+		closing:    strings.Repeat("-", 27),
+		dateLayout: "2006-01-02",
+		tagsKey:    "tags",
+	},
+}
+
+// FileTypes returns the names of the supported file types
+func FileTypes() []string {
+	var names []string
+	for _, c := range codecs {
+		names = append(names, c.FileType())
+	}
+	return names
+}
+
+// NoteExtensions returns the file extensions of the supported file types
+func NoteExtensions() []string {
+	var exts []string
+	seen := make(map[string]bool)
+	for _, c := range codecs {
+		if !seen[c.Extension()] {
+			seen[c.Extension()] = true
+			exts = append(exts, c.Extension())
+		}
+	}
+	return exts
+}
+
+// CodecForFileType returns the codec for a file type name. An empty name
+// means Markdown with YAML front matter.
+func CodecForFileType(name string) (FrontmatterCodec, error) {
+	if name == "" {
+		return codecs[0], nil
+	}
+	for _, c := range codecs {
+		if c.FileType() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown file type %q (valid: %s)", name, strings.Join(FileTypes(), ", "))
+}
+
+// FileTypeOf returns the file type of an existing file, going by its
+// front matter and falling back to its extension
+func FileTypeOf(path string) string {
+	if content, err := os.ReadFile(path); err == nil {
+		if codec, _ := detectCodec(strings.Split(string(content), "\n")); codec != nil {
+			return codec.FileType()
+		}
+	}
+	return CodecForPath(path).FileType()
+}
+
+// CodecForPath returns the codec new front matter in a file should use,
+// going by its extension
+func CodecForPath(path string) FrontmatterCodec {
+	ext := filepath.Ext(path)
+	for _, c := range codecs {
+		if c.Extension() == ext {
+			return c
+		}
+	}
+	return codecs[0]
+}
+
+// detectCodec returns the codec whose front matter lines start with and
+// the number of header lines, or nil and 0 if there is none
+func detectCodec(lines []string) (FrontmatterCodec, int) {
+	for _, c := range codecs {
+		if n := c.HeaderLines(lines); n > 0 {
+			return c, n
+		}
+	}
+	return nil, 0
+}
+
+// FrontmatterLines returns the number of leading lines holding front
+// matter, or 0 if there is none
+func FrontmatterLines(lines []string) int {
+	_, n := detectCodec(lines)
+	return n
+}
+
+// Body returns content without its front matter
+func Body(content string) string {
+	lines := strings.Split(content, "\n")
+	return strings.Join(lines[FrontmatterLines(lines):], "\n")
+}
+
+// yamlCodec is YAML between "---" lines, as in Markdown files
+type yamlCodec struct{}
+
+func (yamlCodec) FileType() string  { return FileTypeMarkdownYAML }
+func (yamlCodec) Extension() string { return ".md" }
+
+// HeaderLines finds the closing "---" by looking for valid YAML, so "---"
+// inside a block value doesn't end the header early
+func (yamlCodec) HeaderLines(lines []string) int {
+	if len(lines) == 0 || strings.TrimRight(lines[0], "\r") != "---" {
+		return 0
+	}
+
+	var frontmatterLines []string
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if line == "---" {
+			var test map[string]interface{}
+			if err := yaml.Unmarshal([]byte(strings.Join(frontmatterLines, "\n")), &test); err == nil {
+				return i + 1
+			}
+		}
+		frontmatterLines = append(frontmatterLines, line)
+	}
+	return 0
+}
+
+func (yamlCodec) Decode(header []string) (string, error) {
+	var lines []string
+	for _, line := range header[1 : len(header)-1] {
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (c yamlCodec) Encode(metadata interface{}, original []string) (string, error) {
+	var doc yaml.Node
+	if err := doc.Encode(metadata); err != nil {
+		return "", fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	if len(original) >= 2 {
+		c.merge(&doc, original, knownFields(metadata))
+	}
+
+	var buf strings.Builder
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&doc); err != nil {
+		return "", fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return "---\n" + buf.String() + "---\n", nil
+}
+
+// merge rewrites the encoded mapping in the original header's key order.
+// Known keys take the encoded value, or are removed if it has none; other
+// keys are kept as they were. Known keys the header lacks go at the end.
+// A header that isn't a YAML mapping is left out.
+func (c yamlCodec) merge(encoded *yaml.Node, original []string, known map[string]bool) {
+	text, err := c.Decode(original)
+	if err != nil {
+		return
+	}
+	var header yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &header); err != nil || len(header.Content) == 0 {
+		return
+	}
+	mapping := header.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return
+	}
+
+	values := make(map[string]int)
+	for i := 0; i+1 < len(encoded.Content); i += 2 {
+		values[encoded.Content[i].Value] = i
+	}
+
+	var merged []*yaml.Node
+	written := make(map[string]bool)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i].Value
+		if !known[key] {
+			merged = append(merged, mapping.Content[i], mapping.Content[i+1])
+			continue
+		}
+		if j, ok := values[key]; ok && !written[key] {
+			written[key] = true
+			// Keep comments written around the field
+			k, v := encoded.Content[j], encoded.Content[j+1]
+			k.HeadComment, k.FootComment = mapping.Content[i].HeadComment, mapping.Content[i].FootComment
+			v.LineComment = mapping.Content[i+1].LineComment
+			merged = append(merged, k, v)
+		}
+	}
+	for i := 0; i+1 < len(encoded.Content); i += 2 {
+		if !written[encoded.Content[i].Value] {
+			merged = append(merged, encoded.Content[i], encoded.Content[i+1])
+		}
+	}
+	encoded.Content = merged
+	encoded.HeadComment, encoded.FootComment = mapping.HeadComment, mapping.FootComment
+}
+
+func (yamlCodec) SetField(content, field, value string) string {
+	return setYAMLField(content, field, value)
+}
+
+// lineCodec is front matter written one "key: value" line per field:
+// "#+key:" keywords in Org files, where a PROPERTIES drawer before the
+// first heading is also read, and plain "key:" lines closed by a dashed
+// line in text files. Lists are written as words and nested values as YAML
+// flow sequences.
+type lineCodec struct {
+	fileType   string
+	extension  string
+	prefix     string         // Before each key
+	separator  string         // Between list items
+	keyword    *regexp.Regexp // Matches a field line: key, value
+	closing    string         // Line ending the header; empty if a non-field line does
+	dateLayout string         // Date format of Denote's header for new files; empty for none
+	tagsKey    string         // Key of the tags line in Denote's header
+}
+
+var (
+	drawerStartPattern = regexp.MustCompile(`(?i)^\s*:PROPERTIES:\s*$`)
+	drawerEndPattern   = regexp.MustCompile(`(?i)^\s*:END:\s*$`)
+	propertyPattern    = regexp.MustCompile(`^(\s*):([\w-]+):\s*(.*?)\s*$`)
+	closingPattern     = regexp.MustCompile(`^-{3,}\s*$`)
+	headingPattern     = regexp.MustCompile(`^\*+\s`)
+)
+
+func (c *lineCodec) FileType() string  { return c.fileType }
+func (c *lineCodec) Extension() string { return c.extension }
+
+func (c *lineCodec) HeaderLines(lines []string) int {
+	i := 0
+	for i < len(lines) {
+		line := strings.TrimRight(lines[i], "\r")
+		switch {
+		case c.closing != "" && i > 0 && closingPattern.MatchString(line):
+			return i + 1
+		case c.keyword.MatchString(line):
+			i++
+		case c.closing == "" && drawerStartPattern.MatchString(line):
+			end := i + 1
+			for end < len(lines) && !drawerEndPattern.MatchString(strings.TrimRight(lines[end], "\r")) {
+				end++
+			}
+			if end == len(lines) {
+				return i
+			}
+			i = end + 1
+		default:
+			if c.closing != "" {
+				return 0
+			}
+			// The drawer may follow blank lines or text, as long as it
+			// comes before the first heading
+			drawer := c.drawerAfter(lines, i)
+			if drawer < 0 {
+				return i
+			}
+			i = drawer
+		}
+	}
+	if c.closing != "" {
+		return 0
+	}
+	return i
+}
+
+// drawerAfter returns the line of the first PROPERTIES drawer at or after
+// line start and before the first heading, or -1 if there is none
+func (c *lineCodec) drawerAfter(lines []string, start int) int {
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		switch {
+		case headingPattern.MatchString(line):
+			return -1
+		case drawerStartPattern.MatchString(line):
+			return i
+		}
+	}
+	return -1
+}
+
+// headerEntry is one field line of a header
+type headerEntry struct {
+	key      string // Lowercased
+	value    string
+	indent   string // Property lines only
+	rawKey   string // Property lines only, as written
+	property bool   // In the PROPERTIES drawer
+}
+
+// walk calls fn for every header line, with the field it holds if any
+func (c *lineCodec) walk(header []string, fn func(i int, line string, entry *headerEntry)) {
+	inDrawer := false
+	for i, line := range header {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case c.closing == "" && !inDrawer && drawerStartPattern.MatchString(line):
+			inDrawer = true
+			fn(i, line, nil)
+		case inDrawer && drawerEndPattern.MatchString(line):
+			inDrawer = false
+			fn(i, line, nil)
+		case inDrawer:
+			if m := propertyPattern.FindStringSubmatch(line); m != nil {
+				fn(i, line, &headerEntry{key: strings.ToLower(m[2]), value: m[3], indent: m[1], rawKey: m[2], property: true})
+			} else {
+				fn(i, line, nil)
+			}
+		default:
+			if m := c.keyword.FindStringSubmatch(line); m != nil && !(c.closing != "" && closingPattern.MatchString(line)) {
+				fn(i, line, &headerEntry{key: strings.ToLower(m[1]), value: m[2]})
+			} else {
+				fn(i, line, nil)
+			}
+		}
+	}
+}
+
+func (c *lineCodec) Decode(header []string) (string, error) {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	seen := make(map[string]bool)
+	var err error
+
+	c.walk(header, func(i int, line string, entry *headerEntry) {
+		if err != nil || entry == nil || entry.value == "" || seen[entry.key] {
+			return
+		}
+		seen[entry.key] = true
+
+		var value *yaml.Node
+		if value, err = c.valueNode(entry.value, metadataFieldKinds[entry.key]); err != nil {
+			err = fmt.Errorf("%s: %w", entry.key, err)
+			return
+		}
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: entry.key}, value)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// valueNode converts a field's text to the YAML value its metadata field
+// expects
+func (c *lineCodec) valueNode(text string, kind fieldKind) (*yaml.Node, error) {
+	switch kind {
+	case kindList:
+		seq := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range strings.FieldsFunc(text, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ','
+		}) {
+			seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+		}
+		return seq, nil
+	case kindInt:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: text}, nil
+	case kindStructured:
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(text), &doc); err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 {
+			return nil, fmt.Errorf("empty value")
+		}
+		return doc.Content[0], nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: text}, nil
+	}
+}
+
+// valueText renders a YAML value as field text
+func (c *lineCodec) valueText(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, nil
+	case yaml.SequenceNode:
+		var items []string
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				items = nil
+				break
+			}
+			items = append(items, item.Value)
+		}
+		if items != nil {
+			return strings.Join(items, c.separator), nil
+		}
+	}
+
+	setFlowStyle(node)
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// setFlowStyle makes a YAML value render on one line
+func setFlowStyle(node *yaml.Node) {
+	node.Style |= yaml.FlowStyle
+	for _, child := range node.Content {
+		setFlowStyle(child)
+	}
+}
+
+func (c *lineCodec) Encode(metadata interface{}, original []string) (string, error) {
+	values, order, err := metadataValues(metadata, c.valueText)
+	if err != nil {
+		return "", err
+	}
+	return c.rewrite(original, values, order, knownFields(metadata)), nil
+}
+
+func (c *lineCodec) SetField(content, field, value string) string {
+	lines := strings.Split(content, "\n")
+	n := c.HeaderLines(lines)
+	header := c.rewrite(lines[:n], map[string]string{field: value}, []string{field}, map[string]bool{field: true})
+	if n == 0 {
+		return header + "\n" + content
+	}
+	return header + strings.Join(lines[n:], "\n")
+}
+
+// rewrite updates the fields in known to values, in place where the
+// header has them and after its last keyword otherwise. Known fields
+// without a value are removed. Other lines are kept as they are.
+func (c *lineCodec) rewrite(header []string, values map[string]string, order []string, known map[string]bool) string {
+	closing := c.closing
+	if c.closing != "" && len(header) > 0 {
+		closing = strings.TrimRight(header[len(header)-1], "\r")
+		header = header[:len(header)-1]
+	}
+
+	var out []string
+	insertAt := -1
+	written := make(map[string]bool)
+	c.walk(header, func(i int, line string, entry *headerEntry) {
+		switch {
+		case entry == nil || !known[entry.key]:
+			out = append(out, line)
+		case !written[entry.key] && values[entry.key] != "":
+			written[entry.key] = true
+			if entry.property {
+				out = append(out, fmt.Sprintf("%s:%s: %s", entry.indent, entry.rawKey, values[entry.key]))
+			} else {
+				out = append(out, c.formatLine(entry.key, values[entry.key]))
+			}
+		}
+		if entry != nil && !entry.property {
+			insertAt = len(out)
+		}
+	})
+
+	var added []string
+	for _, key := range order {
+		if !written[key] && values[key] != "" {
+			added = append(added, c.formatLine(key, values[key]))
+		}
+	}
+	if insertAt < 0 || c.closing != "" {
+		insertAt = len(out)
+	}
+	out = append(out[:insertAt], append(added, out[insertAt:]...)...)
+
+	if closing != "" {
+		out = append(out, closing)
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// denoteHeader returns the lines Denote starts a new file with, for a
+// codec that writes them: the title, filled in from the metadata, then the
+// date, tags and identifier taken from the file's name
+func (c *lineCodec) denoteHeader(file *File) []string {
+	if c.dateLayout == "" {
+		return nil
+	}
+	lines := []string{c.formatLine("title", "")}
+	if created, err := time.ParseInLocation("20060102T150405", file.ID, time.Local); err == nil {
+		lines = append(lines, c.formatLine("date", created.Format(c.dateLayout)))
+	}
+	if len(file.Tags) > 0 {
+		tags := strings.Join(file.Tags, c.separator)
+		if c.tagsKey == "filetags" {
+			tags = ":" + strings.Join(file.Tags, ":") + ":"
+		}
+		lines = append(lines, c.formatLine(c.tagsKey, tags))
+	}
+	lines = append(lines, c.formatLine("identifier", file.ID))
+	if c.closing != "" {
+		lines = append(lines, c.closing)
+	}
+	return lines
+}
+
+// formatLine renders a field, aligning values the way Denote does
+func (c *lineCodec) formatLine(key, value string) string {
+	return c.prefix + fmt.Sprintf("%-11s %s", key+":", value)
+}
+
+// metadataValues renders the fields metadata sets with render, returning
+// them by key and in struct order
+func metadataValues(metadata interface{}, render func(*yaml.Node) (string, error)) (map[string]string, []string, error) {
+	var doc yaml.Node
+	if err := doc.Encode(metadata); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	values := make(map[string]string)
+	var order []string
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key := doc.Content[i].Value
+		text, err := render(doc.Content[i+1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode %s: %w", key, err)
+		}
+		values[key] = text
+		order = append(order, key)
+	}
+	return values, order, nil
+}
+
+// knownFields returns the front matter keys metadata's type defines, set
+// or not
+func knownFields(metadata interface{}) map[string]bool {
+	known := make(map[string]bool)
+	for _, f := range yamlFields(reflect.TypeOf(metadata)) {
+		known[f.name] = true
+	}
+	return known
+}
+
+// fieldKind is the shape of a metadata field's value
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindInt
+	kindList       // List of strings
+	kindStructured // Anything else, such as time_log
+)
+
+// yamlField is a metadata struct field by its front matter key
+type yamlField struct {
+	name string
+	kind fieldKind
+}
+
+// yamlFields lists the front matter fields of a metadata type
+func yamlFields(t reflect.Type) []yamlField {
+	var fields []yamlField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		kind := kindString
+		switch f.Type.Kind() {
+		case reflect.Int:
+			kind = kindInt
+		case reflect.Slice:
+			kind = kindStructured
+			if f.Type.Elem().Kind() == reflect.String {
+				kind = kindList
+			}
+		case reflect.String:
+		default:
+			kind = kindStructured
+		}
+		fields = append(fields, yamlField{name: name, kind: kind})
+	}
+	return fields
+}
+
+// metadataFieldKinds maps the front matter keys of all metadata types to
+// the kind of value they hold
+var metadataFieldKinds = func() map[string]fieldKind {
+	kinds := make(map[string]fieldKind)
+	for _, v := range []interface{}{NoteMetadata{}, TaskMetadata{}, ProjectMetadata{}} {
+		for _, f := range yamlFields(reflect.TypeOf(v)) {
+			kinds[f.name] = f.kind
+		}
+	}
+	return kinds
+}()
@@ -0,0 +1,61 @@
+package denote
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestYAMLEncodeKeepsUnknownFields(t *testing.T) {
+	content := "---\ntitle: Write report\n# Set by hand\ncustom_key: keepme\nindex_id: 6\ntype: task\nstatus: open # still to do\npriority: p1\n---\n\nBody\n"
+	fm, err := ParseFrontmatterFile([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta := fm.Metadata.(TaskMetadata)
+	meta.Priority = "p2"
+	meta.Area = "work"
+	out, err := fm.Encode(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "---\ntitle: Write report\n# Set by hand\ncustom_key: keepme\nindex_id: 6\ntype: task\nstatus: open # still to do\npriority: p2\narea: work\n---\n\nBody\n"
+	if string(out) != want {
+		t.Errorf("encoded file:\n%s\nwant:\n%s", out, want)
+	}
+
+	// Clearing a field removes it rather than keeping the old value
+	meta.Priority = ""
+	out, err = fm.Encode(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "priority") {
+		t.Errorf("cleared priority was kept:\n%s", out)
+	}
+}
+
+func TestWriteNewTextFileHasDenoteHeader(t *testing.T) {
+	codec, err := CodecForFileType(FileTypeText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := TaskMetadata{Title: "Write report", IndexID: 3, Type: TypeTask, Status: TaskStatusOpen}
+	out, err := WriteNewFileAs(codec, "/notes/20250705T093000--write-report__task_work.txt", meta, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "title:      Write report\n" +
+		"date:       2025-07-05\n" +
+		"tags:       task  work\n" +
+		"identifier: 20250705T093000\n" +
+		"index_id:   3\n" +
+		"type:       task\n" +
+		"status:     open\n" +
+		"---------------------------\n"
+	if !strings.HasPrefix(string(out), want) {
+		t.Errorf("new text file:\n%s\nwant it to start with:\n%s", out, want)
+	}
+}
@@ -0,0 +1,229 @@
+package denote
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileTypeMarkdownTOML is Markdown with TOML front matter between "+++"
+// lines
+const FileTypeMarkdownTOML = "markdown-toml"
+
+var (
+	tomlKeyPattern   = regexp.MustCompile(`^\s*([A-Za-z0-9_-]+)\s*=`)
+	tomlTablePattern = regexp.MustCompile(`^\s*\[\[?\s*([A-Za-z0-9_-]+)`)
+)
+
+// tomlCodec is TOML between "+++" lines, as Denote writes Markdown notes
+// with its markdown-toml file type
+type tomlCodec struct{}
+
+func (tomlCodec) FileType() string  { return FileTypeMarkdownTOML }
+func (tomlCodec) Extension() string { return ".md" }
+
+func (tomlCodec) HeaderLines(lines []string) int {
+	if len(lines) == 0 || strings.TrimRight(lines[0], "\r") != "+++" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\r") == "+++" {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (tomlCodec) Decode(header []string) (string, error) {
+	var fields map[string]interface{}
+	if _, err := toml.Decode(strings.Join(header[1:len(header)-1], "\n"), &fields); err != nil {
+		return "", err
+	}
+
+	out, err := yaml.Marshal(tomlToYAML(fields))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// tomlToYAML turns TOML dates into the strings the metadata fields hold:
+// YYYY-MM-DD for a bare date, RFC 3339 otherwise
+func tomlToYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		if v.Location().String() == "date-local" {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = tomlToYAML(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = tomlToYAML(item)
+		}
+	case []map[string]interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = tomlToYAML(item)
+		}
+		return items
+	}
+	return value
+}
+
+func (c tomlCodec) Encode(metadata interface{}, original []string) (string, error) {
+	values, order, err := metadataValues(metadata, tomlValue)
+	if err != nil {
+		return "", err
+	}
+	return c.rewrite(original, values, order, knownFields(metadata)), nil
+}
+
+func (c tomlCodec) SetField(content, field, value string) string {
+	lines := strings.Split(content, "\n")
+	n := c.HeaderLines(lines)
+
+	text := ""
+	if value != "" {
+		switch metadataFieldKinds[field] {
+		case kindInt:
+			if _, err := strconv.Atoi(value); err == nil {
+				text = value
+			} else {
+				text = tomlString(value)
+			}
+		case kindList:
+			var items []string
+			for _, item := range strings.FieldsFunc(value, func(r rune) bool {
+				return r == ' ' || r == '\t' || r == ','
+			}) {
+				items = append(items, tomlString(item))
+			}
+			text = "[" + strings.Join(items, ", ") + "]"
+		default:
+			text = tomlString(value)
+		}
+	}
+
+	header := c.rewrite(lines[:n], map[string]string{field: text}, []string{field}, map[string]bool{field: true})
+	if n == 0 {
+		return header + "\n" + content
+	}
+	return header + strings.Join(lines[n:], "\n")
+}
+
+// rewrite updates the top-level keys in known to values, in place where
+// the header has them and after the last top-level key otherwise. Known
+// keys without a value are removed, as are tables named after them, since
+// their values are written inline. Everything else is kept.
+func (tomlCodec) rewrite(header []string, values map[string]string, order []string, known map[string]bool) string {
+	if len(header) >= 2 {
+		header = header[1 : len(header)-1]
+	}
+
+	var out []string
+	written := make(map[string]bool)
+	keep := true // Whether the current key or table is kept
+	inTable := false
+	insertAt := 0
+	for _, line := range header {
+		line = strings.TrimRight(line, "\r")
+		if m := tomlTablePattern.FindStringSubmatch(line); m != nil {
+			inTable = true
+			keep = !known[m[1]]
+		} else if m := tomlKeyPattern.FindStringSubmatch(line); m != nil && !inTable {
+			key := m[1]
+			keep = true
+			if known[key] {
+				keep = false
+				if !written[key] && values[key] != "" {
+					written[key] = true
+					out = append(out, fmt.Sprintf("%-10s = %s", key, values[key]))
+				}
+			}
+		}
+		if keep {
+			out = append(out, line)
+		}
+		if !inTable {
+			insertAt = len(out)
+		}
+	}
+
+	var added []string
+	for _, key := range order {
+		if !written[key] && values[key] != "" {
+			added = append(added, fmt.Sprintf("%-10s = %s", key, values[key]))
+		}
+	}
+	out = append(out[:insertAt], append(added, out[insertAt:]...)...)
+
+	return "+++\n" + strings.Join(append(out, "+++"), "\n") + "\n"
+}
+
+// tomlValue renders a YAML value as an inline TOML value
+func tomlValue(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!int", "!!float", "!!bool":
+			return node.Value, nil
+		case "!!null":
+			return "", nil
+		default:
+			return tomlString(node.Value), nil
+		}
+	case yaml.SequenceNode:
+		var items []string
+		for _, item := range node.Content {
+			text, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, text)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case yaml.MappingNode:
+		var pairs []string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			text, err := tomlValue(node.Content[i+1])
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, node.Content[i].Value+" = "+text)
+		}
+		return "{" + strings.Join(pairs, ", ") + "}", nil
+	}
+	return "", fmt.Errorf("unsupported value")
+}
+
+// tomlString quotes a TOML basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -49,7 +49,7 @@ func CreateNote(directory, title string, tags []string) (string, error) {
 	}
 	
 	// Write file
-	if err := os.WriteFile(filepath, content, 0644); err != nil {
+	if err := WriteFileAtomic(filepath, content, 0644); err != nil {
 		return "", fmt.Errorf("failed to create note: %w", err)
 	}
 	
@@ -80,8 +80,9 @@ func titleToSlug(title string) string {
 	return slug
 }
 
-// BuildDenoteFilename builds a Denote filename from components
-func BuildDenoteFilename(id, slug string, tags []string) string {
+// BuildDenoteFilename builds a Denote filename from components. ext
+// includes the dot.
+func BuildDenoteFilename(id, slug string, tags []string, ext string) string {
 	// Join tags with underscores
 	tagString := ""
 	if len(tags) > 0 {
@@ -89,7 +90,7 @@ func BuildDenoteFilename(id, slug string, tags []string) string {
 	}
 	
 	// Build filename
-	return fmt.Sprintf("%s--%s%s.md", id, slug, tagString)
+	return fmt.Sprintf("%s--%s%s%s", id, slug, tagString, ext)
 }
 
 // RenameFileForTags renames a Denote file to reflect new tags
@@ -99,7 +100,8 @@ func RenameFileForTags(oldPath string, newTags []string) (string, error) {
 	base := filepath.Base(oldPath)
 	
 	// Extract ID and slug from filename
-	// Format: ID--slug__tag1_tag2.md
+	// Format: ID--slug__tag1_tag2.ext
+	ext := filepath.Ext(base)
 	parts := strings.SplitN(base, "--", 2)
 	if len(parts) != 2 {
 		return "", fmt.Errorf("invalid Denote filename format")
@@ -108,12 +110,12 @@ func RenameFileForTags(oldPath string, newTags []string) (string, error) {
 	id := parts[0]
 	
 	// Extract slug (before tags)
-	remainingPart := strings.TrimSuffix(parts[1], ".md")
+	remainingPart := strings.TrimSuffix(parts[1], ext)
 	slugParts := strings.SplitN(remainingPart, "__", 2)
 	slug := slugParts[0]
 	
 	// Build new filename
-	newFilename := BuildDenoteFilename(id, slug, newTags)
+	newFilename := BuildDenoteFilename(id, slug, newTags, ext)
 	newPath := filepath.Join(dir, newFilename)
 	
 	// Don't rename if the path hasn't changed
@@ -121,13 +123,21 @@ func RenameFileForTags(oldPath string, newTags []string) (string, error) {
 		return oldPath, nil
 	}
 	
+	// Hold the directory lock so the target can't appear between the
+	// existence check and the rename
+	lock, err := LockDir(dir)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Unlock()
+	
 	// Check if target already exists
 	if _, err := os.Stat(newPath); err == nil {
 		return "", fmt.Errorf("target file already exists: %s", newPath)
 	}
 	
 	// Rename the file
-	if err := os.Rename(oldPath, newPath); err != nil {
+	if err := RenameFile(oldPath, newPath); err != nil {
 		return "", fmt.Errorf("failed to rename file: %w", err)
 	}
 	
@@ -0,0 +1,133 @@
+package denote
+
+// ResolveDependencies computes BlockedBy for each task from its depends_on
+// list and returns any dependency cycles found. A dependency blocks until it
+// is done or dropped; references to unknown tasks are ignored.
+func ResolveDependencies(tasks []*Task) [][]string {
+	byID := make(map[string]*Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.File.ID] = t
+	}
+
+	for _, t := range tasks {
+		t.BlockedBy = nil
+		for _, depID := range t.DependsOn {
+			dep, ok := byID[depID]
+			if !ok || dep == t {
+				continue
+			}
+			if !isFinishedStatus(dep.Status) {
+				t.BlockedBy = append(t.BlockedBy, depID)
+			}
+		}
+	}
+
+	return findDependencyCycles(tasks, byID)
+}
+
+// IsBlocked returns true if the task has unfinished dependencies
+func (t *Task) IsBlocked() bool {
+	return len(t.BlockedBy) > 0
+}
+
+// FindDependents returns the tasks that depend on the given Denote ID
+func FindDependents(tasks []*Task, denoteID string) []*Task {
+	var dependents []*Task
+	for _, t := range tasks {
+		for _, depID := range t.DependsOn {
+			if depID == denoteID {
+				dependents = append(dependents, t)
+				break
+			}
+		}
+	}
+	return dependents
+}
+
+// WouldCreateCycle checks whether making task depend on depID would
+// introduce a dependency cycle
+func WouldCreateCycle(tasks []*Task, taskID, depID string) bool {
+	if taskID == depID {
+		return true
+	}
+
+	byID := make(map[string]*Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.File.ID] = t
+	}
+
+	// Walk from the new dependency; reaching the task closes a loop
+	visited := make(map[string]bool)
+	stack := []string{depID}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == taskID {
+			return true
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		if t, ok := byID[id]; ok {
+			stack = append(stack, t.DependsOn...)
+		}
+	}
+
+	return false
+}
+
+// findDependencyCycles returns each cycle as a list of Denote IDs, with the
+// first ID repeated at the end
+func findDependencyCycles(tasks []*Task, byID map[string]*Task) [][]string {
+	const (
+		unvisited = iota
+		inProgress
+		visited
+	)
+
+	state := make(map[string]int, len(tasks))
+	var path []string
+	var cycles [][]string
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = inProgress
+		path = append(path, id)
+
+		for _, depID := range byID[id].DependsOn {
+			if _, ok := byID[depID]; !ok {
+				continue
+			}
+			switch state[depID] {
+			case unvisited:
+				visit(depID)
+			case inProgress:
+				// Found a back edge: extract the loop from the current path
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == depID {
+						cycle := append([]string{}, path[i:]...)
+						cycles = append(cycles, append(cycle, depID))
+						break
+					}
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[id] = visited
+	}
+
+	for _, t := range tasks {
+		if state[t.File.ID] == unvisited {
+			visit(t.File.ID)
+		}
+	}
+
+	return cycles
+}
+
+// isFinishedStatus returns true for statuses that no longer block dependents
+func isFinishedStatus(status string) bool {
+	return status == TaskStatusDone || status == TaskStatusDropped
+}
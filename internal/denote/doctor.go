@@ -0,0 +1,536 @@
+package denote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Problem kinds reported by Diagnose, in the order fixes are applied
+const (
+	ProblemCounterBehind   = "counter-behind"
+	ProblemUnreadable      = "unreadable"
+	ProblemFrontmatter     = "frontmatter"
+	ProblemMissingID       = "missing-id"
+	ProblemDuplicateID     = "duplicate-id"
+	ProblemDanglingProject = "dangling-project"
+	ProblemInvalidStatus   = "invalid-status"
+	ProblemInvalidPriority = "invalid-priority"
+	ProblemInvalidEstimate = "invalid-estimate"
+	ProblemInvalidDate     = "invalid-date"
+	ProblemTagMismatch     = "tag-mismatch"
+)
+
+// problemOrder sorts problems so fixes that rename files run last
+var problemOrder = map[string]int{
+	ProblemCounterBehind:   0,
+	ProblemUnreadable:      1,
+	ProblemFrontmatter:     2,
+	ProblemMissingID:       3,
+	ProblemDuplicateID:     4,
+	ProblemDanglingProject: 5,
+	ProblemInvalidStatus:   6,
+	ProblemInvalidPriority: 7,
+	ProblemInvalidEstimate: 8,
+	ProblemInvalidDate:     9,
+	ProblemTagMismatch:     10,
+}
+
+// dateRepairLayouts are unambiguous date formats that can be normalized to
+// YYYY-MM-DD without guessing
+var dateRepairLayouts = []string{
+	"2006-1-2",
+	"2006/1/2",
+	"2006.1.2",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+}
+
+// Problem is an inconsistency found in the notes directory
+type Problem struct {
+	Kind    string // One of the Problem* constants
+	Path    string // File with the problem (the counter file for counter-behind)
+	Message string // What is wrong
+	Action  string // What Fix will do; empty if it must be fixed by hand
+
+	fix func() error
+}
+
+// Fixable returns true if the problem can be repaired automatically
+func (p *Problem) Fixable() bool {
+	return p.fix != nil
+}
+
+// Fix repairs the problem
+func (p *Problem) Fix() error {
+	if p.fix == nil {
+		return fmt.Errorf("%s must be fixed by hand", p.Kind)
+	}
+	return p.fix()
+}
+
+// DoctorReport is the result of checking a notes directory
+type DoctorReport struct {
+	Checked  int // Task and project files examined
+	Problems []*Problem
+}
+
+// Fixable returns the number of problems that can be repaired automatically
+func (r *DoctorReport) Fixable() int {
+	count := 0
+	for _, p := range r.Problems {
+		if p.Fixable() {
+			count++
+		}
+	}
+	return count
+}
+
+// Diagnose checks every task and project file in dir for duplicate or
+// missing index IDs, dangling project references, frontmatter tags missing
+// from the filename, invalid field values, missing frontmatter and a
+// stale ID counter. Nothing is modified until a problem's Fix is called.
+func Diagnose(dir string) (*DoctorReport, error) {
+	scanner := &Scanner{BaseDir: dir, NoIndex: true}
+	result, err := scanner.Scan()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DoctorReport{Checked: len(result.Files) + countSkipped(result.Errors)}
+
+	// Files with broken frontmatter were loaded with empty metadata, so the
+	// field checks below would only report noise for them
+	broken := make(map[string]bool)
+	brokenMaxID := 0
+	for _, scanErr := range result.Errors {
+		broken[scanErr.Path] = true
+		report.Problems = append(report.Problems, frontmatterProblem(dir, scanErr))
+		if id := rawIndexID(scanErr.Path); id > brokenMaxID {
+			brokenMaxID = id
+		}
+	}
+
+	projectIDs := make(map[string]bool)
+	for _, p := range result.Projects {
+		projectIDs[p.File.ID] = true
+	}
+
+	// Collect index IDs across tasks and projects, which share one sequence
+	var owners []indexOwner
+	for _, t := range result.Tasks {
+		if !broken[t.File.Path] {
+			owners = append(owners, indexOwner{file: t.File, indexID: t.IndexID})
+		}
+	}
+	for _, p := range result.Projects {
+		if !broken[p.File.Path] {
+			owners = append(owners, indexOwner{file: p.File, indexID: p.IndexID})
+		}
+	}
+	report.Problems = append(report.Problems, indexIDProblems(dir, owners, brokenMaxID)...)
+
+	for _, t := range result.Tasks {
+		if broken[t.File.Path] {
+			continue
+		}
+		report.Problems = append(report.Problems, taskProblems(t, projectIDs)...)
+	}
+	for _, p := range result.Projects {
+		if broken[p.File.Path] {
+			continue
+		}
+		report.Problems = append(report.Problems, projectProblems(p)...)
+	}
+
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		return problemOrder[report.Problems[i].Kind] < problemOrder[report.Problems[j].Kind]
+	})
+
+	return report, nil
+}
+
+// indexOwner is a file holding an index ID
+type indexOwner struct {
+	file    File
+	indexID int
+}
+
+// countSkipped counts scan errors for files left out of the results
+func countSkipped(errs []ScanError) int {
+	count := 0
+	for _, e := range errs {
+		if e.Skipped {
+			count++
+		}
+	}
+	return count
+}
+
+// frontmatterProblem describes a file whose frontmatter could not be used
+func frontmatterProblem(dir string, scanErr ScanError) *Problem {
+	path := scanErr.Path
+	problem := &Problem{
+		Kind:    ProblemFrontmatter,
+		Path:    path,
+		Message: scanErr.Err.Error(),
+	}
+
+	if scanErr.Skipped {
+		problem.Kind = ProblemUnreadable
+		return problem
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		problem.Kind = ProblemUnreadable
+		problem.Message = err.Error()
+		return problem
+	}
+
+	file, err := NewParser().ParseFilename(path)
+	if err != nil {
+		return problem
+	}
+	fileType := TypeTask
+	if file.IsProject() && !file.IsTask() {
+		fileType = TypeProject
+	}
+
+	if !strings.HasPrefix(string(content), "---") && FrontmatterLines(strings.Split(string(content), "\n")) == 0 {
+		problem.Message = "missing frontmatter"
+		problem.Action = fmt.Sprintf("generate %s frontmatter from the filename", fileType)
+		problem.fix = func() error {
+			return generateFrontmatter(dir, path, file, fileType)
+		}
+		return problem
+	}
+
+	if _, err := ParseFrontmatterFile(content); err != nil {
+		// Unparseable YAML needs a human to decide what was meant
+		return problem
+	}
+
+	// The frontmatter parsed as the wrong kind of metadata
+	problem.Action = fmt.Sprintf("set type to %s", fileType)
+	problem.fix = func() error {
+		return setFrontmatterField(path, "type", fileType)
+	}
+	return problem
+}
+
+// indexIDProblems reports missing and duplicate index IDs and a stale
+// counter. The file with the oldest Denote ID keeps a shared index ID; the
+// others are renumbered. brokenMaxID is the highest index ID held by files
+// with frontmatter problems, which the counter must also stay ahead of.
+func indexIDProblems(dir string, owners []indexOwner, brokenMaxID int) []*Problem {
+	var problems []*Problem
+
+	byIndexID := make(map[int][]indexOwner)
+	for _, owner := range owners {
+		if owner.indexID <= 0 {
+			path := owner.file.Path
+			problems = append(problems, &Problem{
+				Kind:    ProblemMissingID,
+				Path:    path,
+				Message: "missing index_id",
+				Action:  "assign the next index_id",
+				fix: func() error {
+					return assignIndexID(dir, path)
+				},
+			})
+			continue
+		}
+		byIndexID[owner.indexID] = append(byIndexID[owner.indexID], owner)
+	}
+
+	ids := make([]int, 0, len(byIndexID))
+	for id := range byIndexID {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		group := byIndexID[id]
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].file.ID < group[j].file.ID
+		})
+		keeper := filepath.Base(group[0].file.Path)
+		for _, owner := range group[1:] {
+			path := owner.file.Path
+			problems = append(problems, &Problem{
+				Kind:    ProblemDuplicateID,
+				Path:    path,
+				Message: fmt.Sprintf("index_id %d is also used by %s", id, keeper),
+				Action:  "assign the next index_id",
+				fix: func() error {
+					return assignIndexID(dir, path)
+				},
+			})
+		}
+	}
+
+	// The counter must be ahead of every index ID in use
+	maxID := brokenMaxID
+	for _, owner := range owners {
+		if owner.indexID > maxID {
+			maxID = owner.indexID
+		}
+	}
+	if next := readNextIndexID(dir); next > 0 && next <= maxID {
+		problems = append(problems, &Problem{
+			Kind:    ProblemCounterBehind,
+			Path:    filepath.Join(dir, counterFileName),
+			Message: fmt.Sprintf("next_index_id is %d but index_id %d is in use", next, maxID),
+			Action:  fmt.Sprintf("set next_index_id to %d", maxID+1),
+			fix: func() error {
+				counter, err := GetIDCounter(dir)
+				if err != nil {
+					return fmt.Errorf("failed to get ID counter: %w", err)
+				}
+				return counter.EnsureAbove(maxID)
+			},
+		})
+	}
+
+	return problems
+}
+
+// rawIndexID returns the index ID in a file's frontmatter regardless of
+// whether the metadata type matches the filename, or 0 if there is none
+func rawIndexID(path string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	fm, err := ParseFrontmatterFile(content)
+	if err != nil {
+		return 0
+	}
+	switch meta := fm.Metadata.(type) {
+	case TaskMetadata:
+		return meta.IndexID
+	case ProjectMetadata:
+		return meta.IndexID
+	}
+	return 0
+}
+
+// taskProblems checks a task's field values, project reference and tags
+func taskProblems(t *Task, projectIDs map[string]bool) []*Problem {
+	var problems []*Problem
+	path := t.File.Path
+
+	if !IsValidTaskStatus(t.Status) {
+		problems = append(problems, fieldProblem(ProblemInvalidStatus, path, "status", t.Status, TaskStatusOpen))
+	}
+	if t.Priority != "" && !IsValidPriority(t.Priority) {
+		problems = append(problems, fieldProblem(ProblemInvalidPriority, path, "priority", t.Priority, ""))
+	}
+	if t.Estimate != 0 && !IsValidEstimate(t.Estimate) {
+		problems = append(problems, fieldProblem(ProblemInvalidEstimate, path, "estimate", strconv.Itoa(t.Estimate), ""))
+	}
+	problems = append(problems, dateProblems(path, t.DueDate, t.StartDate)...)
+
+	if t.ProjectID != "" && !projectIDs[t.ProjectID] {
+		problems = append(problems, &Problem{
+			Kind:    ProblemDanglingProject,
+			Path:    path,
+			Message: fmt.Sprintf("project_id %s does not match any project", t.ProjectID),
+			Action:  "clear project_id",
+			fix: func() error {
+				return setFrontmatterField(path, "project_id", "")
+			},
+		})
+	}
+
+	if p := tagProblem(t.File, TypeTask, t.TaskMetadata.Tags); p != nil {
+		problems = append(problems, p)
+	}
+
+	return problems
+}
+
+// projectProblems checks a project's field values and tags
+func projectProblems(p *Project) []*Problem {
+	var problems []*Problem
+	path := p.File.Path
+
+	if !IsValidProjectStatus(p.Status) {
+		problems = append(problems, fieldProblem(ProblemInvalidStatus, path, "status", p.Status, ProjectStatusActive))
+	}
+	if p.Priority != "" && !IsValidPriority(p.Priority) {
+		problems = append(problems, fieldProblem(ProblemInvalidPriority, path, "priority", p.Priority, ""))
+	}
+	problems = append(problems, dateProblems(path, p.DueDate, p.StartDate)...)
+
+	if tp := tagProblem(p.File, TypeProject, p.ProjectMetadata.Tags); tp != nil {
+		problems = append(problems, tp)
+	}
+
+	return problems
+}
+
+// fieldProblem reports an invalid value that is fixed by replacing it with
+// a default (or clearing it when the default is empty)
+func fieldProblem(kind, path, field, value, replacement string) *Problem {
+	action := fmt.Sprintf("clear %s", field)
+	if replacement != "" {
+		action = fmt.Sprintf("set %s to %s", field, replacement)
+	}
+	return &Problem{
+		Kind:    kind,
+		Path:    path,
+		Message: fmt.Sprintf("invalid %s: %q", field, value),
+		Action:  action,
+		fix: func() error {
+			return setFrontmatterField(path, field, replacement)
+		},
+	}
+}
+
+// dateProblems reports due and start dates that are not YYYY-MM-DD
+func dateProblems(path, dueDate, startDate string) []*Problem {
+	var problems []*Problem
+	for _, field := range []struct{ name, value string }{
+		{"due_date", dueDate},
+		{"start_date", startDate},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", field.value); err == nil {
+			continue
+		}
+		normalized := normalizeDate(field.value)
+		problems = append(problems, fieldProblem(ProblemInvalidDate, path, field.name, field.value, normalized))
+		problems[len(problems)-1].Message = fmt.Sprintf("malformed %s: %q", field.name, field.value)
+	}
+	return problems
+}
+
+// normalizeDate converts an unambiguous date to YYYY-MM-DD, returning ""
+// if the value can't be interpreted without guessing
+func normalizeDate(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateRepairLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+// tagProblem reports frontmatter tags missing from the filename. Frontmatter
+// tags are additional to the filename's, so fewer or none is fine. The type
+// tag ("task" or "project") is ignored since it lives in the filename. The
+// fix adds the missing tags to the filename.
+func tagProblem(file File, fileType string, metaTags []string) *Problem {
+	fileTags := withoutTag(file.Tags, fileType)
+	var missing []string
+	for _, tag := range withoutTag(metaTags, fileType) {
+		if !contains(fileTags, tag) && !contains(missing, tag) {
+			missing = append(missing, tag)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	merged := append(append([]string{fileType}, fileTags...), missing...)
+	path := file.Path
+	return &Problem{
+		Kind:    ProblemTagMismatch,
+		Path:    path,
+		Message: fmt.Sprintf("frontmatter tags [%s] missing from filename tags [%s]", strings.Join(missing, " "), strings.Join(fileTags, " ")),
+		Action:  fmt.Sprintf("add [%s] to the filename", strings.Join(missing, " ")),
+		fix: func() error {
+			_, err := RenameFileForTags(path, merged)
+			return err
+		},
+	}
+}
+
+// withoutTag returns tags with one tag removed
+func withoutTag(tags []string, remove string) []string {
+	var result []string
+	for _, tag := range tags {
+		if tag != remove && tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// setFrontmatterField sets (or clears) a single frontmatter field in place,
+// leaving the rest of the file untouched
+func setFrontmatterField(path, field, value string) error {
+	return RewriteFile(path, func(content string) (string, error) {
+		return updateFrontmatterField(content, field, value), nil
+	})
+}
+
+// assignIndexID gives a file the next index ID from the counter
+func assignIndexID(dir, path string) error {
+	counter, err := GetIDCounter(dir)
+	if err != nil {
+		return fmt.Errorf("failed to get ID counter: %w", err)
+	}
+	indexID, err := counter.NextIndexID()
+	if err != nil {
+		return fmt.Errorf("failed to get next index ID: %w", err)
+	}
+	return setFrontmatterField(path, "index_id", strconv.Itoa(indexID))
+}
+
+// generateFrontmatter adds frontmatter built from the filename to a file
+// that has none, keeping the existing text as the body
+func generateFrontmatter(dir, path string, file *File, fileType string) error {
+	counter, err := GetIDCounter(dir)
+	if err != nil {
+		return fmt.Errorf("failed to get ID counter: %w", err)
+	}
+	indexID, err := counter.NextIndexID()
+	if err != nil {
+		return fmt.Errorf("failed to get next index ID: %w", err)
+	}
+
+	return RewriteFile(path, func(content string) (string, error) {
+		var metadata interface{}
+		if fileType == TypeProject {
+			metadata = ProjectMetadata{
+				Title:   file.Title,
+				IndexID: indexID,
+				Type:    TypeProject,
+				Status:  ProjectStatusActive,
+				Tags:    file.Tags,
+			}
+		} else {
+			metadata = TaskMetadata{
+				Title:   file.Title,
+				IndexID: indexID,
+				Type:    TypeTask,
+				Status:  TaskStatusOpen,
+				Tags:    file.Tags,
+			}
+		}
+
+		newContent, err := WriteNewFileAs(CodecForPath(path), path, metadata, strings.TrimLeft(content, "\n"))
+		if err != nil {
+			return "", fmt.Errorf("failed to write frontmatter: %w", err)
+		}
+		return string(newContent), nil
+	})
+}
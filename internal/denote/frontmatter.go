@@ -1,62 +1,63 @@
 package denote
 
 import (
-	"bytes"
 	"fmt"
-	"os"
+	"regexp"
 	"strings"
 	"time"
 	
 	"gopkg.in/yaml.v3"
 )
 
-// FrontmatterFile represents a file with YAML frontmatter
+// FrontmatterFile represents a file with frontmatter
 type FrontmatterFile struct {
-	Metadata interface{} // Can be NoteMetadata, TaskMetadata, or ProjectMetadata
-	Content  string      // The markdown content after frontmatter
+	Metadata interface{}      // Can be NoteMetadata, TaskMetadata, or ProjectMetadata
+	Content  string           // The body after frontmatter
+	Codec    FrontmatterCodec // The frontmatter syntax the file uses
+
+	header []string // Frontmatter lines as read, for fields Encode keeps
 }
 
-// ParseFrontmatterFile reads and validates a file with YAML frontmatter
+// ParseFrontmatterFile reads and validates a file with YAML, Org or text
+// frontmatter
 func ParseFrontmatterFile(content []byte) (*FrontmatterFile, error) {
 	contentStr := string(content)
-	
-	// Must start with ---
-	if !strings.HasPrefix(contentStr, "---\n") && !strings.HasPrefix(contentStr, "---\r\n") {
-		return nil, fmt.Errorf("file does not start with YAML frontmatter marker")
-	}
-	
-	// Find the closing --- by looking for valid YAML
 	lines := strings.Split(contentStr, "\n")
-	var frontmatterLines []string
-	endIndex := -1
 	
-	for i := 1; i < len(lines); i++ {
-		line := strings.TrimRight(lines[i], "\r")
-		
-		if line == "---" {
-			// Try to parse what we have so far as YAML
-			testYAML := strings.Join(frontmatterLines, "\n")
-			var test map[string]interface{}
-			
-			if err := yaml.Unmarshal([]byte(testYAML), &test); err == nil {
-				// It's valid YAML, so this is the end marker
-				endIndex = i
-				break
-			}
-			// Not valid YAML yet, keep going
+	codec, headerLines := detectCodec(lines)
+	if codec == nil {
+		if strings.HasPrefix(contentStr, "---") {
+			return nil, fmt.Errorf("no valid YAML frontmatter found")
 		}
-		
-		frontmatterLines = append(frontmatterLines, line)
+		return nil, fmt.Errorf("file does not start with frontmatter")
+	}
+	
+	frontmatterStr, err := codec.Decode(lines[:headerLines])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
 	}
 	
-	if endIndex == -1 {
-		return nil, fmt.Errorf("no valid YAML frontmatter found")
+	// The body starts after the blank line that follows the frontmatter
+	contentLines := lines[headerLines:]
+	if len(contentLines) > 1 && strings.TrimRight(contentLines[0], "\r") == "" {
+		contentLines = contentLines[1:]
 	}
 	
-	// Extract frontmatter and content
-	frontmatterStr := strings.Join(frontmatterLines, "\n")
-	contentLines := lines[endIndex+1:]
+	metadata, err := decodeMetadata(frontmatterStr)
+	if err != nil {
+		return nil, err
+	}
 	
+	return &FrontmatterFile{
+		Metadata: metadata,
+		Content:  strings.Join(contentLines, "\n"),
+		Codec:    codec,
+		header:   lines[:headerLines],
+	}, nil
+}
+
+// decodeMetadata decodes YAML frontmatter into the metadata type it holds
+func decodeMetadata(frontmatterStr string) (interface{}, error) {
 	// First check the type field to determine what kind of metadata this is
 	var typeCheck struct {
 		Type    string `yaml:"type"`
@@ -67,18 +68,12 @@ func ParseFrontmatterFile(content []byte) (*FrontmatterFile, error) {
 		if typeCheck.Type == "project" {
 			var projectMeta ProjectMetadata
 			if err := yaml.Unmarshal([]byte(frontmatterStr), &projectMeta); err == nil {
-				return &FrontmatterFile{
-					Metadata: projectMeta,
-					Content:  strings.Join(contentLines, "\n"),
-				}, nil
+				return projectMeta, nil
 			}
 		} else if typeCheck.Type == "task" {
 			var taskMeta TaskMetadata
 			if err := yaml.Unmarshal([]byte(frontmatterStr), &taskMeta); err == nil {
-				return &FrontmatterFile{
-					Metadata: taskMeta,
-					Content:  strings.Join(contentLines, "\n"),
-				}, nil
+				return taskMeta, nil
 			}
 		}
 		
@@ -91,19 +86,13 @@ func ParseFrontmatterFile(content []byte) (*FrontmatterFile, error) {
 				// For now, we'll need another way to distinguish
 				// Let's check if the content suggests it's a project
 				// This is a bit fragile but necessary without explicit type
-				return &FrontmatterFile{
-					Metadata: projectMeta,
-					Content:  strings.Join(contentLines, "\n"),
-				}, nil
+				return projectMeta, nil
 			}
 			
 			// Fall back to task
 			var taskMeta TaskMetadata
 			if err := yaml.Unmarshal([]byte(frontmatterStr), &taskMeta); err == nil {
-				return &FrontmatterFile{
-					Metadata: taskMeta,
-					Content:  strings.Join(contentLines, "\n"),
-				}, nil
+				return taskMeta, nil
 			}
 		}
 	}
@@ -114,16 +103,86 @@ func ParseFrontmatterFile(content []byte) (*FrontmatterFile, error) {
 		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
 	}
 	
-	metadata := noteMeta
-	
-	return &FrontmatterFile{
-		Metadata: metadata,
-		Content:  strings.Join(contentLines, "\n"),
-	}, nil
+	return noteMeta, nil
+}
+
+// Encode renders new metadata and the file's body in the file's frontmatter
+// syntax, keeping header fields the metadata doesn't cover
+func (f *FrontmatterFile) Encode(metadata interface{}) ([]byte, error) {
+	codec := f.Codec
+	if codec == nil {
+		codec = yamlCodec{}
+	}
+	return writeFrontmatter(codec, metadata, f.Content, f.header)
+}
+
+// ModifyFrontmatter replaces a file's metadata with fn's result, keeping
+// its body and frontmatter syntax. fn gets the metadata as read from disk
+// under the directory lock.
+func ModifyFrontmatter(path string, fn func(metadata interface{}) (interface{}, error)) error {
+	return RewriteFile(path, func(content string) (string, error) {
+		fm, err := ParseFrontmatterFile([]byte(content))
+		if err != nil {
+			return "", fmt.Errorf("failed to parse frontmatter: %w", err)
+		}
+
+		metadata, err := fn(fm.Metadata)
+		if err != nil {
+			return "", err
+		}
+
+		newContent, err := fm.Encode(metadata)
+		if err != nil {
+			return "", fmt.Errorf("failed to write frontmatter: %w", err)
+		}
+		return string(newContent), nil
+	})
 }
 
 // WriteFrontmatterFile creates file content with validated YAML frontmatter
 func WriteFrontmatterFile(metadata interface{}, content string) ([]byte, error) {
+	return writeFrontmatter(yamlCodec{}, metadata, content, nil)
+}
+
+// WriteNewFileAs creates the content of a new file at path with validated
+// frontmatter in the codec's syntax. Org and text files start with the
+// title, date, tags and identifier lines Denote writes.
+func WriteNewFileAs(codec FrontmatterCodec, path string, metadata interface{}, content string) ([]byte, error) {
+	var original []string
+	if c, ok := codec.(*lineCodec); ok {
+		if file, err := NewParser().ParseFilename(path); err == nil {
+			original = c.denoteHeader(file)
+			// Denote's tags line in text files is also the tags field, so
+			// it has to carry the tags to be kept
+			if c.tagsKey == "tags" {
+				metadata = withFileTags(metadata, file.Tags)
+			}
+		}
+	}
+	return writeFrontmatter(codec, metadata, content, original)
+}
+
+// withFileTags sets the tags of task or project metadata without any to
+// the tags in the file's name
+func withFileTags(metadata interface{}, tags []string) interface{} {
+	switch m := metadata.(type) {
+	case TaskMetadata:
+		if len(m.Tags) == 0 {
+			m.Tags = tags
+		}
+		return m
+	case ProjectMetadata:
+		if len(m.Tags) == 0 {
+			m.Tags = tags
+		}
+		return m
+	}
+	return metadata
+}
+
+// writeFrontmatter validates metadata and renders it with the codec,
+// followed by a blank line and the content
+func writeFrontmatter(codec FrontmatterCodec, metadata interface{}, content string, original []string) ([]byte, error) {
 	// Validate that metadata has required fields based on type
 	switch m := metadata.(type) {
 	case NoteMetadata:
@@ -151,19 +210,13 @@ func WriteFrontmatterFile(metadata interface{}, content string) ([]byte, error)
 		return nil, fmt.Errorf("unsupported metadata type")
 	}
 	
-	// Marshal to YAML
-	var buf bytes.Buffer
-	encoder := yaml.NewEncoder(&buf)
-	encoder.SetIndent(2)
-	
-	if err := encoder.Encode(metadata); err != nil {
-		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	header, err := codec.Encode(metadata, original)
+	if err != nil {
+		return nil, err
 	}
 	
 	// Build complete file content
-	fileContent := fmt.Sprintf("---\n%s---\n\n%s", buf.String(), content)
-	
-	return []byte(fileContent), nil
+	return []byte(header + "\n" + content), nil
 }
 
 // ValidateYAMLFrontmatter checks if content has valid YAML frontmatter
@@ -174,73 +227,78 @@ func ValidateYAMLFrontmatter(content []byte) error {
 
 // AddLogEntry adds a timestamped log entry to a task file
 func AddLogEntry(filepath string, message string) error {
-	// Read the file
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	// Find the end of frontmatter
-	lines := strings.Split(string(content), "\n")
-	frontmatterEnd := -1
-	inFrontmatter := false
-	
-	for i, line := range lines {
-		if i == 0 && line == "---" {
-			inFrontmatter = true
-			continue
+	return RewriteFile(filepath, func(content string) (string, error) {
+		// Find the end of frontmatter
+		lines := strings.Split(content, "\n")
+		frontmatterEnd := FrontmatterLines(lines) - 1
+
+		if frontmatterEnd < 0 {
+			return "", fmt.Errorf("no frontmatter found in file")
+		}
+
+		// Format the log entry with timestamp
+		logEntry := FormatLogEntry(time.Now(), message)
+
+		// Build the new content
+		var newLines []string
+
+		// Add frontmatter
+		newLines = append(newLines, lines[:frontmatterEnd+1]...)
+
+		// Find where to insert the log entry
+		insertPos := frontmatterEnd + 1
+
+		// Skip any existing blank lines after frontmatter
+		for insertPos < len(lines) && lines[insertPos] == "" {
+			insertPos++
 		}
-		if inFrontmatter && line == "---" {
-			frontmatterEnd = i
-			break
+
+		// Check if we need to add blank lines
+		if insertPos < len(lines) {
+			// Add blank line before log entry if there's content after frontmatter
+			newLines = append(newLines, "")
 		}
-	}
-	
-	if frontmatterEnd == -1 {
-		return fmt.Errorf("no frontmatter found in file")
-	}
-	
-	// Format the log entry with timestamp
-	now := time.Now()
+
+		// Add the log entry
+		newLines = append(newLines, logEntry)
+
+		// Add the rest of the content
+		if insertPos < len(lines) {
+			// Add a blank line after log entry if there's more content
+			newLines = append(newLines, "")
+			newLines = append(newLines, lines[insertPos:]...)
+		}
+
+		return strings.Join(newLines, "\n"), nil
+	})
+}
+
+// logEntryPattern matches a log line written by AddLogEntry
+var logEntryPattern = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2}) \w{3}\]: (.*)$`)
+
+// LogEntry is a dated line added with AddLogEntry
+type LogEntry struct {
+	Date    string // YYYY-MM-DD
+	Message string
+}
+
+// FormatLogEntry formats a log line for the day of t
+func FormatLogEntry(t time.Time, message string) string {
 	// Use reference time to get day name: Mon Jan 2 15:04:05 MST 2006
-	timestamp := now.Format("[2006-01-02 Mon]")
-	logEntry := fmt.Sprintf("%s: %s", timestamp, message)
-	
-	// Build the new content
-	var newLines []string
-	
-	// Add frontmatter
-	newLines = append(newLines, lines[:frontmatterEnd+1]...)
-	
-	// Find where to insert the log entry
-	insertPos := frontmatterEnd + 1
-	
-	// Skip any existing blank lines after frontmatter
-	for insertPos < len(lines) && lines[insertPos] == "" {
-		insertPos++
-	}
-	
-	// Check if we need to add blank lines
-	if insertPos < len(lines) {
-		// Add blank line before log entry if there's content after frontmatter
-		newLines = append(newLines, "")
-	}
-	
-	// Add the log entry
-	newLines = append(newLines, logEntry)
-	
-	// Add the rest of the content
-	if insertPos < len(lines) {
-		// Add a blank line after log entry if there's more content
-		newLines = append(newLines, "")
-		newLines = append(newLines, lines[insertPos:]...)
-	}
-	
-	// Write back to file
-	newContent := strings.Join(newLines, "\n")
-	if err := os.WriteFile(filepath, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	return fmt.Sprintf("%s: %s", t.Format("[2006-01-02 Mon]"), message)
+}
+
+// ParseLogEntries returns the log lines in a file's content, in file order
+// (newest first, since AddLogEntry inserts at the top)
+func ParseLogEntries(content string) []LogEntry {
+	lines := strings.Split(content, "\n")
+
+	var entries []LogEntry
+	for _, line := range lines[FrontmatterLines(lines):] {
+		match := logEntryPattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match != nil {
+			entries = append(entries, LogEntry{Date: match[1], Message: match[2]})
+		}
 	}
-	
-	return nil
-}
\ No newline at end of file
+	return entries
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -14,7 +15,12 @@ type CounterData struct {
 	SpecVersion string `json:"spec_version"`
 }
 
-// IDCounter manages sequential IDs for tasks and projects
+// counterFileName is the index ID counter stored in the notes directory
+const counterFileName = ".denote-task-counter.json"
+
+// IDCounter manages sequential IDs for tasks and projects. The counter file
+// is shared by every process using the directory, so each allocation takes
+// a file lock and re-reads the file rather than trusting the loaded value.
 type IDCounter struct {
 	CounterData
 	mu       sync.Mutex
@@ -22,22 +28,67 @@ type IDCounter struct {
 }
 
 var (
-	globalCounter     *IDCounter
-	globalCounterOnce sync.Once
+	countersMu sync.Mutex
+	counters   = make(map[string]*IDCounter)
 )
 
-// GetIDCounter returns the singleton ID counter for the given directory
+// GetIDCounter returns the shared ID counter for the given directory. A
+// subdirectory of the notes directory uses the notes directory's counter.
 func GetIDCounter(dir string) (*IDCounter, error) {
-	var err error
-	globalCounterOnce.Do(func() {
-		globalCounter, err = loadOrCreateCounter(dir)
-	})
-	return globalCounter, err
+	dir = counterDir(dir)
+
+	countersMu.Lock()
+	defer countersMu.Unlock()
+	
+	if counter, ok := counters[dir]; ok {
+		return counter, nil
+	}
+	
+	counter, err := loadOrCreateCounter(dir)
+	if err != nil {
+		return nil, err
+	}
+	counters[dir] = counter
+	return counter, nil
+}
+
+// NotesRoot is the configured notes directory, the root of every scan.
+// Directories inside it share its counter; a counter file outside it, in
+// a parent directory, is never used. Empty gives each directory its own
+// counter.
+var NotesRoot string
+
+// counterDir returns the directory whose counter dir uses: NotesRoot for
+// dir or any directory below it, and dir itself otherwise
+func counterDir(dir string) string {
+	if NotesRoot == "" {
+		return dir
+	}
+	root, err := filepath.Abs(NotesRoot)
+	if err != nil {
+		return dir
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return dir
+	}
+	return NotesRoot
 }
 
 // loadOrCreateCounter loads an existing counter or creates a new one
 func loadOrCreateCounter(dir string) (*IDCounter, error) {
-	counterFile := filepath.Join(dir, ".denote-task-counter.json")
+	counterFile := filepath.Join(dir, counterFileName)
+	
+	// Hold the lock so two processes don't both create or migrate the file
+	lock, err := LockFile(counterFile + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
 	
 	// Try to load existing counter
 	data, err := os.ReadFile(counterFile)
@@ -113,33 +164,22 @@ func loadOrCreateCounter(dir string) (*IDCounter, error) {
 	return counter, nil
 }
 
-// findMaxIndexID scans the directory for the highest index ID
+// findMaxIndexID scans the directory for the highest index ID, using the
+// metadata index so unchanged files aren't re-parsed
 func findMaxIndexID(dir string) int {
 	maxID := 0
 	
-	// Check task files
-	taskPattern := filepath.Join(dir, "*__task*.md")
-	taskFiles, _ := filepath.Glob(taskPattern)
+	result, err := NewScanner(dir).Scan()
+	if err != nil {
+		return 0
+	}
 	
-	for _, file := range taskFiles {
-		task, err := ParseTaskFile(file)
-		if err != nil {
-			continue
-		}
+	for _, task := range result.Tasks {
 		if task.IndexID > maxID {
 			maxID = task.IndexID
 		}
 	}
-	
-	// Check project files
-	projPattern := filepath.Join(dir, "*__project*.md")
-	projFiles, _ := filepath.Glob(projPattern)
-	
-	for _, file := range projFiles {
-		project, err := ParseProjectFile(file)
-		if err != nil {
-			continue
-		}
+	for _, project := range result.Projects {
 		if project.IndexID > maxID {
 			maxID = project.IndexID
 		}
@@ -148,11 +188,35 @@ func findMaxIndexID(dir string) int {
 	return maxID
 }
 
+// refresh moves the counter forward to the value saved by other processes
+// and past every index ID in the metadata index, so a stale or missing
+// counter file, or files synced from another machine, don't lead to an ID
+// that is already in use. It reads the counter and index files rather than
+// parsing notes, so allocation stays cheap however many files there are.
+// The caller must hold the counter file lock.
+func (c *IDCounter) refresh() {
+	dir := filepath.Dir(c.filePath)
+	if next := readNextIndexID(dir); next > c.CounterData.NextIndexID {
+		c.CounterData.NextIndexID = next
+	}
+	if maxID := LoadIndex(dir).MaxIndexID(); maxID >= c.CounterData.NextIndexID {
+		c.CounterData.NextIndexID = maxID + 1
+	}
+}
+
 // NextIndexID returns the next index ID and increments the counter
 func (c *IDCounter) NextIndexID() (int, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
+	lock, err := LockFile(c.filePath + ".lock")
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Unlock()
+	
+	c.refresh()
+	
 	id := c.CounterData.NextIndexID
 	c.CounterData.NextIndexID++
 	
@@ -165,6 +229,48 @@ func (c *IDCounter) NextIndexID() (int, error) {
 	return id, nil
 }
 
+// EnsureAbove raises the counter so the next index ID is greater than maxID
+func (c *IDCounter) EnsureAbove(maxID int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	lock, err := LockFile(c.filePath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	
+	c.refresh()
+	if c.CounterData.NextIndexID > maxID {
+		return nil
+	}
+	
+	previous := c.CounterData.NextIndexID
+	c.CounterData.NextIndexID = maxID + 1
+	
+	if err := c.save(); err != nil {
+		c.CounterData.NextIndexID = previous
+		return fmt.Errorf("failed to save counter: %w", err)
+	}
+	
+	return nil
+}
+
+// readNextIndexID returns the next index ID recorded in the counter file
+// without creating or migrating it. It returns 0 if there is no usable counter.
+func readNextIndexID(dir string) int {
+	data, err := os.ReadFile(filepath.Join(dir, counterFileName))
+	if err != nil {
+		return 0
+	}
+	
+	var counterData CounterData
+	if err := json.Unmarshal(data, &counterData); err != nil {
+		return 0
+	}
+	return counterData.NextIndexID
+}
+
 // Deprecated: Use NextIndexID instead
 func (c *IDCounter) NextTaskID() (int, error) {
 	return c.NextIndexID()
@@ -182,23 +288,16 @@ func (c *IDCounter) save() error {
 		return fmt.Errorf("failed to marshal counter: %w", err)
 	}
 	
-	// Write to temp file first for atomicity
-	tempFile := c.filePath + ".tmp"
-	if err := os.WriteFile(tempFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temp file: %w", err)
-	}
-	
-	// Rename temp file to actual file (atomic on most systems)
-	if err := os.Rename(tempFile, c.filePath); err != nil {
-		os.Remove(tempFile) // Clean up temp file
-		return fmt.Errorf("failed to rename counter file: %w", err)
+	if err := WriteFileAtomic(c.filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write counter file: %w", err)
 	}
 	
 	return nil
 }
 
-// ResetSingleton resets the singleton (useful for testing or config changes)
+// ResetSingleton drops loaded counters (useful for testing or config changes)
 func ResetSingleton() {
-	globalCounterOnce = sync.Once{}
-	globalCounter = nil
+	countersMu.Lock()
+	defer countersMu.Unlock()
+	counters = make(map[string]*IDCounter)
 }
\ No newline at end of file
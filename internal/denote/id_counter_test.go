@@ -0,0 +1,78 @@
+package denote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNextIndexIDSkipsIndexedIDs(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	for i := 1; i <= 5; i++ {
+		path := writeNote(t, dir, fmt.Sprintf("2024010%dT090000--task-%d__task.md", i, i), taskNote(i, fmt.Sprintf("Task %d", i)))
+		// Old enough to be cached by the scan
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := NewScanner(dir).Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A counter file synced from elsewhere that is behind the notes
+	stale := []byte(`{"next_index_id": 2, "spec_version": "2.0.1"}`)
+	if err := os.WriteFile(filepath.Join(dir, counterFileName), stale, 0644); err != nil {
+		t.Fatal(err)
+	}
+	ResetSingleton()
+	t.Cleanup(ResetSingleton)
+
+	counter, err := GetIDCounter(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := counter.NextIndexID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 6 {
+		t.Errorf("NextIndexID = %d, want 6", id)
+	}
+	if next := readNextIndexID(dir); next != 7 {
+		t.Errorf("saved next_index_id = %d, want 7", next)
+	}
+}
+
+func TestCounterDirStopsAtNotesRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "notes")
+	sub := filepath.Join(root, "work", "2024")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// A counter left above the notes directory
+	if err := os.WriteFile(filepath.Join(parent, counterFileName), []byte(`{"next_index_id": 500}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := NotesRoot
+	NotesRoot = root
+	t.Cleanup(func() { NotesRoot = orig })
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{root, root},
+		{sub, root},
+		{parent, parent},
+	}
+	for _, tt := range tests {
+		if got := counterDir(tt.dir); got != tt.want {
+			t.Errorf("counterDir(%s) = %s, want %s", tt.dir, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package denote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// IndexFileName is the metadata cache stored in the notes directory
+const IndexFileName = ".denote-tasks-index"
+
+// indexVersion is bumped whenever the cached metadata layout changes,
+// which invalidates existing index files
+const indexVersion = 4
+
+// racyWindow is how recently a file may have been modified and still be
+// cached. Files written within this window could change again without a
+// visible mtime change on filesystems with coarse timestamps.
+const racyWindow = 2 * time.Second
+
+// IndexEntry caches the parsed metadata of a single file. An entry is only
+// used while the file's modification time and size are unchanged.
+type IndexEntry struct {
+	ModTime   int64            `json:"mtime"` // UnixNano
+	Size      int64            `json:"size"`
+	Task      *TaskMetadata    `json:"task,omitempty"`
+	Project   *ProjectMetadata `json:"project,omitempty"`
+	Checklist []ChecklistItem  `json:"checklist,omitempty"` // Task body checklist
+	Warning   string           `json:"warning,omitempty"`   // Frontmatter problem found when parsed
+}
+
+// Index is an on-disk cache of task and project metadata keyed by path
+// relative to the notes directory. Tasks and projects loaded from the index
+// do not carry file Content (task checklists are cached); use
+// ParseTaskFile/ParseProjectFile for that.
+// An Index is safe for concurrent use.
+type Index struct {
+	Version int                    `json:"version"`
+	Entries map[string]*IndexEntry `json:"entries"`
+
+	mu    sync.Mutex
+	dir   string
+	dirty bool
+	seen  map[string]bool
+}
+
+// LoadIndex reads the index for a directory. A missing, corrupt or
+// outdated index file yields an empty index rather than an error, so
+// callers always fall back to parsing files.
+func LoadIndex(dir string) *Index {
+	idx := newIndex(dir)
+
+	data, err := os.ReadFile(filepath.Join(dir, IndexFileName))
+	if err != nil {
+		return idx
+	}
+
+	var loaded Index
+	if err := json.Unmarshal(data, &loaded); err != nil || loaded.Version != indexVersion || loaded.Entries == nil {
+		// Discard the bad cache; it will be rewritten on save
+		idx.dirty = true
+		return idx
+	}
+
+	idx.Entries = loaded.Entries
+	return idx
+}
+
+// newIndex creates an empty index for a directory
+func newIndex(dir string) *Index {
+	return &Index{
+		Version: indexVersion,
+		Entries: make(map[string]*IndexEntry),
+		dir:     dir,
+		seen:    make(map[string]bool),
+	}
+}
+
+// LoadTask returns the task at path, using cached metadata when the file is
+// unchanged and re-parsing (and updating the cache) otherwise
+func (idx *Index) LoadTask(path string) (*Task, error) {
+	task, _, err := idx.loadTask(path)
+	return task, err
+}
+
+// loadTask is LoadTask that also returns any frontmatter warning
+func (idx *Index) loadTask(path string) (*Task, error, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	key := idx.key(path)
+	if entry := idx.lookup(key, info); entry != nil && entry.Task != nil {
+		file, err := NewParser().ParseFilename(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		task := &Task{
+			File:         *file,
+			TaskMetadata: *entry.Task,
+			ModTime:      info.ModTime(),
+			Checklist:    entry.Checklist,
+		}
+		if task.TaskMetadata.Title != "" {
+			task.File.Title = task.TaskMetadata.Title
+		}
+		task.File.Path = path
+		task.File.ModTime = info.ModTime()
+		return task, entry.warning(), nil
+	}
+
+	task, fmErr, err := parseTaskFile(path)
+	if err != nil {
+		idx.store(key, nil)
+		return nil, nil, err
+	}
+
+	meta := task.TaskMetadata
+	entry := newIndexEntry(info, fmErr, &meta, nil)
+	if entry != nil {
+		entry.Checklist = task.Checklist
+	}
+	idx.store(key, entry)
+
+	return task, fmErr, nil
+}
+
+// LoadProject returns the project at path, using cached metadata when the
+// file is unchanged and re-parsing (and updating the cache) otherwise
+func (idx *Index) LoadProject(path string) (*Project, error) {
+	project, _, err := idx.loadProject(path)
+	return project, err
+}
+
+// loadProject is LoadProject that also returns any frontmatter warning
+func (idx *Index) loadProject(path string) (*Project, error, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	key := idx.key(path)
+	if entry := idx.lookup(key, info); entry != nil && entry.Project != nil {
+		file, err := NewParser().ParseFilename(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		project := &Project{
+			File:            *file,
+			ProjectMetadata: *entry.Project,
+			ModTime:         info.ModTime(),
+		}
+		if project.ProjectMetadata.Title != "" {
+			project.File.Title = project.ProjectMetadata.Title
+		}
+		project.File.Path = path
+		project.File.ModTime = info.ModTime()
+		return project, entry.warning(), nil
+	}
+
+	project, fmErr, err := parseProjectFile(path)
+	if err != nil {
+		idx.store(key, nil)
+		return nil, nil, err
+	}
+
+	meta := project.ProjectMetadata
+	idx.store(key, newIndexEntry(info, fmErr, nil, &meta))
+
+	return project, fmErr, nil
+}
+
+// lookup marks a key as seen and returns its entry if still valid
+func (idx *Index) lookup(key string, info os.FileInfo) *IndexEntry {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.seen[key] = true
+	if entry, ok := idx.Entries[key]; ok && entry.matches(info) {
+		return entry
+	}
+	return nil
+}
+
+// store saves an entry, or removes the key if entry is nil
+func (idx *Index) store(key string, entry *IndexEntry) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if entry == nil {
+		if _, ok := idx.Entries[key]; ok {
+			delete(idx.Entries, key)
+			idx.dirty = true
+		}
+		return
+	}
+
+	idx.Entries[key] = entry
+	idx.dirty = true
+}
+
+// newIndexEntry builds an entry for freshly parsed metadata. Files modified
+// within racyWindow are not cached and nil is returned.
+func newIndexEntry(info os.FileInfo, fmErr error, task *TaskMetadata, project *ProjectMetadata) *IndexEntry {
+	if time.Since(info.ModTime()) < racyWindow {
+		return nil
+	}
+
+	entry := &IndexEntry{
+		ModTime: info.ModTime().UnixNano(),
+		Size:    info.Size(),
+		Task:    task,
+		Project: project,
+	}
+	if fmErr != nil {
+		entry.Warning = fmErr.Error()
+	}
+	return entry
+}
+
+// Prune drops entries for files that were not loaded since the index was
+// read. Only call this after a full directory scan.
+func (idx *Index) Prune() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for key := range idx.Entries {
+		if !idx.seen[key] {
+			delete(idx.Entries, key)
+			idx.dirty = true
+		}
+	}
+}
+
+// MaxIndexID returns the highest index ID among the cached tasks and
+// projects, or 0 if there are none
+func (idx *Index) MaxIndexID() int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	maxID := 0
+	for _, entry := range idx.Entries {
+		if entry.Task != nil && entry.Task.IndexID > maxID {
+			maxID = entry.Task.IndexID
+		}
+		if entry.Project != nil && entry.Project.IndexID > maxID {
+			maxID = entry.Project.IndexID
+		}
+	}
+	return maxID
+}
+
+// Save writes the index to disk if it changed. Failing to save is not
+// fatal for callers since the index is only a cache.
+func (idx *Index) Save() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if !idx.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	if err := WriteFileAtomic(filepath.Join(idx.dir, IndexFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+
+	idx.dirty = false
+	return nil
+}
+
+// RebuildIndex discards any existing index and re-parses every task and
+// project file in the directory. It returns the number of files indexed.
+func RebuildIndex(dir string) (int, error) {
+	idx := newIndex(dir)
+	idx.dirty = true
+
+	scanner := &Scanner{BaseDir: dir, index: idx}
+	tasks, err := scanner.FindTasks()
+	if err != nil {
+		return 0, err
+	}
+	projects, err := scanner.FindProjects()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := idx.Save(); err != nil {
+		return 0, err
+	}
+
+	return len(tasks) + len(projects), nil
+}
+
+// key returns the index key for a path
+func (idx *Index) key(path string) string {
+	if rel, err := filepath.Rel(idx.dir, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// matches reports whether the entry is still valid for the file
+func (e *IndexEntry) matches(info os.FileInfo) bool {
+	return e.ModTime == info.ModTime().UnixNano() && e.Size == info.Size()
+}
+
+// warning returns the cached frontmatter warning as an error
+func (e *IndexEntry) warning() error {
+	if e.Warning == "" {
+		return nil
+	}
+	return errors.New(e.Warning)
+}
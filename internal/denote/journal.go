@@ -0,0 +1,348 @@
+package denote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JournalFileName is the undo/redo journal stored in the notes directory
+const JournalFileName = ".denote-tasks-journal.json"
+
+// journalVersion is bumped whenever the journal layout changes
+const journalVersion = 1
+
+// Journal bounds; the oldest entries are dropped first
+const (
+	journalMaxEntries = 100
+	journalMaxAge     = 30 * 24 * time.Hour
+	journalMaxBytes   = 10 << 20
+)
+
+// ErrNothingToUndo is returned when the undo stack is empty
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrNothingToRedo is returned when the redo stack is empty
+var ErrNothingToRedo = errors.New("nothing to redo")
+
+// ErrJournalConflict is returned when a file was modified after the
+// operation being undone or redone
+var ErrJournalConflict = errors.New("file was modified since")
+
+// FileImage is the saved state of one file touched by an operation
+type FileImage struct {
+	Path    string `json:"path"`              // Relative to the notes directory
+	Exists  bool   `json:"exists"`            // File existed before the operation
+	Content string `json:"content,omitempty"` // Contents before the operation
+	After   string `json:"after"`             // SHA-256 after the operation, empty if the file was gone
+}
+
+// JournalEntry is one undoable operation
+type JournalEntry struct {
+	Time    time.Time   `json:"time"`
+	Summary string      `json:"summary"`
+	Files   []FileImage `json:"files"`
+}
+
+// Journal holds the undo and redo stacks for a notes directory, newest last
+type Journal struct {
+	Version int             `json:"version"`
+	Undo    []*JournalEntry `json:"undo"`
+	Redo    []*JournalEntry `json:"redo"`
+}
+
+// Operation records the before-image of every note written, renamed or
+// removed while it is active. Only one operation is active at a time;
+// BeginOperation waits for the current one to be committed.
+type Operation struct {
+	Summary string
+
+	dir   string
+	files []FileImage
+	seen  map[string]bool
+}
+
+var (
+	// operationMu is held from BeginOperation until Commit
+	operationMu sync.Mutex
+	// activeMu guards activeOp
+	activeMu sync.Mutex
+	activeOp *Operation
+)
+
+// BeginOperation starts recording file changes under dir. Commit must be
+// called exactly once to save the entry and end the operation.
+func BeginOperation(dir, summary string) *Operation {
+	operationMu.Lock()
+
+	op := &Operation{
+		Summary: summary,
+		dir:     dir,
+		seen:    make(map[string]bool),
+	}
+
+	activeMu.Lock()
+	activeOp = op
+	activeMu.Unlock()
+
+	return op
+}
+
+// Commit ends the operation and, if any file changed, adds it to the
+// journal and clears the redo stack
+func (op *Operation) Commit() error {
+	activeMu.Lock()
+	activeOp = nil
+	activeMu.Unlock()
+	defer operationMu.Unlock()
+
+	// Drop files that ended up unchanged
+	var changed []FileImage
+	for _, image := range op.files {
+		after := fileHash(filepath.Join(op.dir, image.Path))
+		if image.Exists && after == contentHash(image.Content) || !image.Exists && after == "" {
+			continue
+		}
+		image.After = after
+		changed = append(changed, image)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	entry := &JournalEntry{
+		Time:    time.Now(),
+		Summary: op.Summary,
+		Files:   changed,
+	}
+
+	return updateJournal(op.dir, func(j *Journal) error {
+		j.Undo = append(j.Undo, entry)
+		j.Redo = nil
+		return nil
+	})
+}
+
+// track saves the before-image of a file the first time it is touched.
+// Hidden files such as the counter, index and journal are not recorded.
+func (op *Operation) track(path string) {
+	rel, err := filepath.Rel(op.dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") || strings.HasPrefix(filepath.Base(rel), ".") {
+		return
+	}
+	if op.seen[rel] {
+		return
+	}
+	op.seen[rel] = true
+
+	image := FileImage{Path: rel}
+	if content, err := os.ReadFile(path); err == nil {
+		image.Exists = true
+		image.Content = string(content)
+	}
+	op.files = append(op.files, image)
+}
+
+// recordBeforeImage adds a file to the active operation, if any
+func recordBeforeImage(path string) {
+	activeMu.Lock()
+	op := activeOp
+	activeMu.Unlock()
+
+	if op != nil {
+		op.track(path)
+	}
+}
+
+// LoadJournal reads the journal for a directory. A missing or unreadable
+// journal is treated as empty.
+func LoadJournal(dir string) *Journal {
+	j := &Journal{Version: journalVersion}
+
+	data, err := os.ReadFile(filepath.Join(dir, JournalFileName))
+	if err != nil {
+		return j
+	}
+
+	var loaded Journal
+	if err := json.Unmarshal(data, &loaded); err != nil || loaded.Version != journalVersion {
+		return j
+	}
+	return &loaded
+}
+
+// Undo reverts the most recent operation. Unless force is set, it refuses
+// to run if a file was changed after the operation, since undoing would
+// discard that change.
+func Undo(dir string, force bool) (*JournalEntry, error) {
+	var undone *JournalEntry
+	err := updateJournal(dir, func(j *Journal) error {
+		if len(j.Undo) == 0 {
+			return ErrNothingToUndo
+		}
+		entry := j.Undo[len(j.Undo)-1]
+
+		inverse, err := applyEntry(dir, entry, force)
+		if err != nil {
+			return err
+		}
+
+		j.Undo = j.Undo[:len(j.Undo)-1]
+		j.Redo = append(j.Redo, inverse)
+		undone = entry
+		return nil
+	})
+	return undone, err
+}
+
+// Redo re-applies the most recently undone operation
+func Redo(dir string, force bool) (*JournalEntry, error) {
+	var redone *JournalEntry
+	err := updateJournal(dir, func(j *Journal) error {
+		if len(j.Redo) == 0 {
+			return ErrNothingToRedo
+		}
+		entry := j.Redo[len(j.Redo)-1]
+
+		inverse, err := applyEntry(dir, entry, force)
+		if err != nil {
+			return err
+		}
+
+		j.Redo = j.Redo[:len(j.Redo)-1]
+		j.Undo = append(j.Undo, inverse)
+		redone = entry
+		return nil
+	})
+	return redone, err
+}
+
+// applyEntry restores the saved images of an entry and returns the entry
+// that reverses it
+func applyEntry(dir string, entry *JournalEntry, force bool) (*JournalEntry, error) {
+	lock, err := LockDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	if !force {
+		for _, image := range entry.Files {
+			if fileHash(filepath.Join(dir, image.Path)) != image.After {
+				return nil, fmt.Errorf("%s: %w %q", image.Path, ErrJournalConflict, entry.Summary)
+			}
+		}
+	}
+
+	inverse := &JournalEntry{Time: time.Now(), Summary: entry.Summary}
+	for _, image := range entry.Files {
+		path := filepath.Join(dir, image.Path)
+
+		current := FileImage{Path: image.Path}
+		if content, err := os.ReadFile(path); err == nil {
+			current.Exists = true
+			current.Content = string(content)
+		}
+		if image.Exists {
+			current.After = contentHash(image.Content)
+		}
+		inverse.Files = append(inverse.Files, current)
+	}
+
+	for _, image := range entry.Files {
+		path := filepath.Join(dir, image.Path)
+		if image.Exists {
+			if err := writeFileAtomic(path, []byte(image.Content), 0644); err != nil {
+				return nil, fmt.Errorf("failed to restore %s: %w", image.Path, err)
+			}
+		} else if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove %s: %w", image.Path, err)
+		}
+	}
+
+	return inverse, nil
+}
+
+// updateJournal loads the journal under its lock, applies fn and saves the
+// result with old entries pruned
+func updateJournal(dir string, fn func(j *Journal) error) error {
+	path := filepath.Join(dir, JournalFileName)
+
+	lock, err := LockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	j := LoadJournal(dir)
+	if err := fn(j); err != nil {
+		return err
+	}
+	j.Version = journalVersion
+	j.Undo = pruneJournal(j.Undo)
+	j.Redo = pruneJournal(j.Redo)
+
+	data, err := json.Marshal(j)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// pruneJournal drops entries beyond the age, count and size limits,
+// oldest first
+func pruneJournal(entries []*JournalEntry) []*JournalEntry {
+	cutoff := time.Now().Add(-journalMaxAge)
+	for len(entries) > 0 && entries[0].Time.Before(cutoff) {
+		entries = entries[1:]
+	}
+
+	if len(entries) > journalMaxEntries {
+		entries = entries[len(entries)-journalMaxEntries:]
+	}
+
+	size := 0
+	for _, entry := range entries {
+		size += entry.size()
+	}
+	for len(entries) > 0 && size > journalMaxBytes {
+		size -= entries[0].size()
+		entries = entries[1:]
+	}
+
+	return entries
+}
+
+// size approximates the bytes an entry takes in the journal
+func (e *JournalEntry) size() int {
+	size := len(e.Summary)
+	for _, image := range e.Files {
+		size += len(image.Path) + len(image.Content) + len(image.After)
+	}
+	return size
+}
+
+// fileHash returns the SHA-256 of a file, or "" if it doesn't exist
+func fileHash(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return contentHash(string(content))
+}
+
+// contentHash returns the SHA-256 of content as hex
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
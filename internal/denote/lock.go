@@ -0,0 +1,93 @@
+package denote
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DirLockFileName is the lock file that serializes updates to notes in a
+// directory across processes
+const DirLockFileName = ".denote-tasks.lock"
+
+const (
+	// lockTimeout is how long to wait for another process to release a lock
+	lockTimeout = 10 * time.Second
+	// lockRetryInterval is how often a held lock is retried
+	lockRetryInterval = 10 * time.Millisecond
+)
+
+// errLocked is returned by tryLock when another holder has the lock
+var errLocked = errors.New("lock is held by another process")
+
+// FileLock is an exclusive advisory lock held on a lock file. Locks are
+// not reentrant: taking the same lock twice, even in one process, blocks.
+type FileLock struct {
+	file *os.File
+	path string
+}
+
+// LockFile acquires an exclusive lock on path, creating the lock file if
+// needed. It waits up to lockTimeout for other holders to release it.
+func LockFile(path string) (*FileLock, error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := tryLock(path)
+		if err == nil {
+			return &FileLock{file: file, path: path}, nil
+		}
+		if !errors.Is(err, errLocked) {
+			return nil, fmt.Errorf("failed to lock %s: %w", filepath.Base(path), err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock on %s", filepath.Base(path))
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// LockDir acquires the directory-wide lock used around read-modify-write
+// updates of task and project files
+func LockDir(dir string) (*FileLock, error) {
+	return LockFile(filepath.Join(dir, DirLockFileName))
+}
+
+// WithFileLock runs fn holding the directory lock for the directory
+// containing path. Every read-modify-write of a note goes through here, so
+// a change another process makes between the read and the write isn't lost.
+func WithFileLock(path string, fn func() error) error {
+	lock, err := LockDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	return fn()
+}
+
+// RewriteFile replaces the contents of path with fn's result, reading and
+// writing under the directory lock
+func RewriteFile(path string, fn func(content string) (string, error)) error {
+	return WithFileLock(path, func() error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		updated, err := fn(string(content))
+		if err != nil {
+			return err
+		}
+
+		if err := WriteFileAtomic(path, []byte(updated), 0644); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		return nil
+	})
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	return unlock(l.file, l.path)
+}
@@ -0,0 +1,34 @@
+//go:build !unix
+
+package denote
+
+import (
+	"os"
+	"time"
+)
+
+// staleLockAge is how old a lock file must be before it is assumed to be
+// left over from a process that died while holding it
+const staleLockAge = time.Minute
+
+// tryLock creates the lock file exclusively; its existence is the lock
+func tryLock(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0644)
+	if err == nil {
+		return file, nil
+	}
+	if !os.IsExist(err) {
+		return nil, err
+	}
+
+	if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+		os.Remove(path)
+	}
+	return nil, errLocked
+}
+
+// unlock removes the lock file
+func unlock(file *os.File, path string) error {
+	file.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package denote
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLock opens the lock file and takes a non-blocking flock on it. The
+// kernel releases the lock if the process dies, so lock files are never stale.
+func tryLock(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// unlock releases the flock; the lock file is left in place for reuse
+func unlock(file *os.File, path string) error {
+	defer file.Close()
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -11,8 +11,9 @@ import (
 )
 
 var (
-	// Denote filename pattern: YYYYMMDDTHHMMSS-title__tags.md or YYYYMMDDTHHMMSS--title__tags.md
-	denotePattern = regexp.MustCompile(`^(\d{8}T\d{6})-{1,2}([^_]+)(?:__(.+))?\.md$`)
+	// Denote filename pattern: YYYYMMDDTHHMMSS-title__tags.md or YYYYMMDDTHHMMSS--title__tags.md,
+	// or .org or .txt for the other file types
+	denotePattern = regexp.MustCompile(`^(\d{8}T\d{6})-{1,2}([^_]+)(?:__(.+))?\.(?:md|org|txt)$`)
 )
 
 // Parser handles parsing of Denote files
@@ -76,41 +77,53 @@ func (p *Parser) ParseFilename(filename string) (*File, error) {
 
 // ParseTaskFile reads and parses a task file
 func ParseTaskFile(path string) (*Task, error) {
+	task, _, err := parseTaskFile(path)
+	return task, err
+}
+
+// parseTaskFile parses a task file. Frontmatter problems are returned as
+// fmErr rather than err since the task is still usable with defaults.
+func parseTaskFile(path string) (task *Task, fmErr error, err error) {
 	// Parse filename first
 	p := NewParser()
 	file, err := p.ParseFilename(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Check if it's a task file
 	if !contains(file.Tags, "task") {
-		return nil, fmt.Errorf("not a task file: %s", path)
+		return nil, nil, fmt.Errorf("not a task file: %s", path)
 	}
 
 	// Read file content
 	content, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Get file info
 	info, err := os.Stat(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to stat file: %w", err)
+		return nil, nil, fmt.Errorf("failed to stat file: %w", err)
 	}
 
-	task := &Task{
-		File:    *file,
-		ModTime: info.ModTime(),
-		Content: string(content),
+	task = &Task{
+		File:      *file,
+		ModTime:   info.ModTime(),
+		Content:   string(content),
+		Checklist: ParseChecklist(string(content)),
 	}
 
 	// Parse frontmatter using strict parser
 	if file, err := ParseFrontmatterFile(content); err == nil {
 		if taskMeta, ok := file.Metadata.(TaskMetadata); ok {
 			task.TaskMetadata = taskMeta
+		} else {
+			fmErr = fmt.Errorf("frontmatter is not task metadata (missing \"type: task\"?)")
 		}
+	} else {
+		fmErr = err
 	}
 
 	// Set defaults per spec
@@ -123,36 +136,43 @@ func ParseTaskFile(path string) (*Task, error) {
 		task.File.Title = task.TaskMetadata.Title
 	}
 
-	return task, nil
+	return task, fmErr, nil
 }
 
 // ParseProjectFile reads and parses a project file
 func ParseProjectFile(path string) (*Project, error) {
+	project, _, err := parseProjectFile(path)
+	return project, err
+}
+
+// parseProjectFile parses a project file. Frontmatter problems are returned
+// as fmErr rather than err since the project is still usable with defaults.
+func parseProjectFile(path string) (project *Project, fmErr error, err error) {
 	// Parse filename first
 	p := NewParser()
 	file, err := p.ParseFilename(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Check if it's a project file
 	if !contains(file.Tags, "project") {
-		return nil, fmt.Errorf("not a project file: %s", path)
+		return nil, nil, fmt.Errorf("not a project file: %s", path)
 	}
 
 	// Read file content
 	content, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Get file info
 	info, err := os.Stat(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to stat file: %w", err)
+		return nil, nil, fmt.Errorf("failed to stat file: %w", err)
 	}
 
-	project := &Project{
+	project = &Project{
 		File:    *file,
 		ModTime: info.ModTime(),
 		Content: string(content),
@@ -162,7 +182,11 @@ func ParseProjectFile(path string) (*Project, error) {
 	if file, err := ParseFrontmatterFile(content); err == nil {
 		if projMeta, ok := file.Metadata.(ProjectMetadata); ok {
 			project.ProjectMetadata = projMeta
+		} else {
+			fmErr = fmt.Errorf("frontmatter is not project metadata (missing \"type: project\"?)")
 		}
+	} else {
+		fmErr = err
 	}
 
 	// Set defaults per spec
@@ -175,7 +199,7 @@ func ParseProjectFile(path string) (*Project, error) {
 		project.File.Title = project.ProjectMetadata.Title
 	}
 
-	return project, nil
+	return project, fmErr, nil
 }
 
 // parseFrontmatter extracts YAML frontmatter from file content
@@ -0,0 +1,162 @@
+package denote
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence describes how a task repeats
+type Recurrence struct {
+	Interval int    // Number of units between occurrences
+	Unit     string // d, w, m, y
+	Weekdays bool   // Repeat on Monday through Friday only
+	MonthDay int    // Day of month for "monthly on N" (0 = not set)
+	Relative bool   // Schedule from the completion date instead of the due date
+}
+
+var (
+	// "every 2w", "every 3 days", "every week"
+	recurEveryPattern = regexp.MustCompile(`^every\s+(\d+)?\s*(d|day|days|w|week|weeks|m|month|months|y|year|years)$`)
+	// "2w", "3d" (used with "after done")
+	recurShortPattern = regexp.MustCompile(`^(\d+)([dwmy])$`)
+	// "monthly on 15", "every month on 15", "every 2 months on 15"
+	recurMonthDayPattern = regexp.MustCompile(`^(?:monthly|every\s+(\d+)?\s*months?)\s+on\s+(\d{1,2})$`)
+)
+
+// ParseRecurrence parses a recurrence rule such as "every 2w", "monthly on 15",
+// "weekdays" or "3d after done"
+func ParseRecurrence(input string) (*Recurrence, error) {
+	rule := strings.TrimSpace(strings.ToLower(input))
+	if rule == "" {
+		return nil, fmt.Errorf("empty recurrence")
+	}
+
+	r := &Recurrence{Interval: 1}
+
+	// Relative rules repeat from the completion date
+	for _, suffix := range []string{" after done", " after completion"} {
+		if strings.HasSuffix(rule, suffix) {
+			r.Relative = true
+			rule = strings.TrimSpace(strings.TrimSuffix(rule, suffix))
+			break
+		}
+	}
+
+	switch rule {
+	case "daily":
+		r.Unit = "d"
+		return r, nil
+	case "weekly":
+		r.Unit = "w"
+		return r, nil
+	case "monthly":
+		r.Unit = "m"
+		return r, nil
+	case "yearly", "annually":
+		r.Unit = "y"
+		return r, nil
+	case "weekdays", "every weekday":
+		r.Unit = "d"
+		r.Weekdays = true
+		return r, nil
+	}
+
+	if matched := recurMonthDayPattern.FindStringSubmatch(rule); matched != nil {
+		if matched[1] != "" {
+			r.Interval, _ = strconv.Atoi(matched[1])
+		}
+		day, _ := strconv.Atoi(matched[2])
+		if day < 1 || day > 31 {
+			return nil, fmt.Errorf("invalid day of month in recurrence: %d", day)
+		}
+		r.Unit = "m"
+		r.MonthDay = day
+	} else if matched := recurEveryPattern.FindStringSubmatch(rule); matched != nil {
+		if matched[1] != "" {
+			r.Interval, _ = strconv.Atoi(matched[1])
+		}
+		r.Unit = matched[2][:1]
+	} else if matched := recurShortPattern.FindStringSubmatch(rule); matched != nil {
+		r.Interval, _ = strconv.Atoi(matched[1])
+		r.Unit = matched[2]
+	} else {
+		return nil, fmt.Errorf("unrecognized recurrence: %s (try: every 2w, monthly on 15, weekdays, 3d after done)", input)
+	}
+
+	if r.Interval <= 0 {
+		return nil, fmt.Errorf("recurrence interval must be positive: %s", input)
+	}
+
+	return r, nil
+}
+
+// IsValidRecurrence checks if a recurrence rule can be parsed
+func IsValidRecurrence(rule string) bool {
+	_, err := ParseRecurrence(rule)
+	return err == nil
+}
+
+// Next returns the first occurrence strictly after the given date
+func (r *Recurrence) Next(from time.Time) time.Time {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+
+	if r.Weekdays {
+		next := from.AddDate(0, 0, 1)
+		for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	}
+
+	if r.MonthDay > 0 {
+		// Try the current month first, then step forward by the interval
+		next := monthDayIn(from.Year(), from.Month(), r.MonthDay, from.Location())
+		for !next.After(from) {
+			first := time.Date(next.Year(), next.Month(), 1, 0, 0, 0, 0, from.Location()).AddDate(0, r.Interval, 0)
+			next = monthDayIn(first.Year(), first.Month(), r.MonthDay, from.Location())
+		}
+		return next
+	}
+
+	switch r.Unit {
+	case "w":
+		return from.AddDate(0, 0, 7*r.Interval)
+	case "m":
+		return from.AddDate(0, r.Interval, 0)
+	case "y":
+		return from.AddDate(r.Interval, 0, 0)
+	default:
+		return from.AddDate(0, 0, r.Interval)
+	}
+}
+
+// String returns the canonical form of the recurrence rule
+func (r *Recurrence) String() string {
+	var rule string
+	switch {
+	case r.Weekdays:
+		rule = "weekdays"
+	case r.MonthDay > 0 && r.Interval == 1:
+		rule = fmt.Sprintf("monthly on %d", r.MonthDay)
+	case r.MonthDay > 0:
+		rule = fmt.Sprintf("every %d months on %d", r.Interval, r.MonthDay)
+	default:
+		rule = fmt.Sprintf("every %d%s", r.Interval, r.Unit)
+	}
+	if r.Relative {
+		rule += " after done"
+	}
+	return rule
+}
+
+// monthDayIn returns the given day in a month, clamped to the month's last day
+func monthDayIn(year int, month time.Month, day int, loc *time.Location) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,141 @@
+package denote
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// ScanError records a file that could not be parsed cleanly
+type ScanError struct {
+	Path    string
+	Err     error
+	Skipped bool // File was left out of the results; otherwise it loaded with defaults
+}
+
+func (e ScanError) Error() string {
+	return fmt.Sprintf("%s: %v", filepath.Base(e.Path), e.Err)
+}
+
+// ScanResult holds everything found by a directory scan. Files, Tasks and
+// Projects are in path order (tasks first), independent of worker timing.
+type ScanResult struct {
+	Files    []File
+	Tasks    []*Task
+	Projects []*Project
+	Errors   []ScanError
+}
+
+// scanItem is the outcome of loading one file
+type scanItem struct {
+	task    *Task
+	project *Project
+	warning error
+	err     error
+}
+
+// Scan loads all task and project files using a bounded pool of workers
+// and collects per-file errors instead of dropping them
+func (s *Scanner) Scan() (*ScanResult, error) {
+	taskPaths, projectPaths, err := s.findNotes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find task and project files: %w", err)
+	}
+
+	taskItems := s.loadTasks(taskPaths)
+	projectItems := s.loadProjects(projectPaths)
+
+	// Every task and project was visited, so stale entries can go
+	if s.index != nil {
+		s.index.Prune()
+	}
+	s.SaveIndex()
+
+	result := &ScanResult{}
+	for i, item := range taskItems {
+		if item.err != nil {
+			result.Errors = append(result.Errors, ScanError{Path: taskPaths[i], Err: item.err, Skipped: true})
+			continue
+		}
+		if item.warning != nil {
+			result.Errors = append(result.Errors, ScanError{Path: taskPaths[i], Err: item.warning})
+		}
+		item.task.File.ModTime = item.task.ModTime
+		result.Files = append(result.Files, item.task.File)
+		result.Tasks = append(result.Tasks, item.task)
+	}
+	for i, item := range projectItems {
+		if item.err != nil {
+			result.Errors = append(result.Errors, ScanError{Path: projectPaths[i], Err: item.err, Skipped: true})
+			continue
+		}
+		if item.warning != nil {
+			result.Errors = append(result.Errors, ScanError{Path: projectPaths[i], Err: item.warning})
+		}
+		item.project.File.ModTime = item.project.ModTime
+		result.Files = append(result.Files, item.project.File)
+		result.Projects = append(result.Projects, item.project)
+	}
+
+	ResolveDependencies(result.Tasks)
+
+	return result, nil
+}
+
+// loadTasks loads task files in parallel, returning results in path order
+func (s *Scanner) loadTasks(paths []string) []scanItem {
+	idx := s.metadataIndex()
+	items := make([]scanItem, len(paths))
+	s.parallel(len(paths), func(i int) {
+		if idx != nil {
+			items[i].task, items[i].warning, items[i].err = idx.loadTask(paths[i])
+		} else {
+			items[i].task, items[i].warning, items[i].err = parseTaskFile(paths[i])
+		}
+	})
+	return items
+}
+
+// loadProjects loads project files in parallel, returning results in path order
+func (s *Scanner) loadProjects(paths []string) []scanItem {
+	idx := s.metadataIndex()
+	items := make([]scanItem, len(paths))
+	s.parallel(len(paths), func(i int) {
+		if idx != nil {
+			items[i].project, items[i].warning, items[i].err = idx.loadProject(paths[i])
+		} else {
+			items[i].project, items[i].warning, items[i].err = parseProjectFile(paths[i])
+		}
+	})
+	return items
+}
+
+// parallel calls fn for 0..n-1 on at most Workers goroutines
+func (s *Scanner) parallel(n int, fn func(i int)) {
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
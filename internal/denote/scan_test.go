@@ -0,0 +1,172 @@
+package denote
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeNote writes a note under dir, creating parent directories
+func writeNote(t testing.TB, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// taskNote is the content of a minimal task file
+func taskNote(id int, title string) string {
+	return fmt.Sprintf("---\ntitle: %s\nindex_id: %d\ntype: task\nstatus: open\n---\n", title, id)
+}
+
+// generateNotes writes n task files, with a project for every 20 tasks,
+// spread over a few subdirectories in shuffled order
+func generateNotes(t testing.TB, dir string, n int) {
+	t.Helper()
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	subdirs := []string{"", "work", "home", "archive/2023"}
+
+	order := rand.New(rand.NewSource(1)).Perm(n)
+	for _, i := range order {
+		id := start.Add(time.Duration(i) * time.Second).Format("20060102T150405")
+		sub := subdirs[i%len(subdirs)]
+		if i%20 == 0 {
+			name := fmt.Sprintf("%s--project-%d__project.md", id, i)
+			writeNote(t, filepath.Join(dir, sub), name,
+				fmt.Sprintf("---\ntitle: Project %d\nindex_id: %d\ntype: project\n---\n", i, i+1))
+			continue
+		}
+		name := fmt.Sprintf("%s--task-%d__task_bench.md", id, i)
+		writeNote(t, filepath.Join(dir, sub), name, taskNote(i+1, fmt.Sprintf("Task %d", i)))
+	}
+}
+
+// scannedPaths lists the paths of the files in a scan result
+func scannedPaths(result *ScanResult) []string {
+	paths := make([]string, len(result.Files))
+	for i, f := range result.Files {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+func TestScanOrderIsDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	generateNotes(t, dir, 200)
+
+	var want []string
+	for _, workers := range []int{1, 2, 8, 32} {
+		for _, noIndex := range []bool{true, false} {
+			s := &Scanner{BaseDir: dir, Workers: workers, NoIndex: noIndex}
+			result, err := s.Scan()
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			got := scannedPaths(result)
+			if want == nil {
+				want = got
+				continue
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("workers=%d noIndex=%v: order differs from the first scan", workers, noIndex)
+			}
+		}
+	}
+
+	// Tasks come first, each group in path order
+	if len(want) != 200 {
+		t.Fatalf("found %d files, want 200", len(want))
+	}
+	tasks, projects := want[:190], want[190:]
+	for i := 1; i < len(tasks); i++ {
+		if tasks[i-1] >= tasks[i] {
+			t.Fatalf("tasks out of order: %s before %s", tasks[i-1], tasks[i])
+		}
+	}
+	for i := 1; i < len(projects); i++ {
+		if projects[i-1] >= projects[i] {
+			t.Fatalf("projects out of order: %s before %s", projects[i-1], projects[i])
+		}
+	}
+	for _, p := range projects {
+		if !isNoteFile(filepath.Base(p), "project") {
+			t.Fatalf("expected only projects after the tasks, got %s", p)
+		}
+	}
+}
+
+func TestScanCollectsParseErrors(t *testing.T) {
+	dir := t.TempDir()
+	good := writeNote(t, dir, "20240101T090000--good__task.md", taskNote(1, "Good"))
+	broken := writeNote(t, dir, "20240101T090001--broken__task.md", "---\ntitle: [unclosed\n---\n")
+	badName := writeNote(t, dir, "not-a-denote-name__task.md", taskNote(3, "Bad name"))
+	later := writeNote(t, dir, "sub/20240101T090002--later__task.md", taskNote(4, "Later"))
+
+	s := &Scanner{BaseDir: dir, NoIndex: true}
+	result, err := s.Scan()
+	if err != nil {
+		t.Fatalf("Scan aborted: %v", err)
+	}
+
+	// The broken file still loads with defaults; the bad name is skipped
+	want := []string{good, broken, later}
+	if got := scannedPaths(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+
+	if len(result.Errors) != 2 {
+		t.Fatalf("got %d errors, want 2: %v", len(result.Errors), result.Errors)
+	}
+	byPath := make(map[string]ScanError)
+	for _, e := range result.Errors {
+		byPath[e.Path] = e
+	}
+	if e, ok := byPath[broken]; !ok || e.Skipped || e.Err == nil {
+		t.Errorf("broken frontmatter: got %+v, want a warning that keeps the file", e)
+	}
+	if e, ok := byPath[badName]; !ok || !e.Skipped || e.Err == nil {
+		t.Errorf("bad filename: got %+v, want a skipped file", e)
+	}
+
+	for _, task := range result.Tasks {
+		if task.File.Path == broken && task.Status != TaskStatusOpen {
+			t.Errorf("broken task status = %q, want the default %q", task.Status, TaskStatusOpen)
+		}
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	dir := b.TempDir()
+	generateNotes(b, dir, 10000)
+
+	b.Run("NoIndex", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s := &Scanner{BaseDir: dir, NoIndex: true}
+			if _, err := s.Scan(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Index", func(b *testing.B) {
+		// Warm the index so each iteration measures a cached scan
+		if _, err := NewScanner(dir).Scan(); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := NewScanner(dir).Scan(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -2,8 +2,6 @@ package denote
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -12,6 +10,11 @@ import (
 // Scanner finds and loads Denote files
 type Scanner struct {
 	BaseDir string
+	NoIndex bool         // Parse every file instead of consulting the metadata index
+	Workers int          // Parallel parse workers (0 = number of CPUs)
+	Options *ScanOptions // Which files to read (nil = DefaultScanOptions)
+
+	index *Index
 }
 
 // NewScanner creates a new scanner for the given directory
@@ -22,52 +25,68 @@ func NewScanner(dir string) *Scanner {
 // FindAllTaskAndProjectFiles finds all task and project files in the directory
 // This is primarily used for completion and scanning operations
 func (s *Scanner) FindAllTaskAndProjectFiles() ([]File, error) {
-	var allFiles []File
-	
-	// Find task files
-	taskPattern := filepath.Join(s.BaseDir, "*__task*.md")
-	taskPaths, err := filepath.Glob(taskPattern)
+	result, err := s.Scan()
 	if err != nil {
-		return nil, fmt.Errorf("failed to glob task files: %w", err)
+		return nil, err
 	}
-	
-	// Find project files
-	projectPattern := filepath.Join(s.BaseDir, "*__project*.md")
-	projectPaths, err := filepath.Glob(projectPattern)
-	if err != nil {
-		return nil, fmt.Errorf("failed to glob project files: %w", err)
+	return result.Files, nil
+}
+
+// LoadTask loads a task, using the metadata index unless disabled
+func (s *Scanner) LoadTask(path string) (*Task, error) {
+	if idx := s.metadataIndex(); idx != nil {
+		return idx.LoadTask(path)
 	}
+	return ParseTaskFile(path)
+}
+
+// LoadProject loads a project, using the metadata index unless disabled
+func (s *Scanner) LoadProject(path string) (*Project, error) {
+	if idx := s.metadataIndex(); idx != nil {
+		return idx.LoadProject(path)
+	}
+	return ParseProjectFile(path)
+}
+
+// LoadMetadata loads task and project metadata for files, keyed by path,
+// for use with SortTaskFiles
+func (s *Scanner) LoadMetadata(files []File) (map[string]*Task, map[string]*Project) {
+	taskMeta := make(map[string]*Task)
+	projectMeta := make(map[string]*Project)
 	
-	// Combine paths
-	allPaths := append(taskPaths, projectPaths...)
-	
-	parser := NewParser()
-	for _, path := range allPaths {
-		// Parse filename
-		file, err := parser.ParseFilename(filepath.Base(path))
-		if err != nil {
-			// Skip non-Denote files
-			continue
-		}
-		
-		file.Path = path
-		
-		// Get file modification time
-		if info, err := os.Stat(path); err == nil {
-			file.ModTime = info.ModTime()
-		}
-		
-		// Try to get title from frontmatter
-		if metadata, err := parser.ParseFrontmatter(path); err == nil && metadata != nil {
-			if title, ok := metadata["title"].(string); ok && title != "" {
-				file.Title = title
+	for _, file := range files {
+		if file.IsTask() {
+			if task, err := s.LoadTask(file.Path); err == nil {
+				taskMeta[file.Path] = task
+			}
+		} else if file.IsProject() {
+			if project, err := s.LoadProject(file.Path); err == nil {
+				projectMeta[file.Path] = project
 			}
 		}
-		
-		allFiles = append(allFiles, *file)
 	}
 	
-	return allFiles, nil
+	s.SaveIndex()
+	return taskMeta, projectMeta
+}
+
+// SaveIndex writes any metadata index changes to disk. Errors are ignored
+// since the index is only a cache.
+func (s *Scanner) SaveIndex() {
+	if s.index != nil {
+		s.index.Save()
+	}
+}
+
+// metadataIndex lazily loads the index for the scanner's directory
+func (s *Scanner) metadataIndex() *Index {
+	if s.NoIndex {
+		return nil
+	}
+	if s.index == nil {
+		s.index = LoadIndex(s.BaseDir)
+	}
+	return s.index
 }
 
 // FindAllNotes is deprecated - use FindAllTaskAndProjectFiles instead
@@ -76,44 +95,43 @@ func (s *Scanner) FindAllNotes() ([]File, error) {
 	return s.FindAllTaskAndProjectFiles()
 }
 
-// FindTasks finds all task files in the directory
+// FindTasks finds all task files in the directory. Files that fail to
+// parse are skipped; use Scan to get the errors.
 func (s *Scanner) FindTasks() ([]*Task, error) {
-	pattern := filepath.Join(s.BaseDir, "*__task*.md")
-	files, err := filepath.Glob(pattern)
+	files, _, err := s.findNotes()
 	if err != nil {
-		return nil, fmt.Errorf("failed to glob task files: %w", err)
+		return nil, fmt.Errorf("failed to find task files: %w", err)
 	}
 
 	var tasks []*Task
-	for _, file := range files {
-		task, err := ParseTaskFile(file)
-		if err != nil {
-			// Skip files that fail to parse
-			continue
+	for _, item := range s.loadTasks(files) {
+		if item.err == nil {
+			tasks = append(tasks, item.task)
 		}
-		tasks = append(tasks, task)
 	}
+	s.SaveIndex()
+
+	// Compute blocked state; cycles are reported by ResolveDependencies callers
+	ResolveDependencies(tasks)
 
 	return tasks, nil
 }
 
-// FindProjects finds all project files in the directory
+// FindProjects finds all project files in the directory. Files that fail to
+// parse are skipped; use Scan to get the errors.
 func (s *Scanner) FindProjects() ([]*Project, error) {
-	pattern := filepath.Join(s.BaseDir, "*__project*.md")
-	files, err := filepath.Glob(pattern)
+	_, files, err := s.findNotes()
 	if err != nil {
-		return nil, fmt.Errorf("failed to glob project files: %w", err)
+		return nil, fmt.Errorf("failed to find project files: %w", err)
 	}
 
 	var projects []*Project
-	for _, file := range files {
-		project, err := ParseProjectFile(file)
-		if err != nil {
-			// Skip files that fail to parse
-			continue
+	for _, item := range s.loadProjects(files) {
+		if item.err == nil {
+			projects = append(projects, item.project)
 		}
-		projects = append(projects, project)
 	}
+	s.SaveIndex()
 
 	return projects, nil
 }
@@ -124,8 +142,8 @@ func SortTasks(tasks []*Task, sortBy string, reverse bool) {
 	case "priority":
 		sort.Slice(tasks, func(i, j int) bool {
 			// P1 < P2 < P3 < no priority
-			pi := priorityValue(tasks[i].Priority)
-			pj := priorityValue(tasks[j].Priority)
+			pi := PriorityValue(tasks[i].Priority)
+			pj := PriorityValue(tasks[j].Priority)
 			if pi != pj {
 				return pi < pj
 			}
@@ -154,7 +172,7 @@ func SortTasks(tasks []*Task, sortBy string, reverse bool) {
 				return si < sj
 			}
 			// Secondary sort by priority
-			return priorityValue(tasks[i].Priority) < priorityValue(tasks[j].Priority)
+			return PriorityValue(tasks[i].Priority) < PriorityValue(tasks[j].Priority)
 		})
 	
 	case "id":
@@ -182,7 +200,8 @@ func SortTasks(tasks []*Task, sortBy string, reverse bool) {
 
 // Helper functions for sorting
 
-func priorityValue(p string) int {
+// PriorityValue ranks a priority for sorting: p1 first, no priority last
+func PriorityValue(p string) int {
 	switch p {
 	case PriorityP1:
 		return 1
@@ -524,6 +543,22 @@ func FilterTasks(tasks []*Task, filterType string, filterValue string) []*Task {
 				filtered = append(filtered, task)
 			}
 		}
+		
+	case "blocked":
+		// Unfinished tasks waiting on unfinished dependencies
+		for _, task := range tasks {
+			if task.IsBlocked() && !isFinishedStatus(task.Status) {
+				filtered = append(filtered, task)
+			}
+		}
+		
+	case "ready":
+		// Open tasks with no unfinished dependencies
+		for _, task := range tasks {
+			if !task.IsBlocked() && (task.Status == TaskStatusOpen || task.Status == "") {
+				filtered = append(filtered, task)
+			}
+		}
 	}
 	
 	return filtered
@@ -0,0 +1,105 @@
+package denote
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeLogFormat is the timestamp layout used in time_log entries
+const TimeLogFormat = "2006-01-02T15:04:05Z07:00"
+
+// TimeEntry is one tracked work session on a task. End is empty while the
+// timer is running.
+type TimeEntry struct {
+	Start string `yaml:"start"`
+	End   string `yaml:"end,omitempty"`
+}
+
+// IsRunning returns true if the entry has not been stopped
+func (e TimeEntry) IsRunning() bool {
+	return e.End == ""
+}
+
+// Duration returns the length of the entry. A running entry is measured up
+// to now; entries with unparseable timestamps count as zero.
+func (e TimeEntry) Duration(now time.Time) time.Duration {
+	start, err := time.Parse(TimeLogFormat, e.Start)
+	if err != nil {
+		return 0
+	}
+	end := now
+	if !e.IsRunning() {
+		end, err = time.Parse(TimeLogFormat, e.End)
+		if err != nil {
+			return 0
+		}
+	}
+	if end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// RunningTimer returns the task's running time entry, or nil if no timer
+// is running
+func (m *TaskMetadata) RunningTimer() *TimeEntry {
+	for i := range m.TimeLog {
+		if m.TimeLog[i].IsRunning() {
+			return &m.TimeLog[i]
+		}
+	}
+	return nil
+}
+
+// TrackedTime returns the total time logged on the task, including any
+// running timer measured up to now
+func (m *TaskMetadata) TrackedTime(now time.Time) time.Duration {
+	var total time.Duration
+	for _, e := range m.TimeLog {
+		total += e.Duration(now)
+	}
+	return total
+}
+
+// StartTimer appends a running time entry. It fails if a timer is already
+// running on the task.
+func (m *TaskMetadata) StartTimer(now time.Time) error {
+	if m.RunningTimer() != nil {
+		return fmt.Errorf("timer already running")
+	}
+	m.TimeLog = append(m.TimeLog, TimeEntry{Start: now.Format(TimeLogFormat)})
+	return nil
+}
+
+// StopTimer closes the running time entry and returns its duration. It
+// fails if no timer is running on the task.
+func (m *TaskMetadata) StopTimer(now time.Time) (time.Duration, error) {
+	entry := m.RunningTimer()
+	if entry == nil {
+		return 0, fmt.Errorf("no timer running")
+	}
+	entry.End = now.Format(TimeLogFormat)
+	return entry.Duration(now), nil
+}
+
+// FindRunningTimers returns the tasks that have a running timer
+func FindRunningTimers(tasks []*Task) []*Task {
+	var running []*Task
+	for _, t := range tasks {
+		if t.RunningTimer() != nil {
+			running = append(running, t)
+		}
+	}
+	return running
+}
+
+// FormatDuration renders a duration as hours and minutes, e.g. "1h 25m"
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", hours, minutes)
+}
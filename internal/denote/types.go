@@ -109,6 +109,13 @@ type TaskMetadata struct {
 	ProjectID string   `yaml:"project_id,omitempty"` // Denote ID of project (v2.0.0)
 	Area      string   `yaml:"area,omitempty"`      // Life context
 	Assignee  string   `yaml:"assignee,omitempty"`  // Person responsible
+	Recurrence string  `yaml:"recurrence,omitempty"` // e.g. "every 2w", "3d after done"
+	Created   string   `yaml:"created,omitempty"`   // Creation time when it predates the Denote ID (imports)
+	Completed string   `yaml:"completed,omitempty"` // When last marked done, in TimeLogFormat
+	UUID      string   `yaml:"uuid,omitempty"`      // Taskwarrior UUID of imported tasks
+	DependsOn []string `yaml:"depends_on,omitempty"` // Denote IDs of tasks that must finish first
+	TimeLog   []TimeEntry `yaml:"time_log,omitempty"` // Tracked work sessions
+	TodoTxt   []string `yaml:"todotxt,omitempty"`   // Imported todo.txt +projects and key:value tags with no field
 	Tags      []string `yaml:"tags,omitempty"`      // Additional tags beyond filename
 }
 
@@ -129,8 +136,10 @@ type ProjectMetadata struct {
 type Task struct {
 	File
 	TaskMetadata
-	ModTime time.Time
-	Content string // Full file content
+	ModTime   time.Time
+	Content   string          // Full file content
+	Checklist []ChecklistItem // "- [ ]" items in the body
+	BlockedBy []string        // Denote IDs of unfinished dependencies (computed by ResolveDependencies)
 }
 
 // Project combines File info with ProjectMetadata
@@ -175,6 +184,45 @@ func IsValidTaskStatus(status string) bool {
 	return false
 }
 
+// SetStatus changes a task's status, recording when it was completed.
+// Moving a task out of done clears the completion time.
+func (m *TaskMetadata) SetStatus(status string, now time.Time) {
+	switch {
+	case status != TaskStatusDone:
+		m.Completed = ""
+	case m.Status != TaskStatusDone || m.Completed == "":
+		m.Completed = now.Format(TimeLogFormat)
+	}
+	m.Status = status
+}
+
+// CompletedTime returns when a done task was completed, if recorded. Dates
+// without a time, as imported from other formats, are read as midnight.
+func (m *TaskMetadata) CompletedTime() (time.Time, bool) {
+	return parseTimestamp(m.Completed)
+}
+
+// CreatedAt returns when the task was created: the created field of
+// imported tasks, otherwise the time in its Denote ID
+func (t *Task) CreatedAt() (time.Time, bool) {
+	if created, ok := parseTimestamp(t.TaskMetadata.Created); ok {
+		return created, true
+	}
+	created, err := time.ParseInLocation("20060102T150405", t.File.ID, time.Local)
+	return created, err == nil
+}
+
+// parseTimestamp parses a TimeLogFormat timestamp or a YYYY-MM-DD date,
+// read as midnight
+func parseTimestamp(s string) (time.Time, bool) {
+	for _, layout := range []string{TimeLogFormat, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
 // IsValidProjectStatus checks if a status is valid for projects
 func IsValidProjectStatus(status string) bool {
 	switch status {
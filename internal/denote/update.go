@@ -2,9 +2,9 @@ package denote
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // UpdateTaskStatus updates the status field in a task file's frontmatter
@@ -13,22 +13,18 @@ func UpdateTaskStatus(filepath string, newStatus string) error {
 	if !IsValidTaskStatus(newStatus) {
 		return fmt.Errorf("invalid status: %s", newStatus)
 	}
-	
-	// Read file
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	// Update status in frontmatter
-	updated := updateFrontmatterField(string(content), "status", newStatus)
-	
-	// Write back
-	if err := os.WriteFile(filepath, []byte(updated), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-	
-	return nil
+
+	return RewriteFile(filepath, func(content string) (string, error) {
+		// Update status in frontmatter, recording or clearing the completion
+		// time as the task moves into or out of done
+		var meta TaskMetadata
+		if fm, err := ParseFrontmatterFile([]byte(content)); err == nil {
+			meta, _ = fm.Metadata.(TaskMetadata)
+		}
+		meta.SetStatus(newStatus, time.Now())
+		updated := updateFrontmatterField(content, "status", newStatus)
+		return updateFrontmatterField(updated, "completed", meta.Completed), nil
+	})
 }
 
 // UpdateTaskPriority updates the priority field in a task file's frontmatter
@@ -37,79 +33,23 @@ func UpdateTaskPriority(filepath string, newPriority string) error {
 	if newPriority != "" && !IsValidPriority(newPriority) {
 		return fmt.Errorf("invalid priority: %s", newPriority)
 	}
-	
-	// Read file
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	// Update or add priority in frontmatter
-	updated := updateFrontmatterField(string(content), "priority", newPriority)
-	
-	// Write back
-	if err := os.WriteFile(filepath, []byte(updated), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-	
-	return nil
+
+	return setFrontmatterField(filepath, "priority", newPriority)
 }
 
 // UpdateTaskProjectID updates the project_id field in a task file's frontmatter (v2.0.0)
 func UpdateTaskProjectID(filepath string, projectID string) error {
-	// Read file
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	// Update or add project_id in frontmatter
-	updated := updateFrontmatterField(string(content), "project_id", projectID)
-	
-	// Write back
-	if err := os.WriteFile(filepath, []byte(updated), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-	
-	return nil
+	return setFrontmatterField(filepath, "project_id", projectID)
 }
 
 // UpdateTaskDueDate updates the due_date field in a task file's frontmatter
 func UpdateTaskDueDate(filepath string, dueDate string) error {
-	// Read file
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	// Update or add due_date in frontmatter
-	updated := updateFrontmatterField(string(content), "due_date", dueDate)
-	
-	// Write back
-	if err := os.WriteFile(filepath, []byte(updated), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-	
-	return nil
+	return setFrontmatterField(filepath, "due_date", dueDate)
 }
 
 // UpdateTaskStartDate updates the start_date field in a task file's frontmatter
 func UpdateTaskStartDate(filepath string, startDate string) error {
-	// Read file
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	// Update or add start_date in frontmatter
-	updated := updateFrontmatterField(string(content), "start_date", startDate)
-	
-	// Write back
-	if err := os.WriteFile(filepath, []byte(updated), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-	
-	return nil
+	return setFrontmatterField(filepath, "start_date", startDate)
 }
 
 // UpdateTaskEstimate updates the estimate field in a task file's frontmatter
@@ -118,112 +58,91 @@ func UpdateTaskEstimate(filepath string, estimate int) error {
 	if estimate != 0 && !IsValidEstimate(estimate) {
 		return fmt.Errorf("invalid estimate: %d (must be 0, 1, 2, 3, 5, 8, or 13)", estimate)
 	}
-	
-	// Read file
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	// Update or add estimate in frontmatter
+
 	value := ""
 	if estimate > 0 {
 		value = fmt.Sprintf("%d", estimate)
 	}
-	updated := updateFrontmatterField(string(content), "estimate", value)
-	
-	// Write back
-	if err := os.WriteFile(filepath, []byte(updated), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-	
-	return nil
+	return setFrontmatterField(filepath, "estimate", value)
 }
 
 // UpdateTaskArea updates the area field in a task file's frontmatter
 func UpdateTaskArea(filepath string, area string) error {
-	// Read file
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	// Update or add area in frontmatter
-	updated := updateFrontmatterField(string(content), "area", area)
-	
-	// Write back
-	if err := os.WriteFile(filepath, []byte(updated), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-	
-	return nil
+	return setFrontmatterField(filepath, "area", area)
 }
 
 // UpdateTaskTags updates the tags field in a task file's frontmatter
 func UpdateTaskTags(filepath string, tags []string) error {
-	// Read file
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	// Parse frontmatter to update tags as YAML array
-	contentStr := string(content)
-	if !strings.HasPrefix(contentStr, "---\n") {
-		return fmt.Errorf("no frontmatter found")
-	}
-	
-	// Use robust frontmatter parsing
-	lines := strings.Split(contentStr, "\n")
-	frontmatterEndLine := -1
-	inFrontmatter := false
-	
-	for i, line := range lines {
-		if i == 0 && line == "---" {
-			inFrontmatter = true
-			continue
+	return RewriteFile(filepath, func(contentStr string) (string, error) {
+		// Other syntaxes have no multi-line lists to clean up
+		if codec, _ := detectCodec(strings.Split(contentStr, "\n")); codec != nil {
+			if _, ok := codec.(yamlCodec); !ok {
+				return codec.SetField(contentStr, "tags", strings.Join(tags, " ")), nil
+			}
 		}
-		
-		if inFrontmatter && line == "---" {
-			possibleYAML := strings.Join(lines[1:i], "\n")
-			if looksLikeYAML(possibleYAML) {
-				frontmatterEndLine = i
-				break
+
+		// Parse frontmatter to update tags as YAML array
+		if !strings.HasPrefix(contentStr, "---\n") {
+			return "", fmt.Errorf("no frontmatter found")
+		}
+
+		// Use robust frontmatter parsing
+		lines := strings.Split(contentStr, "\n")
+		frontmatterEndLine := -1
+		inFrontmatter := false
+
+		for i, line := range lines {
+			if i == 0 && line == "---" {
+				inFrontmatter = true
+				continue
+			}
+
+			if inFrontmatter && line == "---" {
+				possibleYAML := strings.Join(lines[1:i], "\n")
+				if looksLikeYAML(possibleYAML) {
+					frontmatterEndLine = i
+					break
+				}
 			}
 		}
-	}
-	
-	if frontmatterEndLine == -1 {
-		return fmt.Errorf("frontmatter not properly closed")
-	}
-	
-	frontmatter := strings.Join(lines[1:frontmatterEndLine], "\n")
-	rest := strings.Join(lines[frontmatterEndLine+1:], "\n")
-	
-	// Remove existing tags field
-	tagPattern := regexp.MustCompile(`(?m)^tags:.*\n(?:  - .*\n)*`)
-	frontmatter = tagPattern.ReplaceAllString(frontmatter, "")
-	
-	// Add new tags
-	if len(tags) > 0 {
-		tagsYAML := "tags:\n"
-		for _, tag := range tags {
-			tagsYAML += fmt.Sprintf("  - %s\n", tag)
+
+		if frontmatterEndLine == -1 {
+			return "", fmt.Errorf("frontmatter not properly closed")
 		}
-		frontmatter = strings.TrimRight(frontmatter, "\n") + "\n" + tagsYAML
-	}
-	
-	// Write back
-	updated := "---\n" + frontmatter + "---\n" + rest
-	if err := os.WriteFile(filepath, []byte(updated), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-	
-	return nil
+
+		// Keep a trailing newline so a tags list at the end is fully matched
+		frontmatter := strings.Join(lines[1:frontmatterEndLine], "\n") + "\n"
+		rest := strings.Join(lines[frontmatterEndLine+1:], "\n")
+
+		// Remove existing tags field
+		tagPattern := regexp.MustCompile(`(?m)^tags:.*\n(?:[ \t]*- .*\n)*`)
+		frontmatter = tagPattern.ReplaceAllString(frontmatter, "")
+
+		// Add new tags
+		if len(tags) > 0 {
+			tagsYAML := "tags:\n"
+			for _, tag := range tags {
+				tagsYAML += fmt.Sprintf("  - %s\n", tag)
+			}
+			frontmatter = strings.TrimRight(frontmatter, "\n") + "\n" + tagsYAML
+		}
+
+		return "---\n" + frontmatter + "---\n" + rest, nil
+	})
 }
 
-// updateFrontmatterField updates or adds a field in YAML frontmatter
+// updateFrontmatterField updates or adds a field in a file's frontmatter,
+// in whichever syntax the file uses
 func updateFrontmatterField(content, field, value string) string {
+	codec, _ := detectCodec(strings.Split(content, "\n"))
+	if codec == nil {
+		codec = yamlCodec{}
+	}
+	return codec.SetField(content, field, value)
+}
+
+// setYAMLField updates or adds a field in YAML frontmatter
+func setYAMLField(content, field, value string) string {
 	// Check if file has frontmatter
 	if !strings.HasPrefix(content, "---\n") {
 		// Add frontmatter
@@ -268,13 +187,13 @@ func updateFrontmatterField(content, field, value string) string {
 	
 	// Try to update existing field
 	fieldPattern := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(field) + `:\s*.*$`)
-	if fieldPattern.MatchString(frontmatter) {
+	if value == "" {
+		// An empty value removes the field, with any indented lines that
+		// continue it
+		frontmatter = removeYAMLField(frontmatter, field)
+	} else if fieldPattern.MatchString(frontmatter) {
 		// Update existing field
-		newValue := field + ": " + formattedValue
-		if value == "" {
-			newValue = field + ":"
-		}
-		frontmatter = fieldPattern.ReplaceAllString(frontmatter, newValue)
+		frontmatter = fieldPattern.ReplaceAllString(frontmatter, field+": "+formattedValue)
 	} else {
 		// Add new field
 		if !strings.HasSuffix(frontmatter, "\n") {
@@ -293,6 +212,23 @@ func updateFrontmatterField(content, field, value string) string {
 	return result
 }
 
+// removeYAMLField removes a top-level key from YAML frontmatter text
+func removeYAMLField(frontmatter, field string) string {
+	lines := strings.Split(frontmatter, "\n")
+	var kept []string
+	removing := false
+	for _, line := range lines {
+		if removing && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "- ")) {
+			continue
+		}
+		removing = strings.HasPrefix(line, field+":")
+		if !removing {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
 // looksLikeYAML checks if content appears to be valid YAML frontmatter
 func looksLikeYAML(content string) bool {
 	if content == "" {
@@ -417,28 +353,7 @@ func BulkUpdateTaskStatus(filepaths []string, newStatus string) error {
 
 // UpdateProjectFile updates a project file with new metadata
 func UpdateProjectFile(path string, metadata ProjectMetadata) error {
-	// Read the current file
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-
-	// Parse existing frontmatter to get the content
-	fm, err := ParseFrontmatterFile(content)
-	if err != nil {
-		return fmt.Errorf("failed to parse frontmatter: %w", err)
-	}
-
-	// Write updated content
-	newContent, err := WriteFrontmatterFile(metadata, fm.Content)
-	if err != nil {
-		return fmt.Errorf("failed to write frontmatter: %w", err)
-	}
-
-	// Write to file
-	if err := os.WriteFile(path, newContent, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	return nil
-}
\ No newline at end of file
+	return ModifyFrontmatter(path, func(interface{}) (interface{}, error) {
+		return metadata, nil
+	})
+}
@@ -0,0 +1,173 @@
+package denote
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ScanOptions controls which files under the notes directory a Scanner
+// reads. Patterns are globs matched against paths relative to the notes
+// directory, using "/" as separator. A pattern without a "/" matches any
+// single file or directory name; one with a "/" matches the relative path
+// of a file or of a directory above it. Either way a matching directory
+// covers everything beneath it, so "archive" skips every archive folder
+// and "work/done" only the one.
+type ScanOptions struct {
+	Recursive      bool     // Descend into subdirectories
+	Include        []string // If set, only files matching one of these are read
+	Exclude        []string // Files and directories matching any of these are skipped
+	FollowSymlinks bool     // Descend into symlinked directories
+}
+
+// DefaultScanOptions are used by scanners without Options of their own.
+// The CLI sets them from the [scan] section of the config.
+var DefaultScanOptions = ScanOptions{Recursive: true}
+
+// options returns the scan options in effect for s
+func (s *Scanner) options() ScanOptions {
+	if s.Options != nil {
+		return *s.Options
+	}
+	return DefaultScanOptions
+}
+
+// findNotes finds task and project files in every supported file type,
+// each sorted by path. Hidden directories are never entered.
+func (s *Scanner) findNotes() (tasks, projects []string, err error) {
+	w := &noteWalker{
+		opts:    s.options(),
+		visited: make(map[string]bool),
+	}
+	if err := w.walk(s.BaseDir, ""); err != nil {
+		return nil, nil, err
+	}
+
+	sort.Strings(w.tasks)
+	sort.Strings(w.projects)
+	return w.tasks, w.projects, nil
+}
+
+// noteWalker collects note paths while walking the notes directory
+type noteWalker struct {
+	opts     ScanOptions
+	visited  map[string]bool // Real paths of directories walked, to stop symlink loops
+	tasks    []string
+	projects []string
+}
+
+// walk visits dir, whose path relative to the notes directory is rel. Only
+// the notes directory itself must be readable; subdirectories that can't
+// be read are skipped.
+func (w *noteWalker) walk(dir, rel string) error {
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		if w.visited[real] {
+			return nil
+		}
+		w.visited[real] = true
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if rel == "" && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		full := filepath.Join(dir, name)
+		childRel := path.Join(rel, name)
+
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			info, err := os.Stat(full)
+			if err != nil {
+				continue // Broken link
+			}
+			if info.IsDir() && !w.opts.FollowSymlinks {
+				continue
+			}
+			isDir = info.IsDir()
+		}
+
+		if isDir {
+			if !w.opts.Recursive || strings.HasPrefix(name, ".") || matchAny(w.opts.Exclude, childRel) {
+				continue
+			}
+			if err := w.walk(full, childRel); err != nil {
+				return err
+			}
+			continue
+		}
+
+		isTask := isNoteFile(name, "task")
+		isProject := !isTask && isNoteFile(name, "project")
+		if !isTask && !isProject {
+			continue
+		}
+		if matchAny(w.opts.Exclude, childRel) {
+			continue
+		}
+		if len(w.opts.Include) > 0 && !matchAny(w.opts.Include, childRel) {
+			continue
+		}
+
+		if isTask {
+			w.tasks = append(w.tasks, full)
+		} else {
+			w.projects = append(w.projects, full)
+		}
+	}
+	return nil
+}
+
+// isNoteFile reports whether a file name has tags starting with keyword
+// and a supported extension
+func isNoteFile(name, keyword string) bool {
+	for _, ext := range NoteExtensions() {
+		if ok, _ := filepath.Match("*__"+keyword+"*"+ext, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny reports whether rel matches any of the patterns
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern matches a scan pattern against a relative path, as
+// described on ScanOptions
+func matchPattern(pattern, rel string) bool {
+	pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+	if pattern == "" {
+		return false
+	}
+
+	parts := strings.Split(rel, "/")
+	if !strings.Contains(pattern, "/") {
+		for _, part := range parts {
+			if ok, _ := path.Match(pattern, part); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 1; i <= len(parts); i++ {
+		if ok, _ := path.Match(pattern, strings.Join(parts[:i], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
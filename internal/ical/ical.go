@@ -0,0 +1,181 @@
+// Package ical reads and writes iCalendar (RFC 5545) data and maps tasks
+// and projects to calendar components
+package ical
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxLineOctets is the longest content line allowed before folding
+const maxLineOctets = 75
+
+// Component is an iCalendar component such as VCALENDAR, VTODO or VEVENT
+type Component struct {
+	Name     string
+	Props    []Property
+	Children []*Component
+}
+
+// Property is a single content line. Value is stored unescaped for TEXT
+// properties set with AddText; other values are written as is.
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// NewComponent creates an empty component
+func NewComponent(name string) *Component {
+	return &Component{Name: name}
+}
+
+// Add appends a property with a raw value. Params are given as
+// alternating names and values, e.g. Add("DUE", "20250716", "VALUE", "DATE").
+func (c *Component) Add(name, value string, params ...string) {
+	prop := Property{Name: name, Value: value}
+	if len(params) > 1 {
+		prop.Params = make(map[string]string)
+		for i := 0; i+1 < len(params); i += 2 {
+			prop.Params[params[i]] = params[i+1]
+		}
+	}
+	c.Props = append(c.Props, prop)
+}
+
+// AddText appends a TEXT property, escaping the value
+func (c *Component) AddText(name, value string) {
+	c.Add(name, EscapeText(value))
+}
+
+// Remove deletes every property with the given name
+func (c *Component) Remove(name string) {
+	props := c.Props[:0]
+	for _, p := range c.Props {
+		if p.Name != name {
+			props = append(props, p)
+		}
+	}
+	c.Props = props
+}
+
+// Set replaces every property with the given name by a single one, or
+// removes them when value is empty
+func (c *Component) Set(name, value string, params ...string) {
+	c.Remove(name)
+	if value != "" {
+		c.Add(name, value, params...)
+	}
+}
+
+// Get returns the first property with the given name, or nil
+func (c *Component) Get(name string) *Property {
+	for i := range c.Props {
+		if c.Props[i].Name == name {
+			return &c.Props[i]
+		}
+	}
+	return nil
+}
+
+// Value returns the raw value of the named property, or "" if absent
+func (c *Component) Value(name string) string {
+	if p := c.Get(name); p != nil {
+		return p.Value
+	}
+	return ""
+}
+
+// Text returns the unescaped value of the named TEXT property
+func (c *Component) Text(name string) string {
+	return UnescapeText(c.Value(name))
+}
+
+// String renders the component with CRLF line endings and folded lines
+func (c *Component) String() string {
+	var b strings.Builder
+	c.write(&b)
+	return b.String()
+}
+
+// write renders the component and its children
+func (c *Component) write(b *strings.Builder) {
+	writeLine(b, "BEGIN:"+c.Name)
+	for _, p := range c.Props {
+		writeLine(b, p.String())
+	}
+	for _, child := range c.Children {
+		child.write(b)
+	}
+	writeLine(b, "END:"+c.Name)
+}
+
+// String renders the property as an unfolded content line
+func (p Property) String() string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+
+	// Sort parameter names so output is stable
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := p.Params[name]
+		if strings.ContainsAny(value, ":;,") {
+			value = `"` + value + `"`
+		}
+		b.WriteString(";" + name + "=" + value)
+	}
+
+	b.WriteString(":" + p.Value)
+	return b.String()
+}
+
+// writeLine writes a content line, folding it at maxLineOctets without
+// splitting UTF-8 sequences
+func writeLine(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the limit
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line + "\r\n")
+}
+
+// isRuneStart reports whether a byte begins a UTF-8 sequence
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+// EscapeText escapes a TEXT value
+func EscapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// UnescapeText reverses EscapeText
+func UnescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse reads iCalendar data and returns its top-level component, usually
+// a VCALENDAR. Folded lines are joined and both CRLF and bare LF line
+// endings are accepted.
+func Parse(data string) (*Component, error) {
+	var (
+		root  *Component
+		stack []*Component
+	)
+
+	for n, line := range unfoldLines(data) {
+		if line == "" {
+			continue
+		}
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			c := NewComponent(strings.ToUpper(prop.Value))
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, c)
+			} else if root == nil {
+				root = c
+			} else {
+				return nil, fmt.Errorf("line %d: more than one top-level component", n+1)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", n+1, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: property outside of a component", n+1)
+			}
+			c := stack[len(stack)-1]
+			c.Props = append(c.Props, prop)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1].Name)
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no calendar data")
+	}
+	return root, nil
+}
+
+// Find returns all descendants of c (including c) with the given name
+func (c *Component) Find(name string) []*Component {
+	var found []*Component
+	if c.Name == name {
+		found = append(found, c)
+	}
+	for _, child := range c.Children {
+		found = append(found, child.Find(name)...)
+	}
+	return found
+}
+
+// unfoldLines splits data into content lines, joining continuation lines
+// (those starting with a space or tab) onto the previous line
+func unfoldLines(data string) []string {
+	raw := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+
+	var lines []string
+	for _, line := range raw {
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseProperty parses a content line into a property. Parameter values
+// may be quoted; property and parameter names are upper-cased.
+func parseProperty(line string) (Property, error) {
+	var prop Property
+
+	// The name ends at the first ';' or ':'
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return prop, fmt.Errorf("malformed content line %q", line)
+	}
+	prop.Name = strings.ToUpper(line[:i])
+	rest := line[i:]
+
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return prop, fmt.Errorf("malformed parameter in %q", line)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return prop, fmt.Errorf("unterminated quoted parameter in %q", line)
+			}
+			value = rest[1 : end+1]
+			rest = rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return prop, fmt.Errorf("malformed parameter in %q", line)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+
+		if prop.Params == nil {
+			prop.Params = make(map[string]string)
+		}
+		prop.Params[name] = value
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return prop, fmt.Errorf("missing value in %q", line)
+	}
+	prop.Value = rest[1:]
+	return prop, nil
+}
@@ -0,0 +1,305 @@
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+const (
+	// ProdID identifies calendars written by denote-tasks
+	ProdID = "-//denote-tasks//denote-tasks//EN"
+
+	// uidDomain is appended to Denote IDs to form UIDs
+	uidDomain = "@denote-tasks"
+
+	// dateFormat is the iCalendar DATE value layout
+	dateFormat = "20060102"
+	// dateTimeFormat is the iCalendar UTC DATE-TIME value layout
+	dateTimeFormat = "20060102T150405Z"
+)
+
+// NewCalendar creates an empty VCALENDAR
+func NewCalendar() *Component {
+	cal := NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", ProdID)
+	cal.Add("CALSCALE", "GREGORIAN")
+	return cal
+}
+
+// UID returns the stable UID for a Denote ID
+func UID(denoteID string) string {
+	return denoteID + uidDomain
+}
+
+// DenoteID extracts the Denote ID from a UID created by UID, or returns ""
+func DenoteID(uid string) string {
+	if !strings.HasSuffix(uid, uidDomain) {
+		return ""
+	}
+	return strings.TrimSuffix(uid, uidDomain)
+}
+
+// TaskStatus maps a task status to a VTODO STATUS
+func TaskStatus(status string) string {
+	switch status {
+	case denote.TaskStatusDone:
+		return "COMPLETED"
+	case denote.TaskStatusDropped:
+		return "CANCELLED"
+	case denote.TaskStatusDelegated:
+		return "IN-PROCESS"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// ProjectStatus maps a project status to a VTODO STATUS
+func ProjectStatus(status string) string {
+	switch status {
+	case denote.ProjectStatusCompleted:
+		return "COMPLETED"
+	case denote.ProjectStatusCancelled:
+		return "CANCELLED"
+	case denote.ProjectStatusActive, "":
+		return "IN-PROCESS"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// Priority maps p1/p2/p3 to the iCalendar PRIORITY scale (1 high, 9 low);
+// no priority maps to 0 (undefined)
+func Priority(priority string) int {
+	switch priority {
+	case denote.PriorityP1:
+		return 1
+	case denote.PriorityP2:
+		return 5
+	case denote.PriorityP3:
+		return 9
+	default:
+		return 0
+	}
+}
+
+// TaskStatusFromICal maps a VTODO STATUS back to a task status. current
+// is kept when it already maps to the same STATUS, so statuses iCalendar
+// can't tell apart (open and paused) survive a round trip.
+func TaskStatusFromICal(status, current string) string {
+	status = strings.ToUpper(status)
+	if TaskStatus(current) == status {
+		return current
+	}
+	switch status {
+	case "COMPLETED":
+		return denote.TaskStatusDone
+	case "CANCELLED":
+		return denote.TaskStatusDropped
+	case "NEEDS-ACTION", "IN-PROCESS":
+		return denote.TaskStatusOpen
+	default:
+		return current
+	}
+}
+
+// PriorityFromICal maps an iCalendar PRIORITY to p1/p2/p3: 1-4 is p1, 5 is
+// p2 and 6-9 is p3. 0 or an invalid value means no priority.
+func PriorityFromICal(value string) string {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	switch {
+	case err != nil || n <= 0 || n > 9:
+		return ""
+	case n < 5:
+		return denote.PriorityP1
+	case n == 5:
+		return denote.PriorityP2
+	default:
+		return denote.PriorityP3
+	}
+}
+
+// DateFromICal converts a DATE or DATE-TIME value to YYYY-MM-DD. UTC times
+// are converted to the local date. Invalid values return "".
+func DateFromICal(value string) string {
+	if t, err := time.Parse(dateTimeFormat, value); err == nil {
+		return t.Local().Format("2006-01-02")
+	}
+	if len(value) >= len(dateFormat) {
+		if t, err := time.Parse(dateFormat, value[:len(dateFormat)]); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+// TaskToVTODO converts a task to a VTODO. projectName, if set, is added to
+// the description.
+func TaskToVTODO(t *denote.Task, projectName string) *Component {
+	todo := NewComponent("VTODO")
+	addCommon(todo, t.File.ID, t.TaskMetadata.Title, t.ModTime, t.TaskMetadata.IndexID)
+	todo.Add("STATUS", TaskStatus(t.TaskMetadata.Status))
+	addDates(todo, "DUE", t.TaskMetadata.StartDate, t.TaskMetadata.DueDate)
+	addTaskDetails(todo, t, projectName)
+	return todo
+}
+
+// TaskToVEVENT converts a task to an all-day VEVENT spanning its start and
+// due dates. Tasks with neither date return nil.
+func TaskToVEVENT(t *denote.Task, projectName string) *Component {
+	start, end, ok := eventSpan(t.TaskMetadata.StartDate, t.TaskMetadata.DueDate)
+	if !ok {
+		return nil
+	}
+
+	event := NewComponent("VEVENT")
+	addCommon(event, t.File.ID, t.TaskMetadata.Title, t.ModTime, t.TaskMetadata.IndexID)
+	event.Add("DTSTART", start, "VALUE", "DATE")
+	event.Add("DTEND", end, "VALUE", "DATE")
+	event.Add("TRANSP", "TRANSPARENT")
+	if t.TaskMetadata.Status == denote.TaskStatusDropped {
+		event.Add("STATUS", "CANCELLED")
+	}
+	addTaskDetails(event, t, projectName)
+	return event
+}
+
+// ProjectToVTODO converts a project to a VTODO
+func ProjectToVTODO(p *denote.Project) *Component {
+	todo := NewComponent("VTODO")
+	addCommon(todo, p.File.ID, p.ProjectMetadata.Title, p.ModTime, p.ProjectMetadata.IndexID)
+	todo.Add("STATUS", ProjectStatus(p.ProjectMetadata.Status))
+	addDates(todo, "DUE", p.ProjectMetadata.StartDate, p.ProjectMetadata.DueDate)
+	addProjectDetails(todo, p)
+	return todo
+}
+
+// ProjectToVEVENT converts a project to an all-day VEVENT spanning its
+// start and due dates. Projects with neither date return nil.
+func ProjectToVEVENT(p *denote.Project) *Component {
+	start, end, ok := eventSpan(p.ProjectMetadata.StartDate, p.ProjectMetadata.DueDate)
+	if !ok {
+		return nil
+	}
+
+	event := NewComponent("VEVENT")
+	addCommon(event, p.File.ID, p.ProjectMetadata.Title, p.ModTime, p.ProjectMetadata.IndexID)
+	event.Add("DTSTART", start, "VALUE", "DATE")
+	event.Add("DTEND", end, "VALUE", "DATE")
+	event.Add("TRANSP", "TRANSPARENT")
+	if p.ProjectMetadata.Status == denote.ProjectStatusCancelled {
+		event.Add("STATUS", "CANCELLED")
+	}
+	addProjectDetails(event, p)
+	return event
+}
+
+// addCommon adds the properties shared by all exported components. DTSTAMP
+// uses the file modification time so regenerating an unchanged directory
+// produces identical output.
+func addCommon(c *Component, denoteID, title string, modTime time.Time, indexID int) {
+	stamp := TimeValue(modTime)
+	c.Add("UID", UID(denoteID))
+	c.Add("DTSTAMP", stamp)
+	c.Add("LAST-MODIFIED", stamp)
+	c.AddText("SUMMARY", title)
+	if indexID > 0 {
+		c.Add("X-DENOTE-INDEX-ID", strconv.Itoa(indexID))
+	}
+}
+
+// addDates adds DTSTART and the end property (DUE for VTODO) as DATE values
+func addDates(c *Component, endProp, startDate, dueDate string) {
+	if d, ok := DateValue(startDate); ok {
+		c.Add("DTSTART", d, "VALUE", "DATE")
+	}
+	if d, ok := DateValue(dueDate); ok {
+		c.Add(endProp, d, "VALUE", "DATE")
+	}
+}
+
+// addTaskDetails adds priority, categories, description and the project link
+func addTaskDetails(c *Component, t *denote.Task, projectName string) {
+	if p := Priority(t.TaskMetadata.Priority); p > 0 {
+		c.Add("PRIORITY", strconv.Itoa(p))
+	}
+	addCategories(c, t.TaskMetadata.Area, t.TaskMetadata.Tags)
+	if projectName != "" {
+		c.AddText("DESCRIPTION", "Project: "+projectName)
+	}
+	if t.TaskMetadata.ProjectID != "" {
+		c.Add("RELATED-TO", UID(t.TaskMetadata.ProjectID))
+	}
+}
+
+// addProjectDetails adds priority and categories for a project
+func addProjectDetails(c *Component, p *denote.Project) {
+	if pr := Priority(p.ProjectMetadata.Priority); pr > 0 {
+		c.Add("PRIORITY", strconv.Itoa(pr))
+	}
+	addCategories(c, p.ProjectMetadata.Area, p.ProjectMetadata.Tags)
+}
+
+// addCategories adds the area followed by non-system tags as CATEGORIES
+func addCategories(c *Component, area string, tags []string) {
+	var categories []string
+	if area != "" {
+		categories = append(categories, EscapeText(area))
+	}
+	for _, tag := range tags {
+		if tag != "task" && tag != "project" && tag != area {
+			categories = append(categories, EscapeText(tag))
+		}
+	}
+	if len(categories) > 0 {
+		c.Add("CATEGORIES", strings.Join(categories, ","))
+	}
+}
+
+// eventSpan returns the DTSTART and exclusive DTEND dates for an all-day
+// event from a start and due date, either of which may be empty
+func eventSpan(startDate, dueDate string) (string, string, bool) {
+	start, hasStart := parseDate(startDate)
+	due, hasDue := parseDate(dueDate)
+	switch {
+	case hasStart && hasDue && !due.Before(start):
+		return start.Format(dateFormat), due.AddDate(0, 0, 1).Format(dateFormat), true
+	case hasDue:
+		return due.Format(dateFormat), due.AddDate(0, 0, 1).Format(dateFormat), true
+	case hasStart:
+		return start.Format(dateFormat), start.AddDate(0, 0, 1).Format(dateFormat), true
+	}
+	return "", "", false
+}
+
+// DateValue converts a YYYY-MM-DD date to an iCalendar DATE value
+func DateValue(date string) (string, bool) {
+	t, ok := parseDate(date)
+	if !ok {
+		return "", false
+	}
+	return t.Format(dateFormat), true
+}
+
+// TimeValue formats a time as a UTC DATE-TIME value
+func TimeValue(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+// ParseTimeValue parses a UTC DATE-TIME value
+func ParseTimeValue(value string) (time.Time, bool) {
+	t, err := time.Parse(dateTimeFormat, value)
+	return t, err == nil
+}
+
+// parseDate parses a YYYY-MM-DD date
+func parseDate(date string) (time.Time, bool) {
+	if date == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02", date)
+	return t, err == nil
+}
@@ -0,0 +1,416 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+// MatchTask reports whether a task matches the query. projects maps
+// project Denote IDs to titles, so project=Title works; dependencies must
+// already be resolved for is:blocked and is:ready.
+func (q *Query) MatchTask(t *denote.Task, projects map[string]string) bool {
+	if q.root == nil {
+		return true
+	}
+	return q.root.eval(taskRecord(t, projects))
+}
+
+// MatchProject reports whether a project matches the query. A project's
+// project field is the project itself.
+func (q *Query) MatchProject(p *denote.Project) bool {
+	if q.root == nil {
+		return true
+	}
+	return q.root.eval(projectRecord(p))
+}
+
+// ProjectTitles maps project Denote IDs to titles for MatchTask
+func ProjectTitles(projects []*denote.Project) map[string]string {
+	titles := make(map[string]string)
+	for _, p := range projects {
+		titles[p.File.ID] = p.ProjectMetadata.Title
+	}
+	return titles
+}
+
+// record holds the fields of a task or project as queries see them
+type record struct {
+	values map[string]string // Scalar fields by name
+	tags   []string
+	flags  map[string]bool // is: values
+}
+
+func taskRecord(t *denote.Task, projects map[string]string) *record {
+	meta := t.TaskMetadata
+	status := meta.Status
+	if status == "" {
+		status = denote.TaskStatusOpen
+	}
+	title := meta.Title
+	if title == "" {
+		title = t.File.Title
+	}
+	finished := status == denote.TaskStatusDone || status == denote.TaskStatusDropped
+
+	r := &record{
+		values: map[string]string{
+			"title":    title,
+			"status":   status,
+			"priority": meta.Priority,
+			"area":     meta.Area,
+			"assignee": meta.Assignee,
+			"project":  meta.ProjectID,
+			"due":      meta.DueDate,
+			"start":    meta.StartDate,
+			"recur":    meta.Recurrence,
+			"type":     denote.TypeTask,
+			"id":       strconv.Itoa(meta.IndexID),
+		},
+		tags: recordTags(t.File.Tags, meta.Tags),
+		flags: map[string]bool{
+			"task":      true,
+			"open":      status == denote.TaskStatusOpen,
+			"blocked":   t.IsBlocked(),
+			"ready":     status == denote.TaskStatusOpen && !t.IsBlocked(),
+			"overdue":   !finished && denote.IsOverdue(meta.DueDate),
+			"recurring": meta.Recurrence != "",
+		},
+	}
+	if meta.Estimate > 0 {
+		r.values["estimate"] = strconv.Itoa(meta.Estimate)
+	}
+	if meta.ProjectID != "" {
+		r.values["project_title"] = projects[meta.ProjectID]
+	}
+	return r
+}
+
+func projectRecord(p *denote.Project) *record {
+	meta := p.ProjectMetadata
+	status := meta.Status
+	if status == "" {
+		status = denote.ProjectStatusActive
+	}
+	title := meta.Title
+	if title == "" {
+		title = p.File.Title
+	}
+	finished := status == denote.ProjectStatusCompleted || status == denote.ProjectStatusCancelled
+
+	return &record{
+		values: map[string]string{
+			"title":         title,
+			"status":        status,
+			"priority":      meta.Priority,
+			"area":          meta.Area,
+			"project":       p.File.ID,
+			"project_title": title,
+			"due":           meta.DueDate,
+			"start":         meta.StartDate,
+			"type":          denote.TypeProject,
+			"id":            strconv.Itoa(meta.IndexID),
+		},
+		tags: recordTags(p.File.Tags, meta.Tags),
+		flags: map[string]bool{
+			"project": true,
+			"open":    status == denote.ProjectStatusActive,
+			"overdue": !finished && denote.IsOverdue(meta.DueDate),
+		},
+	}
+}
+
+// recordTags merges filename and metadata tags, leaving out the task and
+// project keywords, which is:task and is:project cover
+func recordTags(fileTags, metaTags []string) []string {
+	var tags []string
+	for _, tag := range append(append([]string{}, fileTags...), metaTags...) {
+		if tag != denote.TypeTask && tag != denote.TypeProject {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// node is an expression tree node
+type node interface {
+	eval(r *record) bool
+}
+
+type andNode struct{ left, right node }
+type orNode struct{ left, right node }
+type notNode struct{ x node }
+type textNode struct{ text string } // Lowercased
+
+func (n andNode) eval(r *record) bool { return n.left.eval(r) && n.right.eval(r) }
+func (n orNode) eval(r *record) bool  { return n.left.eval(r) || n.right.eval(r) }
+func (n notNode) eval(r *record) bool { return !n.x.eval(r) }
+
+func (n textNode) eval(r *record) bool {
+	return strings.Contains(strings.ToLower(r.values["title"]), n.text)
+}
+
+// fieldKind decides how a field's values compare
+type fieldKind int
+
+const (
+	kindText     fieldKind = iota // Case-insensitive; ~ and : match substrings
+	kindKeyword                   // Case-insensitive; : is equality
+	kindPriority                  // p1 < p2 < p3
+	kindDate                      // YYYY-MM-DD or a relative date
+	kindNumber
+	kindProject // Denote ID or title
+	kindTags
+	kindFlag // is:
+)
+
+// field describes a queryable field
+type field struct {
+	name  string
+	kind  fieldKind
+	valid []string // Allowed values, if restricted
+}
+
+// fields lists queryable fields by name, including aliases
+var fields = map[string]*field{
+	"title":    {name: "title", kind: kindText},
+	"status":   {name: "status", kind: kindKeyword, valid: statuses},
+	"priority": {name: "priority", kind: kindPriority},
+	"area":     {name: "area", kind: kindKeyword},
+	"assignee": {name: "assignee", kind: kindKeyword},
+	"project":  {name: "project", kind: kindProject},
+	"tag":      {name: "tag", kind: kindTags},
+	"tags":     {name: "tag", kind: kindTags},
+	"due":      {name: "due", kind: kindDate},
+	"start":    {name: "start", kind: kindDate},
+	"estimate": {name: "estimate", kind: kindNumber},
+	"id":       {name: "id", kind: kindNumber},
+	"recur":    {name: "recur", kind: kindText},
+	"type":     {name: "type", kind: kindKeyword, valid: []string{denote.TypeTask, denote.TypeProject}},
+	"is":       {name: "is", kind: kindFlag, valid: []string{"task", "project", "open", "blocked", "ready", "overdue", "recurring"}},
+}
+
+// statuses are the task and project status values
+var statuses = []string{
+	denote.TaskStatusOpen, denote.TaskStatusDone, denote.TaskStatusPaused,
+	denote.TaskStatusDelegated, denote.TaskStatusDropped,
+	denote.ProjectStatusActive, denote.ProjectStatusCompleted, denote.ProjectStatusCancelled,
+}
+
+// fieldNames lists the field names for error messages
+func fieldNames() string {
+	var names []string
+	for name := range fields {
+		if name != "tags" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// allows reports whether op can be used with the field
+func (f *field) allows(op string) bool {
+	switch op {
+	case "=", "!=", ":":
+		return true
+	case "~":
+		return f.kind == kindText || f.kind == kindKeyword || f.kind == kindProject || f.kind == kindTags
+	case "<", "<=", ">", ">=":
+		return f.kind == kindPriority || f.kind == kindDate || f.kind == kindNumber
+	}
+	return false
+}
+
+// relativeDatePattern matches a signed offset from today, such as +7d
+var relativeDatePattern = regexp.MustCompile(`^([+-])(\d+)([dwmy])$`)
+
+// compile checks value and builds the comparison node
+func (f *field) compile(op, value string) (node, error) {
+	c := cmpNode{field: f, op: op, value: strings.ToLower(value)}
+	if c.value == "none" && f.kind != kindFlag {
+		if op != "=" && op != "!=" && op != ":" {
+			return nil, fmt.Errorf("none can only be compared with = or !=")
+		}
+		c.none = true
+		return c, nil
+	}
+
+	if len(f.valid) > 0 && op != "~" && !contains(f.valid, c.value) {
+		return nil, fmt.Errorf("invalid %s %q (valid: %s)", f.name, value, strings.Join(f.valid, ", "))
+	}
+
+	switch f.kind {
+	case kindPriority:
+		if priorityRank(c.value) == 0 {
+			return nil, fmt.Errorf("invalid priority %q (valid: p1, p2, p3)", value)
+		}
+	case kindDate:
+		date, err := resolveDate(c.value)
+		if err != nil {
+			return nil, err
+		}
+		c.value = date
+	case kindNumber:
+		n, err := strconv.Atoi(c.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: not a number", f.name, value)
+		}
+		c.number = n
+	}
+	return c, nil
+}
+
+// resolveDate turns a query date into YYYY-MM-DD. Besides what
+// ParseNaturalDate accepts, offsets from today may be signed: +7d, -2w.
+func resolveDate(value string) (string, error) {
+	if m := relativeDatePattern.FindStringSubmatch(value); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		if m[1] == "-" {
+			n = -n
+		}
+		now := time.Now()
+		switch m[3] {
+		case "d":
+			now = now.AddDate(0, 0, n)
+		case "w":
+			now = now.AddDate(0, 0, n*7)
+		case "m":
+			now = now.AddDate(0, n, 0)
+		case "y":
+			now = now.AddDate(n, 0, 0)
+		}
+		return now.Format("2006-01-02"), nil
+	}
+
+	date, err := denote.ParseNaturalDate(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
+
+// cmpNode compares one field with a value
+type cmpNode struct {
+	field  *field
+	op     string
+	value  string // Lowercased; resolved to YYYY-MM-DD for dates
+	number int
+	none   bool // Value was "none": compare against an empty field
+}
+
+func (c cmpNode) eval(r *record) bool {
+	switch c.field.kind {
+	case kindFlag:
+		return c.negate(r.flags[c.value])
+	case kindTags:
+		return c.negate(c.matchTags(r.tags))
+	}
+
+	actual := strings.ToLower(r.values[c.field.name])
+	if c.none {
+		return c.negate(actual == "")
+	}
+
+	switch c.op {
+	case "=", ":", "!=":
+		return c.negate(c.equal(actual, r))
+	case "~":
+		if c.field.kind == kindProject {
+			return strings.Contains(actual, c.value) ||
+				strings.Contains(strings.ToLower(r.values["project_title"]), c.value)
+		}
+		return strings.Contains(actual, c.value)
+	}
+
+	// Ordering comparisons never match an empty field
+	if actual == "" {
+		return false
+	}
+	var diff int
+	switch c.field.kind {
+	case kindPriority:
+		diff = priorityRank(actual) - priorityRank(c.value)
+	case kindNumber:
+		n, _ := strconv.Atoi(actual)
+		diff = n - c.number
+	default:
+		diff = strings.Compare(actual, c.value)
+	}
+	switch c.op {
+	case "<":
+		return diff < 0
+	case "<=":
+		return diff <= 0
+	case ">":
+		return diff > 0
+	default:
+		return diff >= 0
+	}
+}
+
+// negate inverts a match for !=
+func (c cmpNode) negate(match bool) bool {
+	if c.op == "!=" {
+		return !match
+	}
+	return match
+}
+
+// equal handles =, : and != before negation
+func (c cmpNode) equal(actual string, r *record) bool {
+	switch c.field.kind {
+	case kindText:
+		if c.op == ":" {
+			return strings.Contains(actual, c.value)
+		}
+		return actual == c.value
+	case kindNumber:
+		n, err := strconv.Atoi(actual)
+		return err == nil && n == c.number
+	case kindProject:
+		return actual == c.value || strings.ToLower(r.values["project_title"]) == c.value
+	}
+	return actual == c.value
+}
+
+// matchTags reports whether any tag matches, before negation
+func (c cmpNode) matchTags(tags []string) bool {
+	if c.none {
+		return len(tags) == 0
+	}
+	for _, tag := range tags {
+		tag = strings.ToLower(tag)
+		if c.op == "~" && strings.Contains(tag, c.value) || tag == c.value {
+			return true
+		}
+	}
+	return false
+}
+
+// priorityRank orders priorities, 0 for none
+func priorityRank(p string) int {
+	switch p {
+	case denote.PriorityP1:
+		return 1
+	case denote.PriorityP2:
+		return 2
+	case denote.PriorityP3:
+		return 3
+	}
+	return 0
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
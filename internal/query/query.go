@@ -0,0 +1,298 @@
+// Package query parses and evaluates task selection expressions such as
+//
+//	priority<=p2 and (area=work or tag:urgent) and due<+7d and not status:done
+//
+// An expression combines comparisons with and, or, not and parentheses;
+// and binds tighter than or, and terms written side by side are joined
+// with and. A comparison is a field, an operator (=, !=, <, <=, >, >=, :
+// or ~) and a value, quoted if it contains spaces. A bare word matches
+// tasks whose title contains it.
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Query is a parsed expression
+type Query struct {
+	Source string
+	root   node
+}
+
+// ParseError describes where and why an expression failed to parse
+type ParseError struct {
+	Input string
+	Pos   int // Byte offset of the offending token
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Msg, e.Pos+1)
+}
+
+// Caret returns the input with a marker under the offending token
+func (e *ParseError) Caret() string {
+	return e.Input + "\n" + strings.Repeat(" ", e.Pos) + "^"
+}
+
+// Parse parses an expression. An empty expression matches everything.
+func Parse(input string) (*Query, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{input: input, tokens: tokens}
+	q := &Query{Source: input}
+	if p.peek().kind == tokEOF {
+		return q, nil
+	}
+
+	q.root, err = p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+	return q, nil
+}
+
+// IsQuery reports whether input uses query syntax (an operator, a
+// parenthesis or and/or/not) rather than being plain search text
+func IsQuery(input string) bool {
+	tokens, err := lex(input)
+	if err != nil {
+		return true
+	}
+	for _, tok := range tokens {
+		switch {
+		case tok.kind == tokOp, tok.kind == tokLParen, tok.kind == tokRParen:
+			return true
+		case isKeyword(tok, "and"), isKeyword(tok, "or"), isKeyword(tok, "not"):
+			return true
+		}
+	}
+	return false
+}
+
+// tokenKind is the lexical class of a token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// operators in matching order, longest first
+var operators = []string{"!=", "<=", ">=", "=", "<", ">", ":", "~"}
+
+// lex splits input into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == '"' || c == '\'':
+			start := i
+			var b strings.Builder
+			i++
+			for i < len(input) && input[i] != c {
+				if input[i] == '\\' && i+1 < len(input) {
+					i++
+				}
+				b.WriteByte(input[i])
+				i++
+			}
+			if i == len(input) {
+				return nil, &ParseError{Input: input, Pos: start, Msg: "unterminated string"}
+			}
+			i++
+			tokens = append(tokens, token{tokString, b.String(), start})
+		default:
+			if op := operatorAt(input, i); op != "" {
+				tokens = append(tokens, token{tokOp, op, i})
+				i += len(op)
+				continue
+			}
+			if c == '!' {
+				tokens = append(tokens, token{tokWord, "not", i})
+				i++
+				continue
+			}
+			start := i
+			for i < len(input) && !strings.ContainsRune(" \t\n\r()\"'", rune(input[i])) &&
+				operatorAt(input, i) == "" && input[i] != '!' {
+				i++
+			}
+			tokens = append(tokens, token{tokWord, input[start:i], start})
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(input)}), nil
+}
+
+// operatorAt returns the operator starting at input[i], if any
+func operatorAt(input string, i int) string {
+	for _, op := range operators {
+		if strings.HasPrefix(input[i:], op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// parser is a recursive descent parser over tokens:
+//
+//	or     = and { "or" and }
+//	and    = unary { ["and"] unary }
+//	unary  = "not" unary | "(" or ")" | field op value | word
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return &ParseError{Input: p.input, Pos: tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isKeyword reports whether tok is the keyword word, in any case
+func isKeyword(tok token, word string) bool {
+	return tok.kind == tokWord && strings.EqualFold(tok.text, word)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for isKeyword(p.peek(), "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if isKeyword(tok, "and") {
+			p.next()
+		} else if tok.kind == tokEOF || tok.kind == tokRParen || isKeyword(tok, "or") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	tok := p.next()
+	switch {
+	case isKeyword(tok, "not"):
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+
+	case tok.kind == tokLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "expected \")\" but found %s", closing)
+		}
+		return x, nil
+
+	case tok.kind == tokWord && p.peek().kind == tokOp:
+		return p.parseComparison(tok)
+
+	case tok.kind == tokWord && (isKeyword(tok, "and") || isKeyword(tok, "or")):
+		return nil, p.errorf(tok, "expected a condition but found %s", tok)
+
+	case tok.kind == tokWord || tok.kind == tokString:
+		return textNode{strings.ToLower(tok.text)}, nil
+
+	case tok.kind == tokOp:
+		return nil, p.errorf(tok, "missing field before %s", tok)
+
+	default:
+		return nil, p.errorf(tok, "expected a condition but found %s", tok)
+	}
+}
+
+// parseComparison parses the operator and value following a field name
+func (p *parser) parseComparison(name token) (node, error) {
+	f, ok := fields[strings.ToLower(name.text)]
+	if !ok {
+		return nil, p.errorf(name, "unknown field %q (valid: %s)", name.text, fieldNames())
+	}
+
+	op := p.next()
+	if !f.allows(op.text) {
+		return nil, p.errorf(op, "operator %s can't be used with %s", op.text, name.text)
+	}
+
+	value := p.next()
+	if value.kind != tokWord && value.kind != tokString {
+		return nil, p.errorf(value, "missing value after %s%s", name.text, op.text)
+	}
+
+	cmp, err := f.compile(op.text, value.text)
+	if err != nil {
+		return nil, p.errorf(value, "%v", err)
+	}
+	return cmp, nil
+}
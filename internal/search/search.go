@@ -0,0 +1,397 @@
+// Package search is a full-text index over task and project files. It
+// covers titles, tags and the body below the front matter, so log entries
+// and notes can be found, and ranks matches by how often and where the
+// search terms occur.
+//
+// Every term must occur in a document for it to match. A term matches
+// words that start with it, so "meet" finds "meeting"; whole-word matches
+// rank higher.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+// Field is the part of a document a match was found in
+type Field int
+
+const (
+	FieldTitle Field = iota
+	FieldTags
+	FieldBody
+)
+
+// fieldWeights favour matches in titles, then tags, over the body
+var fieldWeights = [...]float64{FieldTitle: 3, FieldTags: 2, FieldBody: 1}
+
+// SnippetWidth is the longest snippet, in runes, Search returns
+const SnippetWidth = 80
+
+// Document is one indexed file
+type Document struct {
+	File  denote.File
+	Title string
+	Tags  []string
+	Body  string // Content without front matter
+
+	bodyLine int // Line of the file the body starts on, less one
+}
+
+// posting records the occurrences of a word in one document
+type posting struct {
+	doc    int
+	counts [3]int // By Field
+}
+
+// Index is an inverted index from words to the documents containing them
+type Index struct {
+	docs     []*Document
+	postings map[string][]posting
+	words    []string // Indexed words, sorted, for prefix lookups
+	sorted   bool
+}
+
+// New creates an empty index
+func New() *Index {
+	return &Index{postings: make(map[string][]posting)}
+}
+
+// Build indexes tasks and projects. Ones loaded without Content, such as
+// from the metadata index, are read from disk; files that can't be read
+// are indexed by title and tags alone.
+func Build(tasks []*denote.Task, projects []*denote.Project) *Index {
+	idx := New()
+	for _, t := range tasks {
+		idx.AddTask(t)
+	}
+	for _, p := range projects {
+		idx.AddProject(p)
+	}
+	return idx
+}
+
+// AddTask indexes a task
+func (idx *Index) AddTask(t *denote.Task) {
+	content := t.Content
+	if content == "" {
+		if parsed, err := denote.ParseTaskFile(t.File.Path); err == nil {
+			content = parsed.Content
+		}
+	}
+	title := t.TaskMetadata.Title
+	if title == "" {
+		title = t.File.Title
+	}
+	idx.addContent(&Document{
+		File:  t.File,
+		Title: title,
+		Tags:  documentTags(t.File.Tags, t.TaskMetadata.Tags),
+	}, content)
+}
+
+// AddProject indexes a project
+func (idx *Index) AddProject(p *denote.Project) {
+	content := p.Content
+	if content == "" {
+		if parsed, err := denote.ParseProjectFile(p.File.Path); err == nil {
+			content = parsed.Content
+		}
+	}
+	title := p.ProjectMetadata.Title
+	if title == "" {
+		title = p.File.Title
+	}
+	idx.addContent(&Document{
+		File:  p.File,
+		Title: title,
+		Tags:  documentTags(p.File.Tags, p.ProjectMetadata.Tags),
+	}, content)
+}
+
+// addContent indexes doc with the body of a file's content
+func (idx *Index) addContent(doc *Document, content string) {
+	lines := strings.Split(content, "\n")
+	doc.bodyLine = denote.FrontmatterLines(lines)
+	doc.Body = strings.Join(lines[doc.bodyLine:], "\n")
+	idx.Add(doc)
+}
+
+// documentTags merges filename and metadata tags without the task and
+// project keywords, which every document of a kind shares
+func documentTags(fileTags, metaTags []string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, tag := range append(append([]string{}, fileTags...), metaTags...) {
+		if tag == denote.TypeTask || tag == denote.TypeProject || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// Add indexes a document
+func (idx *Index) Add(doc *Document) {
+	n := len(idx.docs)
+	idx.docs = append(idx.docs, doc)
+
+	counts := make(map[string]*posting)
+	count := func(text string, field Field) {
+		for _, w := range words(text) {
+			p := counts[w.text]
+			if p == nil {
+				p = &posting{doc: n}
+				counts[w.text] = p
+			}
+			p.counts[field]++
+		}
+	}
+	count(doc.Title, FieldTitle)
+	count(strings.Join(doc.Tags, " "), FieldTags)
+	count(doc.Body, FieldBody)
+
+	for word, p := range counts {
+		if _, ok := idx.postings[word]; !ok {
+			idx.words = append(idx.words, word)
+			idx.sorted = false
+		}
+		idx.postings[word] = append(idx.postings[word], *p)
+	}
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// Result is a matching document
+type Result struct {
+	Doc     *Document
+	Score   float64
+	Snippet Snippet
+}
+
+// Snippet is the text around the best match in a document
+type Snippet struct {
+	Field   Field
+	Line    int      // Line number in the file, from 1; 0 for title and tags
+	Text    string   // At most SnippetWidth runes
+	Matches [][2]int // Byte ranges of matching words within Text
+}
+
+// Search returns the documents containing every term in terms, best
+// first. Ties are broken by title.
+func (idx *Index) Search(terms string) []Result {
+	query := Terms(terms)
+	if len(query) == 0 {
+		return nil
+	}
+	idx.sortWords()
+
+	scores := make(map[int]float64)
+	for i, term := range query {
+		termScores := idx.termScores(term)
+		for doc := range scores {
+			if _, ok := termScores[doc]; !ok {
+				delete(scores, doc)
+			}
+		}
+		for doc, score := range termScores {
+			if i == 0 {
+				scores[doc] = score
+			} else if _, ok := scores[doc]; ok {
+				scores[doc] += score
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for n, score := range scores {
+		doc := idx.docs[n]
+		results = append(results, Result{
+			Doc:     doc,
+			Score:   score,
+			Snippet: snippet(doc, query),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return strings.ToLower(results[i].Doc.Title) < strings.ToLower(results[j].Doc.Title)
+	})
+	return results
+}
+
+// termScores scores each document containing a word starting with term.
+// Rare words count for more, repeats count with diminishing returns and
+// prefix matches count half.
+func (idx *Index) termScores(term string) map[int]float64 {
+	scores := make(map[int]float64)
+	start := sort.SearchStrings(idx.words, term)
+	for _, word := range idx.words[start:] {
+		if !strings.HasPrefix(word, term) {
+			break
+		}
+		postings := idx.postings[word]
+		idf := math.Log(1 + float64(len(idx.docs))/float64(len(postings)))
+		if word != term {
+			idf /= 2
+		}
+		for _, p := range postings {
+			var tf float64
+			for field, c := range p.counts {
+				tf += fieldWeights[field] * float64(c)
+			}
+			scores[p.doc] += idf * tf / (tf + 1.2)
+		}
+	}
+	return scores
+}
+
+// sortWords sorts the word list after additions
+func (idx *Index) sortWords() {
+	if !idx.sorted {
+		sort.Strings(idx.words)
+		idx.sorted = true
+	}
+}
+
+// Terms splits search text into lowercase terms
+func Terms(text string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, w := range words(text) {
+		if !seen[w.text] {
+			seen[w.text] = true
+			terms = append(terms, w.text)
+		}
+	}
+	return terms
+}
+
+// word is a lowercased word and its byte range in the text it came from
+type word struct {
+	text       string
+	start, end int
+}
+
+// words splits text into runs of letters and digits
+func words(text string) []word {
+	var result []word
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			result = append(result, word{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		result = append(result, word{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return result
+}
+
+// matchRanges returns the byte ranges of words in text starting with any
+// of the terms
+func matchRanges(text string, terms []string) [][2]int {
+	var ranges [][2]int
+	for _, w := range words(text) {
+		for _, term := range terms {
+			if strings.HasPrefix(w.text, term) {
+				ranges = append(ranges, [2]int{w.start, w.end})
+				break
+			}
+		}
+	}
+	return ranges
+}
+
+// snippet picks the body line matching the most distinct terms, falling
+// back to the title or tags when the body has no match
+func snippet(doc *Document, terms []string) Snippet {
+	best := Snippet{Field: FieldTitle, Text: doc.Title}
+	bestDistinct := distinctMatches(doc.Title, terms)
+	if bestDistinct == 0 {
+		tags := strings.Join(doc.Tags, ", ")
+		if n := distinctMatches(tags, terms); n > 0 {
+			best = Snippet{Field: FieldTags, Text: tags}
+			bestDistinct = n
+		}
+	}
+
+	if bestDistinct < len(terms) {
+		for i, line := range strings.Split(doc.Body, "\n") {
+			if n := distinctMatches(line, terms); n > bestDistinct {
+				best = Snippet{Field: FieldBody, Line: doc.bodyLine + i + 1, Text: strings.TrimSpace(line)}
+				bestDistinct = n
+				if n == len(terms) {
+					break
+				}
+			}
+		}
+	}
+
+	best.Text = clip(best.Text, terms)
+	best.Matches = matchRanges(best.Text, terms)
+	return best
+}
+
+// distinctMatches counts the terms matching some word in text
+func distinctMatches(text string, terms []string) int {
+	n := 0
+	textWords := words(text)
+	for _, term := range terms {
+		for _, w := range textWords {
+			if strings.HasPrefix(w.text, term) {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
+
+// clip shortens text to SnippetWidth runes, keeping the first match in
+// view and marking cut ends with "…"
+func clip(text string, terms []string) string {
+	if utf8.RuneCountInString(text) <= SnippetWidth {
+		return text
+	}
+
+	runes := []rune(text)
+	first := 0
+	if ranges := matchRanges(text, terms); len(ranges) > 0 {
+		first = utf8.RuneCountInString(text[:ranges[0][0]])
+	}
+
+	// Show some context before the match
+	start := first - SnippetWidth/4
+	if start < 0 {
+		start = 0
+	}
+	end := start + SnippetWidth - 2
+	if end > len(runes) {
+		end = len(runes)
+		start = end - (SnippetWidth - 2)
+	}
+
+	clipped := string(runes[start:end])
+	if start > 0 {
+		clipped = "…" + clipped
+	}
+	if end < len(runes) {
+		clipped += "…"
+	}
+	return clipped
+}
@@ -0,0 +1,119 @@
+package task
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+// UpdateTaskStatus updates a task's status and, when a recurring task is
+// marked done, creates its next instance. The new task is returned, or nil
+// if none was created.
+func UpdateTaskStatus(path string, newStatus string) (*denote.Task, error) {
+	t, err := denote.ParseTaskFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse task: %w", err)
+	}
+	wasDone := t.Status == denote.TaskStatusDone
+
+	if err := denote.UpdateTaskStatus(path, newStatus); err != nil {
+		return nil, err
+	}
+
+	if newStatus != denote.TaskStatusDone || wasDone || t.Recurrence == "" {
+		return nil, nil
+	}
+
+	return CreateNextInstance(t, time.Now())
+}
+
+// CreateNextInstance creates the next occurrence of a recurring task that
+// was completed at the given time, and logs the link in both files
+func CreateNextInstance(t *denote.Task, completed time.Time) (*denote.Task, error) {
+	rule, err := denote.ParseRecurrence(t.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurrence on task %d: %w", t.IndexID, err)
+	}
+
+	dueDate, startDate := nextDates(rule, t, completed)
+
+	// Carry over filename tags other than "task" and keep the file type
+	var tags []string
+	for _, tag := range t.File.Tags {
+		if tag != "task" {
+			tags = append(tags, tag)
+		}
+	}
+
+	dir := filepath.Dir(t.File.Path)
+	next, err := CreateTaskAs(dir, denote.FileTypeOf(t.File.Path), t.TaskMetadata.Title, "", tags, t.Area)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create next instance: %w", err)
+	}
+
+	next.TaskMetadata.Priority = t.Priority
+	next.TaskMetadata.Estimate = t.Estimate
+	next.TaskMetadata.ProjectID = t.ProjectID
+	next.TaskMetadata.Assignee = t.Assignee
+	next.TaskMetadata.Tags = t.TaskMetadata.Tags
+	next.TaskMetadata.Recurrence = t.Recurrence
+	next.TaskMetadata.DueDate = dueDate
+	next.TaskMetadata.StartDate = startDate
+
+	if err := UpdateTaskFile(next.File.Path, next.TaskMetadata); err != nil {
+		return nil, fmt.Errorf("failed to update next instance: %w", err)
+	}
+
+	// Record the link between the two instances
+	if err := denote.AddLogEntry(next.File.Path, fmt.Sprintf("Recurs from task %d (%s)", t.IndexID, t.File.ID)); err != nil {
+		return nil, fmt.Errorf("failed to log recurrence: %w", err)
+	}
+	if err := denote.AddLogEntry(t.File.Path, fmt.Sprintf("Next occurrence: task %d (%s)", next.IndexID, next.File.ID)); err != nil {
+		return nil, fmt.Errorf("failed to log recurrence: %w", err)
+	}
+
+	return denote.ParseTaskFile(next.File.Path)
+}
+
+// nextDates computes the due and start dates of the next instance.
+// Fixed schedules advance from the previous due date (or start date) until
+// past the completion date; relative schedules advance from the completion
+// date. The start date keeps its original offset from the due date.
+func nextDates(rule *denote.Recurrence, t *denote.Task, completed time.Time) (string, string) {
+	due := t.GetParsedDueDate()
+	start := t.GetParsedStartDate()
+	today := time.Date(completed.Year(), completed.Month(), completed.Day(), 0, 0, 0, 0, time.UTC)
+
+	base := due
+	if base == nil {
+		base = start
+	}
+
+	var next time.Time
+	if rule.Relative || base == nil {
+		next = rule.Next(today)
+	} else {
+		next = rule.Next(*base)
+		for !next.After(today) {
+			next = rule.Next(next)
+		}
+	}
+
+	var dueDate, startDate string
+	switch {
+	case due != nil && start != nil:
+		offset := due.Sub(*start)
+		dueDate = next.Format("2006-01-02")
+		startDate = next.Add(-offset).Format("2006-01-02")
+	case due != nil:
+		dueDate = next.Format("2006-01-02")
+	case start != nil:
+		startDate = next.Format("2006-01-02")
+	default:
+		dueDate = next.Format("2006-01-02")
+	}
+
+	return dueDate, startDate
+}
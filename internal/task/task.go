@@ -8,11 +8,22 @@ import (
 	"time"
 
 	"github.com/pdxmph/denote-tasks/internal/denote"
-	"gopkg.in/yaml.v3"
 )
 
 // CreateTask creates a new task file with YAML frontmatter
 func CreateTask(dir, title, content string, tags []string, area string) (*denote.Task, error) {
+	return CreateTaskAs(dir, "", title, content, tags, area)
+}
+
+// CreateTaskAs creates a new task file of a Denote file type
+// ("markdown-yaml", "markdown-toml", "org" or "text"; empty means
+// markdown-yaml)
+func CreateTaskAs(dir, fileType, title, content string, tags []string, area string) (*denote.Task, error) {
+	codec, err := denote.CodecForFileType(fileType)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get ID counter
 	counter, err := denote.GetIDCounter(dir)
 	if err != nil {
@@ -27,7 +38,6 @@ func CreateTask(dir, title, content string, tags []string, area string) (*denote
 
 	// Generate Denote ID
 	now := time.Now()
-	denoteID := now.Format("20060102T150405")
 
 	// Create slug from title
 	slug := titleToSlug(title)
@@ -42,8 +52,14 @@ func CreateTask(dir, title, content string, tags []string, area string) (*denote
 	if len(tags) > 0 {
 		tagStr = "__" + strings.Join(tags, "_")
 	}
-	filename := fmt.Sprintf("%s--%s%s.md", denoteID, slug, tagStr)
-	filepath := filepath.Join(dir, filename)
+	// Hold the directory lock so another process can't take the same
+	// Denote ID between the check and the write
+	lock, err := denote.LockDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+	filepath := uniqueFilePath(dir, now, slug, tagStr, codec.Extension())
 
 	// Create task metadata
 	metadata := denote.TaskMetadata{
@@ -55,23 +71,13 @@ func CreateTask(dir, title, content string, tags []string, area string) (*denote
 	}
 
 	// Build content with frontmatter
-	var builder strings.Builder
-	builder.WriteString("---\n")
-	
-	// Marshal metadata to YAML
-	yamlData, err := yaml.Marshal(metadata)
+	fileContent, err := denote.WriteNewFileAs(codec, filepath, metadata, content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
-	builder.Write(yamlData)
-	builder.WriteString("---\n\n")
-	
-	if content != "" {
-		builder.WriteString(content)
-	}
 
 	// Write file
-	if err := os.WriteFile(filepath, []byte(builder.String()), 0644); err != nil {
+	if err := denote.WriteFileAtomic(filepath, fileContent, 0644); err != nil {
 		return nil, fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -81,6 +87,18 @@ func CreateTask(dir, title, content string, tags []string, area string) (*denote
 
 // CreateProject creates a new project file with YAML frontmatter
 func CreateProject(dir, title, content string, tags []string) (*denote.Project, error) {
+	return CreateProjectAs(dir, "", title, content, tags)
+}
+
+// CreateProjectAs creates a new project file of a Denote file type
+// ("markdown-yaml", "markdown-toml", "org" or "text"; empty means
+// markdown-yaml)
+func CreateProjectAs(dir, fileType, title, content string, tags []string) (*denote.Project, error) {
+	codec, err := denote.CodecForFileType(fileType)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get ID counter
 	counter, err := denote.GetIDCounter(dir)
 	if err != nil {
@@ -95,7 +113,6 @@ func CreateProject(dir, title, content string, tags []string) (*denote.Project,
 
 	// Generate Denote ID
 	now := time.Now()
-	denoteID := now.Format("20060102T150405")
 
 	// Create slug from title
 	slug := titleToSlug(title)
@@ -110,8 +127,14 @@ func CreateProject(dir, title, content string, tags []string) (*denote.Project,
 	if len(tags) > 0 {
 		tagStr = "__" + strings.Join(tags, "_")
 	}
-	filename := fmt.Sprintf("%s--%s%s.md", denoteID, slug, tagStr)
-	filepath := filepath.Join(dir, filename)
+	// Hold the directory lock so another process can't take the same
+	// Denote ID between the check and the write
+	lock, err := denote.LockDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+	filepath := uniqueFilePath(dir, now, slug, tagStr, codec.Extension())
 
 	// Create project metadata
 	metadata := denote.ProjectMetadata{
@@ -122,23 +145,13 @@ func CreateProject(dir, title, content string, tags []string) (*denote.Project,
 	}
 
 	// Build content with frontmatter
-	var builder strings.Builder
-	builder.WriteString("---\n")
-	
-	// Marshal metadata to YAML
-	yamlData, err := yaml.Marshal(metadata)
+	fileContent, err := denote.WriteNewFileAs(codec, filepath, metadata, content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
-	builder.Write(yamlData)
-	builder.WriteString("---\n\n")
-	
-	if content != "" {
-		builder.WriteString(content)
-	}
 
 	// Write file
-	if err := os.WriteFile(filepath, []byte(builder.String()), 0644); err != nil {
+	if err := denote.WriteFileAtomic(filepath, fileContent, 0644); err != nil {
 		return nil, fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -146,6 +159,31 @@ func CreateProject(dir, title, content string, tags []string) (*denote.Project,
 	return denote.ParseProjectFile(filepath)
 }
 
+// uniqueFilePath builds a Denote file path, advancing the timestamp one
+// second at a time if another file already uses the same Denote ID. The
+// directory is listed once, so creating many files in a burst, which walks
+// the timestamp ahead of the clock, doesn't re-read it for every second.
+func uniqueFilePath(dir string, now time.Time, slug, tagStr, ext string) string {
+	used := make(map[string]bool)
+	if f, err := os.Open(dir); err == nil {
+		names, _ := f.Readdirnames(-1)
+		f.Close()
+		for _, name := range names {
+			if id, _, ok := strings.Cut(name, "-"); ok {
+				used[id] = true
+			}
+		}
+	}
+
+	for {
+		denoteID := now.Format("20060102T150405")
+		if !used[denoteID] {
+			return filepath.Join(dir, fmt.Sprintf("%s--%s%s%s", denoteID, slug, tagStr, ext))
+		}
+		now = now.Add(time.Second)
+	}
+}
+
 // FindTaskByID finds a task by its sequential ID
 func FindTaskByID(dir string, id int) (*denote.Task, error) {
 	scanner := denote.NewScanner(dir)
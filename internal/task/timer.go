@@ -0,0 +1,40 @@
+package task
+
+import (
+	"time"
+
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+// StartTimer starts a timer on a task, first stopping any timer running on
+// another task so only one task is tracked at a time. The tasks whose
+// timers were stopped are returned.
+func StartTimer(t *denote.Task, tasks []*denote.Task, now time.Time) ([]*denote.Task, error) {
+	var stopped []*denote.Task
+	for _, other := range denote.FindRunningTimers(tasks) {
+		if other.File.Path == t.File.Path {
+			continue
+		}
+		if _, err := StopTimer(other.File.Path, now); err != nil {
+			return stopped, err
+		}
+		stopped = append(stopped, other)
+	}
+
+	err := ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+		return meta.StartTimer(now)
+	})
+	return stopped, err
+}
+
+// StopTimer stops the running timer on the task at path and returns the
+// length of the session
+func StopTimer(path string, now time.Time) (time.Duration, error) {
+	var elapsed time.Duration
+	err := ModifyTaskFile(path, func(meta *denote.TaskMetadata) error {
+		d, err := meta.StopTimer(now)
+		elapsed = d
+		return err
+	})
+	return elapsed, err
+}
@@ -2,35 +2,43 @@ package task
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/pdxmph/denote-tasks/internal/denote"
 )
 
 // UpdateTaskFile updates the task metadata in a file
 func UpdateTaskFile(path string, metadata denote.TaskMetadata) error {
-	// Read the current file
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
+	return denote.ModifyFrontmatter(path, func(interface{}) (interface{}, error) {
+		return metadata, nil
+	})
+}
 
-	// Parse existing frontmatter to get the content
-	fm, err := denote.ParseFrontmatterFile(content)
-	if err != nil {
-		return fmt.Errorf("failed to parse frontmatter: %w", err)
-	}
+// ModifyTaskFile applies fn to a task's metadata and writes the result,
+// holding the directory lock across the read and the write
+func ModifyTaskFile(path string, fn func(meta *denote.TaskMetadata) error) error {
+	return denote.ModifyFrontmatter(path, func(metadata interface{}) (interface{}, error) {
+		meta, ok := metadata.(denote.TaskMetadata)
+		if !ok {
+			return nil, fmt.Errorf("not a task file")
+		}
+		if err := fn(&meta); err != nil {
+			return nil, err
+		}
+		return meta, nil
+	})
+}
 
-	// Write updated content
-	newContent, err := denote.WriteFrontmatterFile(metadata, fm.Content)
-	if err != nil {
-		return fmt.Errorf("failed to write frontmatter: %w", err)
-	}
-
-	// Write to file
-	if err := os.WriteFile(path, newContent, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	return nil
-}
\ No newline at end of file
+// ModifyProjectFile applies fn to a project's metadata and writes the
+// result, holding the directory lock across the read and the write
+func ModifyProjectFile(path string, fn func(meta *denote.ProjectMetadata) error) error {
+	return denote.ModifyFrontmatter(path, func(metadata interface{}) (interface{}, error) {
+		meta, ok := metadata.(denote.ProjectMetadata)
+		if !ok {
+			return nil, fmt.Errorf("not a project file")
+		}
+		if err := fn(&meta); err != nil {
+			return nil, err
+		}
+		return meta, nil
+	})
+}
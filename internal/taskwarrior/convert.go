@@ -0,0 +1,366 @@
+package taskwarrior
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/task"
+)
+
+// ImportResult lists what Import created, updated and left out
+type ImportResult struct {
+	Tasks    []*denote.Task // Created tasks
+	Updated  []*denote.Task
+	Projects []*denote.Project
+	Skipped  []string // Task descriptions with the reason they were skipped
+}
+
+// taskFields are the task fields a Taskwarrior task sets
+type taskFields struct {
+	Title     string
+	Status    string
+	Priority  string
+	DueDate   string
+	StartDate string
+	ProjectID string
+	Area      string
+	Estimate  int
+	Created   string // entry, in TimeLogFormat
+	Completed string // end, in TimeLogFormat
+}
+
+// apply copies the fields into task metadata
+func (f taskFields) apply(meta *denote.TaskMetadata) {
+	meta.Title = f.Title
+	meta.SetStatus(f.Status, time.Now())
+	if f.Completed != "" {
+		meta.Completed = f.Completed
+	}
+	meta.Priority = f.Priority
+	meta.DueDate = f.DueDate
+	meta.StartDate = f.StartDate
+	meta.ProjectID = f.ProjectID
+	meta.Area = f.Area
+	meta.Estimate = f.Estimate
+	meta.Created = f.Created
+}
+
+// TaskUUID returns the Taskwarrior UUID of a task: the one it was imported
+// with, or one derived from its Denote ID
+func TaskUUID(t *denote.Task) string {
+	if t.TaskMetadata.UUID != "" {
+		return t.TaskMetadata.UUID
+	}
+	return UUID(t.File.ID)
+}
+
+// Import creates task files of fileType in dir for Taskwarrior tasks. Projects become
+// project files linked by project_id, reusing existing projects with the
+// same title; annotations become log entries and dependencies are resolved
+// to Denote IDs. The UUID and entry date are kept in the uuid and created
+// fields. Tasks whose UUID matches an existing task, whether imported
+// before or exported from this directory, update that task instead; their
+// annotations and tags are left alone. Recurring task templates are
+// skipped (their pending instances are imported). On error, the result
+// lists what was done before it.
+func Import(dir, fileType string, twTasks []Task, existing *denote.ScanResult) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	// UUID -> task, for updates and dependencies
+	tasksByUUID := make(map[string]*denote.Task)
+	for _, t := range existing.Tasks {
+		tasksByUUID[TaskUUID(t)] = t
+	}
+
+	projectIDs := make(map[string]string) // Lowercase title -> Denote ID
+	for _, p := range existing.Projects {
+		projectIDs[strings.ToLower(p.ProjectMetadata.Title)] = p.File.ID
+	}
+
+	type imported struct {
+		task    *denote.Task
+		depends Depends
+	}
+	var changed []imported
+
+	for _, tw := range twTasks {
+		switch {
+		case tw.Status == StatusRecurring:
+			result.Skipped = append(result.Skipped, tw.Description+" (recurring template)")
+			continue
+		case strings.TrimSpace(tw.Description) == "":
+			result.Skipped = append(result.Skipped, tw.UUID+" (no description)")
+			continue
+		}
+
+		projectID := ""
+		if tw.Project != "" {
+			key := strings.ToLower(tw.Project)
+			projectID = projectIDs[key]
+			if projectID == "" {
+				p, err := task.CreateProjectAs(dir, fileType, tw.Project, "", nil)
+				if err != nil {
+					return result, fmt.Errorf("failed to create project %s: %w", tw.Project, err)
+				}
+				projectID = p.File.ID
+				projectIDs[key] = projectID
+				result.Projects = append(result.Projects, p)
+			}
+		}
+
+		fields := taskFields{
+			Title:     tw.Description,
+			Status:    Status(tw.Status, tw.DenoteStatus),
+			Priority:  Priority(tw.Priority),
+			DueDate:   Date(tw.Due),
+			StartDate: Date(tw.Scheduled),
+			ProjectID: projectID,
+			Area:      tw.Area,
+			Estimate:  tw.Estimate,
+			Created:   localTime(tw.Entry),
+		}
+		if fields.Status == denote.TaskStatusDone {
+			fields.Completed = localTime(tw.End)
+		}
+
+		if t := tasksByUUID[tw.UUID]; tw.UUID != "" && t != nil {
+			updated, err := updateTask(t, fields)
+			if err != nil {
+				return result, err
+			}
+			if updated {
+				result.Updated = append(result.Updated, t)
+				changed = append(changed, imported{task: t, depends: tw.Depends})
+			} else {
+				result.Skipped = append(result.Skipped, tw.Description+" (unchanged)")
+			}
+			continue
+		}
+
+		var tags []string
+		for _, tag := range tw.Tags {
+			if tag = Tag(tag); tag != "" && tag != "task" && tag != "project" {
+				tags = append(tags, tag)
+			}
+		}
+
+		t, err := task.CreateTaskAs(dir, fileType, tw.Description, annotationLog(tw.Annotations), tags, tw.Area)
+		if err != nil {
+			return result, fmt.Errorf("failed to create task %s: %w", tw.Description, err)
+		}
+
+		err = task.ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+			fields.apply(meta)
+			meta.UUID = tw.UUID
+			return nil
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to update task %s: %w", tw.Description, err)
+		}
+		fields.apply(&t.TaskMetadata)
+		t.TaskMetadata.UUID = tw.UUID
+
+		if tw.UUID != "" {
+			tasksByUUID[tw.UUID] = t
+		}
+		result.Tasks = append(result.Tasks, t)
+		changed = append(changed, imported{task: t, depends: tw.Depends})
+	}
+
+	// Dependencies can point at tasks later in the file, so link them last
+	for _, c := range changed {
+		var dependsOn []string
+		for _, uuid := range c.depends {
+			if t := tasksByUUID[uuid]; t != nil {
+				dependsOn = append(dependsOn, t.File.ID)
+			}
+		}
+		if len(dependsOn) > 0 || len(c.task.TaskMetadata.DependsOn) > 0 {
+			err := task.ModifyTaskFile(c.task.File.Path, func(meta *denote.TaskMetadata) error {
+				meta.DependsOn = dependsOn
+				return nil
+			})
+			if err != nil {
+				return result, fmt.Errorf("failed to link dependencies: %w", err)
+			}
+			c.task.TaskMetadata.DependsOn = dependsOn
+		}
+	}
+
+	return result, nil
+}
+
+// updateTask applies fields to an existing task and reports whether
+// anything changed. Dates the export carried over from the task itself
+// (entry from the Denote ID, end from the completion time) are kept as they
+// are.
+func updateTask(t *denote.Task, fields taskFields) (bool, error) {
+	meta := t.TaskMetadata
+	before := taskFields{
+		Title:     meta.Title,
+		Status:    meta.Status,
+		Priority:  meta.Priority,
+		DueDate:   meta.DueDate,
+		StartDate: meta.StartDate,
+		ProjectID: meta.ProjectID,
+		Area:      meta.Area,
+		Estimate:  meta.Estimate,
+		Created:   meta.Created,
+		Completed: meta.Completed,
+	}
+	if before.Status == "" {
+		before.Status = denote.TaskStatusOpen
+	}
+	if created, ok := t.CreatedAt(); fields.Created == "" || ok && sameSecond(created, fields.Created) {
+		fields.Created = before.Created
+	}
+	if completed, ok := meta.CompletedTime(); fields.Completed == "" || ok && sameSecond(completed, fields.Completed) {
+		fields.Completed = ""
+		if fields.Status == denote.TaskStatusDone {
+			fields.Completed = before.Completed
+		}
+	}
+	if before == fields {
+		return false, nil
+	}
+
+	err := task.ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+		fields.apply(meta)
+		if meta.Status != denote.TaskStatusOpen {
+			meta.StopTimer(time.Now())
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update task %s: %w", fields.Title, err)
+	}
+	fields.apply(&t.TaskMetadata)
+	return true, nil
+}
+
+// localTime converts a Taskwarrior date to a local TimeLogFormat timestamp,
+// or "" if it isn't valid
+func localTime(value string) string {
+	t, err := time.Parse(TimeFormat, value)
+	if err != nil {
+		return ""
+	}
+	return t.Local().Format(denote.TimeLogFormat)
+}
+
+// sameSecond reports whether t is the time of a TimeLogFormat timestamp
+func sameSecond(t time.Time, timestamp string) bool {
+	parsed, err := time.Parse(denote.TimeLogFormat, timestamp)
+	return err == nil && parsed.Equal(t.Truncate(time.Second))
+}
+
+// annotationLog renders annotations as log entries, newest first as
+// AddLogEntry leaves them
+func annotationLog(annotations []Annotation) string {
+	sorted := make([]Annotation, len(annotations))
+	copy(sorted, annotations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Entry > sorted[j].Entry
+	})
+
+	var lines []string
+	for _, a := range sorted {
+		when := time.Now()
+		if t, err := time.Parse(TimeFormat, a.Entry); err == nil {
+			when = t.Local()
+		}
+		message := strings.Join(strings.Fields(a.Description), " ")
+		lines = append(lines, denote.FormatLogEntry(when, message))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Export converts tasks to Taskwarrior format, with project titles as
+// Taskwarrior projects and log entries as annotations. Imported tasks keep
+// their UUID and entry date. existing resolves projects and dependencies.
+func Export(tasks []*denote.Task, existing *denote.ScanResult) ([]Task, error) {
+	projectNames := make(map[string]string) // ID -> Title
+	for _, p := range existing.Projects {
+		projectNames[p.File.ID] = p.ProjectMetadata.Title
+	}
+	uuids := make(map[string]string) // Denote ID -> UUID
+	for _, t := range existing.Tasks {
+		uuids[t.File.ID] = TaskUUID(t)
+	}
+
+	var out []Task
+	for _, t := range tasks {
+		meta := t.TaskMetadata
+		status, denoteStatus := TaskwarriorStatus(meta.Status)
+
+		tw := Task{
+			UUID:         TaskUUID(t),
+			Description:  meta.Title,
+			Status:       status,
+			Modified:     FormatTime(t.ModTime),
+			Priority:     TaskwarriorPriority(meta.Priority),
+			Due:          Timestamp(meta.DueDate),
+			Scheduled:    Timestamp(meta.StartDate),
+			Project:      projectNames[meta.ProjectID],
+			Area:         meta.Area,
+			Estimate:     meta.Estimate,
+			DenoteStatus: denoteStatus,
+		}
+
+		tw.Entry = tw.Modified
+		if created, ok := t.CreatedAt(); ok {
+			tw.Entry = FormatTime(created)
+			// Denote IDs can run ahead of the clock when many files are
+			// created at once; keep modified from preceding entry
+			if created.After(t.ModTime) {
+				tw.Modified = tw.Entry
+			}
+		}
+		// Taskwarrior requires an end date on finished tasks
+		if status == StatusCompleted || status == StatusDeleted {
+			tw.End = tw.Modified
+			if completed, ok := meta.CompletedTime(); ok {
+				tw.End = FormatTime(completed)
+			}
+		}
+
+		seen := map[string]bool{"task": true}
+		for _, tag := range append(append([]string{}, t.File.Tags...), meta.Tags...) {
+			if !seen[tag] {
+				seen[tag] = true
+				tw.Tags = append(tw.Tags, tag)
+			}
+		}
+
+		for _, dep := range meta.DependsOn {
+			uuid := uuids[dep]
+			if uuid == "" {
+				uuid = UUID(dep)
+			}
+			tw.Depends = append(tw.Depends, uuid)
+		}
+
+		content, err := os.ReadFile(t.File.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", t.File.Path, err)
+		}
+		// Oldest first, as Taskwarrior lists them
+		entries := denote.ParseLogEntries(string(content))
+		for i := len(entries) - 1; i >= 0; i-- {
+			tw.Annotations = append(tw.Annotations, Annotation{
+				Entry:       Timestamp(entries[i].Date),
+				Description: entries[i].Message,
+			})
+		}
+
+		out = append(out, tw)
+	}
+	return out, nil
+}
@@ -0,0 +1,231 @@
+// Package taskwarrior converts between Taskwarrior's JSON export format
+// and tasks
+package taskwarrior
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+// TimeFormat is the layout of Taskwarrior dates (always UTC)
+const TimeFormat = "20060102T150405Z"
+
+// Taskwarrior statuses
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusDeleted   = "deleted"
+	StatusWaiting   = "waiting"
+	StatusRecurring = "recurring"
+)
+
+// Annotation is a timestamped note on a Taskwarrior task
+type Annotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+// Task is a task in Taskwarrior's JSON format. Area, Estimate and
+// DenoteStatus are user-defined attributes carrying fields Taskwarrior has
+// no equivalent for, so they survive a round trip.
+type Task struct {
+	UUID        string       `json:"uuid,omitempty"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	Entry       string       `json:"entry,omitempty"`
+	Modified    string       `json:"modified,omitempty"`
+	End         string       `json:"end,omitempty"`
+	Priority    string       `json:"priority,omitempty"`
+	Due         string       `json:"due,omitempty"`
+	Scheduled   string       `json:"scheduled,omitempty"`
+	Project     string       `json:"project,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+	Depends     Depends      `json:"depends,omitempty"`
+
+	Area         string `json:"area,omitempty"`
+	Estimate     int    `json:"estimate,omitempty"`
+	DenoteStatus string `json:"denote_status,omitempty"`
+}
+
+// Depends is a list of UUIDs. Taskwarrior 2.5 writes it as a
+// comma-separated string and 2.6 as an array; both are read, arrays are
+// written.
+type Depends []string
+
+// UnmarshalJSON accepts a string or an array of strings
+func (d *Depends) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*d = list
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("depends must be a string or an array: %w", err)
+	}
+	*d = nil
+	for _, uuid := range strings.Split(s, ",") {
+		if uuid = strings.TrimSpace(uuid); uuid != "" {
+			*d = append(*d, uuid)
+		}
+	}
+	return nil
+}
+
+// Parse reads a Taskwarrior export: either a JSON array or one JSON object
+// per line, as older versions wrote
+func Parse(data []byte) ([]Task, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	if trimmed[0] == '[' {
+		var tasks []Task
+		if err := json.Unmarshal(trimmed, &tasks); err != nil {
+			return nil, fmt.Errorf("invalid Taskwarrior JSON: %w", err)
+		}
+		return tasks, nil
+	}
+
+	var tasks []Task
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		line = bytes.TrimSuffix(line, []byte(","))
+		if len(line) == 0 {
+			continue
+		}
+		var t Task
+		if err := json.Unmarshal(line, &t); err != nil {
+			return nil, fmt.Errorf("invalid Taskwarrior JSON on line %d: %w", n, err)
+		}
+		tasks = append(tasks, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// UUID returns a stable UUID for a Denote ID, so exporting the same task
+// twice updates it in Taskwarrior rather than adding a duplicate
+func UUID(denoteID string) string {
+	sum := sha1.Sum([]byte("denote-tasks:" + denoteID))
+	// Version 5 (name-based, SHA-1) and the RFC 4122 variant
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// Priority maps a Taskwarrior priority (H/M/L) to p1/p2/p3
+func Priority(priority string) string {
+	switch strings.ToUpper(priority) {
+	case "H":
+		return denote.PriorityP1
+	case "M":
+		return denote.PriorityP2
+	case "L":
+		return denote.PriorityP3
+	default:
+		return ""
+	}
+}
+
+// TaskwarriorPriority maps p1/p2/p3 to H/M/L
+func TaskwarriorPriority(priority string) string {
+	switch priority {
+	case denote.PriorityP1:
+		return "H"
+	case denote.PriorityP2:
+		return "M"
+	case denote.PriorityP3:
+		return "L"
+	default:
+		return ""
+	}
+}
+
+// Status maps a Taskwarrior status to a task status. denoteStatus, the
+// attribute written by export, takes precedence when it is valid.
+func Status(status, denoteStatus string) string {
+	if denote.IsValidTaskStatus(denoteStatus) {
+		return denoteStatus
+	}
+	switch status {
+	case StatusCompleted:
+		return denote.TaskStatusDone
+	case StatusDeleted:
+		return denote.TaskStatusDropped
+	case StatusWaiting:
+		return denote.TaskStatusPaused
+	default:
+		return denote.TaskStatusOpen
+	}
+}
+
+// TaskwarriorStatus maps a task status to a Taskwarrior status. Statuses
+// Taskwarrior lacks (paused, delegated) are exported as pending, with the
+// original returned as the second value for the denote_status attribute.
+func TaskwarriorStatus(status string) (string, string) {
+	switch status {
+	case denote.TaskStatusDone:
+		return StatusCompleted, ""
+	case denote.TaskStatusDropped:
+		return StatusDeleted, ""
+	case denote.TaskStatusPaused, denote.TaskStatusDelegated:
+		return StatusPending, status
+	default:
+		return StatusPending, ""
+	}
+}
+
+// Date converts a Taskwarrior date to a local YYYY-MM-DD date, or "" if
+// it isn't valid
+func Date(value string) string {
+	t, err := time.Parse(TimeFormat, value)
+	if err != nil {
+		return ""
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// Timestamp converts a YYYY-MM-DD date to a Taskwarrior date at local
+// midnight, which is what Taskwarrior stores for a date without a time
+func Timestamp(date string) string {
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return ""
+	}
+	return FormatTime(t)
+}
+
+// FormatTime formats a time as a Taskwarrior date
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(TimeFormat)
+}
+
+// Tag converts a Taskwarrior tag to a Denote keyword: lowercase letters
+// and digits only
+func Tag(tag string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return -1
+		}
+	}, tag)
+}
@@ -0,0 +1,392 @@
+package todotxt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/task"
+)
+
+// knownKeys are the key:value tags mapped to task fields:
+//
+//	due:    due_date           t:      start_date (threshold)
+//	pri:    priority of a completed task
+//	est:    estimate           status: paused, delegated or dropped
+//	tags:   comma-separated tags
+//	denote: Denote ID, so re-importing an export updates tasks in place
+var knownKeys = map[string]bool{
+	"due": true, "t": true, "pri": true, "est": true,
+	"status": true, "tags": true, "denote": true,
+}
+
+// Priority maps A/B/C to p1/p2/p3. Lower priorities (D to Z) map to p3.
+func Priority(letter string) string {
+	switch {
+	case letter == "":
+		return ""
+	case letter == "A":
+		return denote.PriorityP1
+	case letter == "B":
+		return denote.PriorityP2
+	default:
+		return denote.PriorityP3
+	}
+}
+
+// PriorityLetter maps p1/p2/p3 to A/B/C
+func PriorityLetter(priority string) string {
+	switch priority {
+	case denote.PriorityP1:
+		return "A"
+	case denote.PriorityP2:
+		return "B"
+	case denote.PriorityP3:
+		return "C"
+	default:
+		return ""
+	}
+}
+
+// ProjectName turns a project title into a +project name, joining words
+// with dashes
+func ProjectName(title string) string {
+	return strings.Join(strings.Fields(title), "-")
+}
+
+// projectKey normalizes a project name or title for matching, so +HomeGarden,
+// +home-garden and "Home Garden" are the same project
+func projectKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return -1
+		}
+	}, name)
+}
+
+// FromTask converts a task to a todo.txt item. projectTitle is the title
+// of the task's project, if any.
+func FromTask(t *denote.Task, projectTitle string) Item {
+	meta := t.TaskMetadata
+	item := Item{
+		Done:     meta.Status == denote.TaskStatusDone || meta.Status == denote.TaskStatusDropped,
+		Priority: PriorityLetter(meta.Priority),
+		Text:     meta.Title,
+	}
+
+	item.Created = createdDate(t.File.ID, meta)
+	if item.Done {
+		item.Completed = completedDate(meta)
+		if item.Completed == "" {
+			item.Completed = formatDate(t.ModTime)
+		}
+	}
+	if projectTitle != "" {
+		item.Projects = []string{ProjectName(projectTitle)}
+	}
+	var extra []KeyValue
+	for _, token := range meta.TodoTxt {
+		if strings.HasPrefix(token, "+") {
+			item.Projects = append(item.Projects, token[1:])
+		} else if key, value, ok := splitKeyValue(token); ok {
+			extra = append(extra, KeyValue{Key: key, Value: value})
+		}
+	}
+	if meta.Area != "" {
+		item.Contexts = []string{meta.Area}
+	}
+
+	addKey := func(key, value string) {
+		if value != "" {
+			item.Keys = append(item.Keys, KeyValue{Key: key, Value: value})
+		}
+	}
+	addKey("due", meta.DueDate)
+	addKey("t", meta.StartDate)
+	if meta.Estimate > 0 {
+		addKey("est", strconv.Itoa(meta.Estimate))
+	}
+	switch meta.Status {
+	case denote.TaskStatusPaused, denote.TaskStatusDelegated, denote.TaskStatusDropped:
+		addKey("status", meta.Status)
+	}
+
+	var tags []string
+	seen := map[string]bool{"task": true}
+	for _, tag := range append(append([]string{}, t.File.Tags...), meta.Tags...) {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	addKey("tags", strings.Join(tags, ","))
+	item.Keys = append(item.Keys, extra...)
+	addKey("denote", t.File.ID)
+
+	return item
+}
+
+// ImportResult lists what Import created, updated and left out
+type ImportResult struct {
+	Created  []*denote.Task
+	Updated  []*denote.Task
+	Projects []*denote.Project
+	Skipped  []string // Lines with the reason they were skipped
+}
+
+// itemFields are the task fields an item sets
+type itemFields struct {
+	Title     string
+	Status    string
+	Priority  string
+	DueDate   string
+	StartDate string
+	Estimate  int
+	ProjectID string
+	Area      string
+	Created   string // YYYY-MM-DD
+	Completed string // YYYY-MM-DD
+	Extra     string // Further +projects and unknown key:value tags, space-separated
+}
+
+// apply copies the fields into the metadata of the task with Denote ID id.
+// Dates are only written where they differ from what the task has, so a
+// timestamp on the same day is kept.
+func (f itemFields) apply(id string, meta *denote.TaskMetadata) {
+	meta.Title = f.Title
+	meta.SetStatus(f.Status, time.Now())
+	if f.Completed != "" && f.Completed != completedDate(*meta) {
+		meta.Completed = f.Completed
+	}
+	if f.Created != "" && f.Created != createdDate(id, *meta) {
+		meta.Created = f.Created
+	}
+	meta.TodoTxt = strings.Fields(f.Extra)
+	meta.Priority = f.Priority
+	meta.DueDate = f.DueDate
+	meta.StartDate = f.StartDate
+	meta.Estimate = f.Estimate
+	meta.ProjectID = f.ProjectID
+	meta.Area = f.Area
+}
+
+// Import creates task files of fileType in dir from todo.txt items. The first +project
+// is matched against existing project titles or created as a new project
+// file; the first @context becomes the area and further contexts become
+// tags. Other +projects and key:value tags are kept in the todotxt field
+// and written back by FromTask. Items carrying a denote: key for a task that still exists update
+// that task instead of creating a new one. On error, the result lists what
+// was done before it.
+func Import(dir, fileType string, items []Item, existing *denote.ScanResult) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	tasksByID := make(map[string]*denote.Task)
+	for _, t := range existing.Tasks {
+		tasksByID[t.File.ID] = t
+	}
+	projectIDs := make(map[string]string) // projectKey -> Denote ID
+	for _, p := range existing.Projects {
+		projectIDs[projectKey(p.ProjectMetadata.Title)] = p.File.ID
+	}
+
+	for _, item := range items {
+		if item.Text == "" {
+			result.Skipped = append(result.Skipped, item.String()+" (no description)")
+			continue
+		}
+
+		fields := itemFields{
+			Title:     item.Text,
+			Status:    denote.TaskStatusOpen,
+			Priority:  Priority(item.Priority),
+			DueDate:   validDate(item.Key("due")),
+			StartDate: validDate(item.Key("t")),
+			Created:   validDate(item.Created),
+			Extra:     extraTokens(item),
+		}
+		if status := item.Key("status"); denote.IsValidTaskStatus(status) {
+			fields.Status = status
+		} else if item.Done {
+			fields.Status = denote.TaskStatusDone
+		}
+		if fields.Status == denote.TaskStatusDone {
+			fields.Completed = validDate(item.Completed)
+		}
+		if est, err := strconv.Atoi(item.Key("est")); err == nil && est > 0 {
+			fields.Estimate = est
+		}
+		if len(item.Contexts) > 0 {
+			fields.Area = item.Contexts[0]
+		}
+
+		if len(item.Projects) > 0 {
+			name := item.Projects[0]
+			key := projectKey(name)
+			fields.ProjectID = projectIDs[key]
+			if fields.ProjectID == "" {
+				p, err := task.CreateProjectAs(dir, fileType, name, "", nil)
+				if err != nil {
+					return result, fmt.Errorf("failed to create project %s: %w", name, err)
+				}
+				fields.ProjectID = p.File.ID
+				projectIDs[key] = p.File.ID
+				result.Projects = append(result.Projects, p)
+			}
+		}
+
+		if t := tasksByID[item.Key("denote")]; t != nil {
+			updated, err := updateTask(t, fields)
+			if err != nil {
+				return result, err
+			}
+			if updated {
+				result.Updated = append(result.Updated, t)
+			} else {
+				result.Skipped = append(result.Skipped, item.Text+" (unchanged)")
+			}
+			continue
+		}
+
+		var tags []string
+		for _, c := range item.Contexts[min(1, len(item.Contexts)):] {
+			tags = appendTag(tags, c)
+		}
+		for _, tag := range strings.Split(item.Key("tags"), ",") {
+			tags = appendTag(tags, tag)
+		}
+
+		t, err := task.CreateTaskAs(dir, fileType, fields.Title, "", tags, fields.Area)
+		if err != nil {
+			return result, fmt.Errorf("failed to create task %s: %w", fields.Title, err)
+		}
+		if err := task.ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+			fields.apply(t.File.ID, meta)
+			return nil
+		}); err != nil {
+			return result, fmt.Errorf("failed to update task %s: %w", fields.Title, err)
+		}
+		fields.apply(t.File.ID, &t.TaskMetadata)
+		result.Created = append(result.Created, t)
+	}
+
+	return result, nil
+}
+
+// updateTask applies fields to an existing task and reports whether
+// anything changed. Completing a recurring task creates its next instance,
+// as marking it done would.
+func updateTask(t *denote.Task, fields itemFields) (bool, error) {
+	meta := t.TaskMetadata
+	before := itemFields{
+		Title:     meta.Title,
+		Status:    meta.Status,
+		Priority:  meta.Priority,
+		DueDate:   meta.DueDate,
+		StartDate: meta.StartDate,
+		Estimate:  meta.Estimate,
+		ProjectID: meta.ProjectID,
+		Area:      meta.Area,
+		Created:   createdDate(t.File.ID, meta),
+		Completed: completedDate(meta),
+		Extra:     strings.Join(meta.TodoTxt, " "),
+	}
+	if before.Status == "" {
+		before.Status = denote.TaskStatusOpen
+	}
+	// Lines without dates keep the task's own
+	if fields.Created == "" {
+		fields.Created = before.Created
+	}
+	if fields.Completed == "" && fields.Status == denote.TaskStatusDone {
+		fields.Completed = before.Completed
+	}
+	if before == fields {
+		return false, nil
+	}
+
+	err := task.ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+		fields.apply(t.File.ID, meta)
+		if meta.Status != denote.TaskStatusOpen {
+			meta.StopTimer(time.Now())
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update task %s: %w", fields.Title, err)
+	}
+
+	fields.apply(t.File.ID, &t.TaskMetadata)
+
+	if fields.Status == denote.TaskStatusDone && before.Status != denote.TaskStatusDone && meta.Recurrence != "" {
+		if _, err := task.CreateNextInstance(t, time.Now()); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// extraTokens lists the +projects after the first and the key:value tags
+// with no task field, as they appear in the line
+func extraTokens(item Item) string {
+	var tokens []string
+	for _, p := range item.Projects[min(1, len(item.Projects)):] {
+		tokens = append(tokens, "+"+p)
+	}
+	for _, kv := range item.Keys {
+		if !knownKeys[kv.Key] {
+			tokens = append(tokens, kv.Key+":"+kv.Value)
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// createdDate is the creation date of a task as a todo.txt date
+func createdDate(id string, meta denote.TaskMetadata) string {
+	t := denote.Task{File: denote.File{ID: id}, TaskMetadata: meta}
+	if created, ok := t.CreatedAt(); ok {
+		return formatDate(created)
+	}
+	return ""
+}
+
+// completedDate is the completion date of a done task as a todo.txt date,
+// or "" if none is recorded
+func completedDate(meta denote.TaskMetadata) string {
+	if meta.Status != denote.TaskStatusDone {
+		return ""
+	}
+	if completed, ok := meta.CompletedTime(); ok {
+		return formatDate(completed)
+	}
+	return ""
+}
+
+// appendTag adds a tag normalized to a Denote keyword, skipping empty,
+// reserved and duplicate tags
+func appendTag(tags []string, tag string) []string {
+	tag = projectKey(tag)
+	if tag == "" || tag == "task" || tag == "project" {
+		return tags
+	}
+	for _, t := range tags {
+		if t == tag {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
+
+// validDate returns date if it is a valid YYYY-MM-DD date, or ""
+func validDate(date string) string {
+	if _, err := time.Parse(dateFormat, date); err != nil {
+		return ""
+	}
+	return date
+}
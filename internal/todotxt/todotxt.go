@@ -0,0 +1,162 @@
+// Package todotxt reads and writes the todo.txt format
+// (https://github.com/todotxt/todo.txt) and maps it to tasks
+package todotxt
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dateFormat is the todo.txt date layout
+const dateFormat = "2006-01-02"
+
+// datePattern matches a todo.txt date
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// priorityPattern matches a priority marker such as "(A)"
+var priorityPattern = regexp.MustCompile(`^\([A-Z]\)$`)
+
+// KeyValue is a "key:value" extension tag
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// Item is one todo.txt line
+type Item struct {
+	Done      bool
+	Priority  string // "A" to "Z", or ""
+	Completed string // Completion date, YYYY-MM-DD
+	Created   string // Creation date, YYYY-MM-DD
+	Text      string // Description without projects, contexts and key:value tags
+	Projects  []string
+	Contexts  []string
+	Keys      []KeyValue
+}
+
+// Key returns the value of the first key:value tag with the given key
+func (it *Item) Key(key string) string {
+	for _, kv := range it.Keys {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return ""
+}
+
+// Parse reads todo.txt data, skipping blank lines
+func Parse(data string) []Item {
+	var items []Item
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if item, ok := ParseLine(scanner.Text()); ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ParseLine parses one todo.txt line. Projects, contexts and key:value tags
+// are taken out of the text, in the order they appear.
+func ParseLine(line string) (Item, bool) {
+	var item Item
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return item, false
+	}
+
+	// "x [completion date] [creation date]" or "[(A)] [creation date]"
+	i := 0
+	if fields[i] == "x" {
+		item.Done = true
+		i++
+		if i < len(fields) && datePattern.MatchString(fields[i]) {
+			item.Completed = fields[i]
+			i++
+		}
+	} else if priorityPattern.MatchString(fields[i]) {
+		item.Priority = fields[i][1:2]
+		i++
+	}
+	if i < len(fields) && datePattern.MatchString(fields[i]) {
+		item.Created = fields[i]
+		i++
+	}
+
+	var words []string
+	for _, f := range fields[i:] {
+		switch {
+		case len(f) > 1 && f[0] == '+':
+			item.Projects = append(item.Projects, f[1:])
+		case len(f) > 1 && f[0] == '@':
+			item.Contexts = append(item.Contexts, f[1:])
+		default:
+			if key, value, ok := splitKeyValue(f); ok {
+				item.Keys = append(item.Keys, KeyValue{Key: key, Value: value})
+				continue
+			}
+			words = append(words, f)
+		}
+	}
+	item.Text = strings.Join(words, " ")
+
+	// Completed tasks keep their priority as pri:X
+	if item.Done && item.Priority == "" {
+		if p := item.Key("pri"); len(p) == 1 && p[0] >= 'A' && p[0] <= 'Z' {
+			item.Priority = p
+		}
+	}
+	return item, true
+}
+
+// splitKeyValue splits a "key:value" token. URLs are not key:value tags.
+func splitKeyValue(token string) (string, string, bool) {
+	key, value, ok := strings.Cut(token, ":")
+	if !ok || key == "" || value == "" || strings.Contains(value, ":") || strings.HasPrefix(value, "//") {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+// String renders the item as a todo.txt line
+func (it Item) String() string {
+	var parts []string
+	if it.Done {
+		parts = append(parts, "x")
+		if it.Completed != "" {
+			parts = append(parts, it.Completed)
+		}
+	} else if it.Priority != "" {
+		parts = append(parts, "("+it.Priority+")")
+	}
+	// A creation date on a completed task needs a completion date before it
+	if it.Created != "" && (!it.Done || it.Completed != "") {
+		parts = append(parts, it.Created)
+	}
+	if it.Text != "" {
+		parts = append(parts, it.Text)
+	}
+	for _, p := range it.Projects {
+		parts = append(parts, "+"+p)
+	}
+	for _, c := range it.Contexts {
+		parts = append(parts, "@"+c)
+	}
+
+	keys := it.Keys
+	if it.Done && it.Priority != "" && it.Key("pri") == "" {
+		keys = append([]KeyValue{{Key: "pri", Value: it.Priority}}, keys...)
+	}
+	for _, kv := range keys {
+		parts = append(parts, kv.Key+":"+kv.Value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatDate formats a time as a todo.txt date
+func formatDate(t time.Time) string {
+	return t.Format(dateFormat)
+}
@@ -0,0 +1,454 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/task"
+)
+
+// agendaDateFormat is the format of due_date and start_date
+const agendaDateFormat = "2006-01-02"
+
+// agendaItem is a task or project placed on a day by one of its dates
+type agendaItem struct {
+	file     denote.File
+	title    string
+	field    string // "due" or "start": the date that placed it
+	date     string // Its value, YYYY-MM-DD
+	status   string
+	priority string
+	overdue  bool // Unfinished and due before today
+	carried  bool // Shown on today because it is overdue
+}
+
+// loadAgenda places the filtered tasks and projects on days by their due
+// and start dates; overdue items are also carried to today. Metadata comes
+// through the index, and the days are only rebuilt when the agenda opens or
+// its items change, not on every render.
+func (m *Model) loadAgenda() {
+	today := time.Now().Format(agendaDateFormat)
+	days := make(map[string][]agendaItem)
+
+	add := func(item agendaItem, due, start string, finished bool) {
+		if due != "" {
+			it := item
+			it.field, it.date = "due", due
+			it.overdue = !finished && due < today
+			days[due] = append(days[due], it)
+			if it.overdue {
+				it.carried = true
+				days[today] = append(days[today], it)
+			}
+		}
+		if start != "" && start != due {
+			it := item
+			it.field, it.date = "start", start
+			days[start] = append(days[start], it)
+		}
+	}
+
+	taskMeta, projectMeta := denote.NewScanner(m.config.NotesDirectory).LoadMetadata(m.filtered)
+	for _, f := range m.filtered {
+		if t, ok := taskMeta[f.Path]; ok {
+			meta := t.TaskMetadata
+			item := agendaItem{file: f, title: meta.Title, status: meta.Status, priority: meta.Priority}
+			if item.title == "" {
+				item.title = f.Title
+			}
+			finished := meta.Status == denote.TaskStatusDone || meta.Status == denote.TaskStatusDropped
+			add(item, meta.DueDate, meta.StartDate, finished)
+		} else if p, ok := projectMeta[f.Path]; ok {
+			meta := p.ProjectMetadata
+			item := agendaItem{file: f, title: meta.Title, status: meta.Status, priority: meta.Priority}
+			if item.title == "" {
+				item.title = f.Title
+			}
+			finished := meta.Status == denote.ProjectStatusCompleted || meta.Status == denote.ProjectStatusCancelled
+			add(item, meta.DueDate, meta.StartDate, finished)
+		}
+	}
+
+	// Overdue first, then due before start, then by priority and title
+	for _, items := range days {
+		sort.SliceStable(items, func(i, j int) bool {
+			a, b := items[i], items[j]
+			if a.overdue != b.overdue {
+				return a.overdue
+			}
+			if a.field != b.field {
+				return a.field == "due"
+			}
+			if pa, pb := denote.PriorityValue(a.priority), denote.PriorityValue(b.priority); pa != pb {
+				return pa < pb
+			}
+			return strings.ToLower(a.title) < strings.ToLower(b.title)
+		})
+	}
+	m.agendaDays = days
+}
+
+// startAgenda enters agenda mode on today
+func (m *Model) startAgenda() {
+	m.mode = ModeAgenda
+	m.agendaDay = agendaToday()
+	m.agendaCursor = 0
+	m.loadAgenda()
+}
+
+// agendaToday returns the start of today
+func agendaToday() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// agendaWeekStart returns the Monday of day's week
+func agendaWeekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// selectedAgendaItem returns the selected item on the selected day
+func (m Model) selectedAgendaItem() (agendaItem, bool) {
+	items := m.agendaDays[m.agendaDay.Format(agendaDateFormat)]
+	if m.agendaCursor < 0 || m.agendaCursor >= len(items) {
+		return agendaItem{}, false
+	}
+	return items[m.agendaCursor], true
+}
+
+// moveAgendaDay changes the selected day
+func (m *Model) moveAgendaDay(days int) {
+	m.agendaDay = m.agendaDay.AddDate(0, 0, days)
+	m.agendaCursor = 0
+}
+
+// rescheduleAgendaItem moves the selected item's date by days, relative
+// to the day it is shown on, and follows it
+func (m *Model) rescheduleAgendaItem(offset int) {
+	item, ok := m.selectedAgendaItem()
+	if !ok {
+		return
+	}
+	newDate := m.agendaDay.AddDate(0, 0, offset).Format(agendaDateFormat)
+
+	var err error
+	if item.file.IsTask() {
+		err = task.ModifyTaskFile(item.file.Path, func(meta *denote.TaskMetadata) error {
+			if item.field == "due" {
+				meta.DueDate = newDate
+			} else {
+				meta.StartDate = newDate
+			}
+			return nil
+		})
+	} else {
+		err = task.ModifyProjectFile(item.file.Path, func(meta *denote.ProjectMetadata) error {
+			if item.field == "due" {
+				meta.DueDate = newDate
+			} else {
+				meta.StartDate = newDate
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+		return
+	}
+
+	if item.field == "due" {
+		m.statusMsg = fmt.Sprintf("Due date set to %s", newDate)
+	} else {
+		m.statusMsg = fmt.Sprintf("Start date set to %s", newDate)
+	}
+	m.applyFilters()
+	m.sortFiles()
+
+	m.loadAgenda()
+	m.moveAgendaDay(offset)
+	for i, moved := range m.agendaDays[newDate] {
+		if moved.file.Path == item.file.Path && moved.field == item.field {
+			m.agendaCursor = i
+			break
+		}
+	}
+}
+
+func (m Model) handleAgendaKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.statusMsg = ""
+	days := m.agendaDays
+
+	// Months move by calendar month, weeks by seven days
+	period := func(dir int) {
+		if m.agendaMonth {
+			m.agendaDay = m.agendaDay.AddDate(0, dir, 0)
+			m.agendaCursor = 0
+		} else {
+			m.moveAgendaDay(7 * dir)
+		}
+	}
+
+	switch m.keys.action("agenda", msg.String()) {
+	case "agenda.back":
+		m.mode = ModeNormal
+
+	case "agenda.prev_day":
+		m.moveAgendaDay(-1)
+
+	case "agenda.next_day":
+		m.moveAgendaDay(1)
+
+	case "agenda.prev_period":
+		period(-1)
+
+	case "agenda.next_period":
+		period(1)
+
+	case "agenda.down":
+		if m.agendaCursor < len(days[m.agendaDay.Format(agendaDateFormat)])-1 {
+			m.agendaCursor++
+		}
+
+	case "agenda.up":
+		if m.agendaCursor > 0 {
+			m.agendaCursor--
+		}
+
+	case "agenda.today":
+		m.agendaDay = agendaToday()
+		m.agendaCursor = 0
+
+	case "agenda.toggle_month":
+		m.agendaMonth = !m.agendaMonth
+
+	case "agenda.earlier":
+		m.rescheduleAgendaItem(-1)
+
+	case "agenda.later":
+		m.rescheduleAgendaItem(1)
+
+	case "agenda.open":
+		item, ok := m.selectedAgendaItem()
+		if !ok {
+			return m, nil
+		}
+		file := item.file
+		if file.IsTask() {
+			t, err := denote.ParseTaskFile(file.Path)
+			if err != nil {
+				m.statusMsg = fmt.Sprintf("Error loading task: %v", err)
+				return m, nil
+			}
+			m.mode = ModeTaskView
+			m.viewingTask = t
+			m.viewingProject = nil
+			m.viewingFile = &file
+			m.editingField = ""
+			m.editBuffer = ""
+			m.loadTaskDependencies()
+			m.returnToAgenda = true
+		} else {
+			p, err := denote.ParseProjectFile(file.Path)
+			if err != nil {
+				m.statusMsg = fmt.Sprintf("Error loading project: %v", err)
+				return m, nil
+			}
+			m.mode = ModeProjectView
+			m.viewingTask = nil
+			m.viewingProject = p
+			m.viewingFile = &file
+			m.editingField = ""
+			m.editBuffer = ""
+			m.projectViewTab = 0
+			m.loadProjectTasks()
+			m.returnToAgenda = true
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) renderAgenda() string {
+	days := m.agendaDays
+
+	width := m.width
+	if width <= 0 {
+		width = 120
+	}
+	colWidth := width/7 - 1
+	if colWidth < 14 {
+		colWidth = 14
+	}
+
+	var grid string
+	var period string
+	if m.agendaMonth {
+		grid = m.renderAgendaMonth(days, colWidth)
+		period = m.agendaDay.Format("January 2006")
+	} else {
+		start := agendaWeekStart(m.agendaDay)
+		maxItems := m.height - HeaderFooterHeight - 3
+		if maxItems < MinVisibleHeight {
+			maxItems = MinVisibleHeight
+		}
+		grid = m.renderAgendaWeek(days, start, colWidth, maxItems)
+		period = fmt.Sprintf("Week of %s", start.Format("Jan 2, 2006"))
+	}
+
+	title := titleStyle.Render("Agenda")
+	status := fmt.Sprintf("%s | %s", period, m.agendaDay.Format("Mon Jan 2"))
+	if item, ok := m.selectedAgendaItem(); ok {
+		status += fmt.Sprintf(" | %s %s", item.field, item.date)
+	}
+	if m.statusMsg != "" {
+		status += " | " + m.statusMsg
+	}
+
+	unit := "week"
+	if m.agendaMonth {
+		unit = "month"
+	}
+	k := m.keys
+	help := helpStyle.Render(joinHints([]string{
+		k.hint("day", "agenda.prev_day", "agenda.next_day"),
+		k.hint(unit, "agenda.prev_period", "agenda.next_period"),
+		k.hint("item", "agenda.down", "agenda.up"),
+		k.hint("reschedule", "agenda.earlier", "agenda.later"),
+		k.hint("today", "agenda.today"),
+		k.hint("week/month", "agenda.toggle_month"),
+		k.hint("open", "agenda.open"),
+		k.hint("back", "agenda.back"),
+	}) + "   (• due  → start  ! overdue)")
+	return lipgloss.JoinVertical(lipgloss.Left, title, statusStyle.Render(status), "", grid, "", help)
+}
+
+// renderAgendaWeek lays out the week starting on start as seven columns
+func (m Model) renderAgendaWeek(days map[string][]agendaItem, start time.Time, colWidth, maxItems int) string {
+	var columns []string
+	for i := 0; i < 7; i++ {
+		day := start.AddDate(0, 0, i)
+		heading := m.agendaDayHeading(day, day.Format("Mon 01-02"))
+		lines := append([]string{heading}, m.agendaDayLines(days, day, colWidth, maxItems)...)
+		columns = append(columns, lipgloss.NewStyle().Width(colWidth).MarginRight(1).Render(strings.Join(lines, "\n")))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// renderAgendaMonth lays out the selected day's month as a grid of weeks
+func (m Model) renderAgendaMonth(days map[string][]agendaItem, colWidth int) string {
+	first := time.Date(m.agendaDay.Year(), m.agendaDay.Month(), 1, 0, 0, 0, 0, m.agendaDay.Location())
+	start := agendaWeekStart(first)
+
+	// Fit up to six weeks in the window
+	cellItems := (m.height - HeaderFooterHeight - 3) / 6
+	if cellItems < 1 {
+		cellItems = 1
+	}
+	cellItems-- // Day number
+
+	var header []string
+	for i := 0; i < 7; i++ {
+		header = append(header, lipgloss.NewStyle().Width(colWidth).MarginRight(1).Render(
+			boardHeaderStyle.Render(start.AddDate(0, 0, i).Format("Mon"))))
+	}
+	rows := []string{lipgloss.JoinHorizontal(lipgloss.Top, header...)}
+
+	for week := start; week.Month() == first.Month() || week.Before(first); week = week.AddDate(0, 0, 7) {
+		var cells []string
+		for i := 0; i < 7; i++ {
+			day := week.AddDate(0, 0, i)
+			heading := m.agendaDayHeading(day, day.Format("2"))
+			if day.Month() != first.Month() && !m.sameDay(day, m.agendaDay) {
+				heading = agendaOtherMonthStyle.Render(day.Format("2"))
+			}
+			lines := append([]string{heading}, m.agendaDayLines(days, day, colWidth, cellItems)...)
+			cells = append(cells, lipgloss.NewStyle().Width(colWidth).Height(cellItems+1).MarginRight(1).Render(strings.Join(lines, "\n")))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// sameDay reports whether a and b are the same date
+func (m Model) sameDay(a, b time.Time) bool {
+	return a.Format(agendaDateFormat) == b.Format(agendaDateFormat)
+}
+
+// agendaDayHeading styles a day's heading: selected, today or plain
+func (m Model) agendaDayHeading(day time.Time, text string) string {
+	switch {
+	case m.sameDay(day, m.agendaDay):
+		return boardActiveHeaderStyle.Render(text)
+	case m.sameDay(day, agendaToday()):
+		return agendaTodayStyle.Render(text)
+	}
+	return boardHeaderStyle.Render(text)
+}
+
+// agendaDayLines renders up to maxItems of a day's items, scrolled so the
+// selected one is visible
+func (m Model) agendaDayLines(days map[string][]agendaItem, day time.Time, width, maxItems int) []string {
+	items := days[day.Format(agendaDateFormat)]
+	selectedDay := m.sameDay(day, m.agendaDay)
+	if len(items) == 0 || maxItems < 1 {
+		return nil
+	}
+
+	start := 0
+	if selectedDay && m.agendaCursor >= maxItems {
+		start = m.agendaCursor - maxItems + 1
+	}
+	end := start + maxItems
+	more := 0
+	if end < len(items) {
+		// Keep the last line for the count
+		end--
+		more = len(items) - end
+	}
+	if end > len(items) {
+		end = len(items)
+	}
+
+	var lines []string
+	for i := start; i < end; i++ {
+		lines = append(lines, m.renderAgendaItem(items[i], width, selectedDay && i == m.agendaCursor))
+	}
+	if more > 0 {
+		lines = append(lines, helpStyle.Render(fmt.Sprintf("+%d more", more)))
+	}
+	return lines
+}
+
+// renderAgendaItem renders one item, marked by the date that placed it
+func (m Model) renderAgendaItem(item agendaItem, width int, selected bool) string {
+	marker := "•"
+	if item.field == "start" {
+		marker = "→"
+	}
+	if item.carried {
+		marker = "!"
+	}
+	text := marker + " " + truncate(item.title, width-2)
+
+	switch {
+	case selected:
+		return selectedStyle.Render(text)
+	case item.overdue:
+		return overdueStyle.Render(text)
+	case item.status == denote.TaskStatusDone || item.status == denote.ProjectStatusCompleted:
+		return doneStyle.Render(text)
+	case item.status == denote.TaskStatusDropped || item.status == denote.ProjectStatusCancelled:
+		return droppedStyle.Render(text)
+	case item.file.IsProject():
+		return projectStyle.Render(text)
+	case item.priority == PriorityLevels[0]:
+		return priorityHighStyle.Render(text)
+	}
+	return baseStyle.Render(text)
+}
@@ -0,0 +1,276 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/task"
+)
+
+// boardStatuses are the board's columns, left to right
+var boardStatuses = []string{
+	denote.TaskStatusOpen,
+	denote.TaskStatusPaused,
+	denote.TaskStatusDelegated,
+	denote.TaskStatusDone,
+	denote.TaskStatusDropped,
+}
+
+// loadBoard puts the filtered tasks in the board's columns, in the list's
+// sort order. Metadata comes through the index, and the columns are only
+// rebuilt when the board opens or its tasks change, not on every key press.
+func (m *Model) loadBoard() {
+	var files []denote.File
+	for _, f := range m.filtered {
+		if f.IsTask() {
+			files = append(files, f)
+		}
+	}
+	taskMeta, _ := denote.NewScanner(m.config.NotesDirectory).LoadMetadata(files)
+
+	columns := make([][]*denote.Task, len(boardStatuses))
+	for _, f := range files {
+		t, ok := taskMeta[f.Path]
+		if !ok {
+			continue
+		}
+		status := t.TaskMetadata.Status
+		if status == "" {
+			status = denote.TaskStatusOpen
+		}
+		for i, s := range boardStatuses {
+			if s == status {
+				columns[i] = append(columns[i], t)
+				break
+			}
+		}
+	}
+	m.boardColumns = columns
+	m.clampBoard()
+}
+
+// boardVisibleCards is how many cards fit in a column
+func (m Model) boardVisibleCards() int {
+	visible := m.height - HeaderFooterHeight - 2 // Column headings
+	if visible < MinVisibleHeight {
+		visible = MinVisibleHeight
+	}
+	return visible
+}
+
+// clampBoard keeps the column cursors within their columns and scrolls
+// each column so its cursor is visible
+func (m *Model) clampBoard() {
+	visible := m.boardVisibleCards()
+	for i, cards := range m.boardColumns {
+		if m.boardCursors[i] >= len(cards) {
+			m.boardCursors[i] = len(cards) - 1
+		}
+		if m.boardCursors[i] < 0 {
+			m.boardCursors[i] = 0
+		}
+		if m.boardCursors[i] < m.boardScroll[i] {
+			m.boardScroll[i] = m.boardCursors[i]
+		}
+		if m.boardCursors[i] >= m.boardScroll[i]+visible {
+			m.boardScroll[i] = m.boardCursors[i] - visible + 1
+		}
+	}
+}
+
+// moveBoardCard moves the selected card to the column dir steps away,
+// changing its status, and follows it there
+func (m *Model) moveBoardCard(dir int) {
+	cards := m.boardColumns[m.boardColumn]
+	target := m.boardColumn + dir
+	if len(cards) == 0 || target < 0 || target >= len(boardStatuses) {
+		return
+	}
+
+	card := cards[m.boardCursors[m.boardColumn]]
+	status := boardStatuses[target]
+	next, err := task.UpdateTaskStatus(card.File.Path, status)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+		return
+	}
+	if next != nil {
+		m.scanFiles()
+		m.statusMsg = fmt.Sprintf("Task done, next occurrence #%d created", next.IndexID)
+	} else {
+		m.applyFilters()
+		m.sortFiles()
+		m.statusMsg = "Task status changed to " + status
+	}
+
+	m.boardColumn = target
+	m.loadBoard()
+	for i, t := range m.boardColumns[target] {
+		if t.File.Path == card.File.Path {
+			m.boardCursors[target] = i
+			break
+		}
+	}
+}
+
+func (m Model) handleBoardKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.statusMsg = ""
+	columns := m.boardColumns
+
+	switch m.keys.action("board", msg.String()) {
+	case "board.back":
+		m.mode = ModeNormal
+
+	case "board.left":
+		if m.boardColumn > 0 {
+			m.boardColumn--
+		}
+
+	case "board.right":
+		if m.boardColumn < len(boardStatuses)-1 {
+			m.boardColumn++
+		}
+
+	case "board.down":
+		if m.boardCursors[m.boardColumn] < len(columns[m.boardColumn])-1 {
+			m.boardCursors[m.boardColumn]++
+		}
+
+	case "board.up":
+		if m.boardCursors[m.boardColumn] > 0 {
+			m.boardCursors[m.boardColumn]--
+		}
+
+	case "board.top":
+		m.boardCursors[m.boardColumn] = 0
+
+	case "board.bottom":
+		m.boardCursors[m.boardColumn] = len(columns[m.boardColumn]) - 1
+
+	case "board.move_left":
+		m.moveBoardCard(-1)
+
+	case "board.move_right":
+		m.moveBoardCard(1)
+
+	case "board.open":
+		cards := columns[m.boardColumn]
+		if len(cards) == 0 {
+			return m, nil
+		}
+		card := cards[m.boardCursors[m.boardColumn]]
+		file := card.File
+		m.mode = ModeTaskView
+		m.viewingTask = card
+		m.viewingProject = nil
+		m.viewingFile = &file
+		m.editingField = ""
+		m.editBuffer = ""
+		m.loadTaskDependencies()
+		m.returnToBoard = true
+		return m, nil
+	}
+
+	m.clampBoard()
+	return m, nil
+}
+
+func (m Model) renderBoard() string {
+	columns := m.boardColumns
+
+	width := m.width
+	if width <= 0 {
+		width = 120
+	}
+	colWidth := width/len(boardStatuses) - 1
+	if colWidth < 16 {
+		colWidth = 16
+	}
+
+	visible := m.boardVisibleCards()
+	var rendered []string
+	for i, status := range boardStatuses {
+		cards := columns[i]
+
+		heading := fmt.Sprintf("%s (%d)", strings.ToUpper(status[:1])+status[1:], len(cards))
+		if i == m.boardColumn {
+			heading = boardActiveHeaderStyle.Render(heading)
+		} else {
+			heading = boardHeaderStyle.Render(heading)
+		}
+		lines := []string{heading, ""}
+
+		start := m.boardScroll[i]
+		if start > 0 {
+			lines[1] = helpStyle.Render(fmt.Sprintf("↑ %d more", start))
+		}
+		end := start + visible
+		if end > len(cards) {
+			end = len(cards)
+		}
+		for j := start; j < end; j++ {
+			selected := i == m.boardColumn && j == m.boardCursors[i]
+			lines = append(lines, m.renderBoardCard(cards[j], colWidth, selected))
+		}
+		if end < len(cards) {
+			lines = append(lines, helpStyle.Render(fmt.Sprintf("↓ %d more", len(cards)-end)))
+		}
+
+		column := lipgloss.NewStyle().Width(colWidth).MarginRight(1).Render(strings.Join(lines, "\n"))
+		rendered = append(rendered, column)
+	}
+
+	board := lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+	k := m.keys
+	help := helpStyle.Render(joinHints([]string{
+		k.hint("column", "board.left", "board.right"),
+		k.hint("card", "board.down", "board.up"),
+		k.hint("move card", "board.move_left", "board.move_right"),
+		k.hint("open", "board.open"),
+		k.hint("back", "board.back"),
+	}))
+	return lipgloss.JoinVertical(lipgloss.Left, m.renderHeader(), board, "", help)
+}
+
+// renderBoardCard renders one card: priority, title and due date
+func (m Model) renderBoardCard(t *denote.Task, width int, selected bool) string {
+	title := t.TaskMetadata.Title
+	if title == "" {
+		title = t.File.Title
+	}
+
+	due := ""
+	if t.TaskMetadata.DueDate != "" && len(t.TaskMetadata.DueDate) == len("2006-01-02") {
+		due = " " + t.TaskMetadata.DueDate[5:] // MM-DD
+	}
+	priority := ""
+	if t.TaskMetadata.Priority != "" {
+		priority = t.TaskMetadata.Priority + " "
+	}
+
+	room := width - 2 - len(priority) - len(due)
+	if room < 4 {
+		room = 4
+	}
+	text := priority + truncate(title, room) + due
+
+	if selected {
+		return selectedStyle.Render("> " + text)
+	}
+
+	style := baseStyle
+	switch {
+	case t.TaskMetadata.Status == denote.TaskStatusDone:
+		style = doneStyle
+	case t.TaskMetadata.Status == denote.TaskStatusDropped:
+		style = droppedStyle
+	case denote.IsOverdue(t.TaskMetadata.DueDate):
+		style = overdueStyle
+	case t.TaskMetadata.Priority == PriorityLevels[0]:
+		style = priorityHighStyle
+	}
+	return style.Render("  " + text)
+}
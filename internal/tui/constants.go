@@ -57,6 +57,7 @@ const (
 	MsgTasksOnly         = "Task management only - notes mode removed"
 	MsgAlreadyInTaskMode = "Already showing tasks"
 	MsgFuzzyMatch        = " (fuzzy match, #tag for tags, Esc to clear)"
+	MsgQueryMatch        = " (query, Esc to clear)"
 	MsgPressEnterSelect  = "press Enter to select"
 	MsgPressEnterChange  = "press Enter to change"
 	MsgSpaceSeparated    = "space-separated"
@@ -15,12 +15,12 @@ type FieldRenderer struct {
 	emptyStyle lipgloss.Style
 }
 
-// NewFieldRenderer creates a field renderer with default styles
+// NewFieldRenderer creates a field renderer with the current theme's styles
 func NewFieldRenderer() *FieldRenderer {
 	return &FieldRenderer{
-		labelStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("246")),
-		valueStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("252")),
-		emptyStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true),
+		labelStyle: statusStyle.Copy().Bold(true),
+		valueStyle: baseStyle,
+		emptyStyle: hintStyle,
 	}
 }
 
@@ -66,11 +66,11 @@ func (fr *FieldRenderer) RenderPriorityWithCursor(priority string, isEditing boo
 	var style lipgloss.Style
 	switch priority {
 	case "p1":
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+		style = priorityHighStyle
 	case "p2":
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+		style = priorityMediumStyle
 	case "p3":
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("248"))
+		style = priorityLowStyle
 	default:
 		return fr.RenderField("Priority", "", "none", false, "")
 	}
@@ -98,18 +98,18 @@ func (fr *FieldRenderer) RenderStatusWithCursor(status string, isEditing bool, e
 	switch status {
 	case denote.TaskStatusDone:
 		symbol = StatusSymbolDone
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("70"))
+		style = doneStyle
 	case denote.TaskStatusPaused:
 		symbol = StatusSymbolPaused
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+		style = pausedStyle
 	case denote.TaskStatusDelegated:
 		symbol = StatusSymbolDelegated
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("33"))
+		style = delegatedStyle
 	case denote.TaskStatusDropped:
 		symbol = StatusSymbolDropped
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+		style = droppedStyle
 	default:
-		style = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+		style = baseStyle
 	}
 
 	return fmt.Sprintf("%s %s %s",
@@ -156,7 +156,7 @@ func (fr *FieldRenderer) RenderDueDateWithCursor(dueDate string, isEditing bool,
 	if denote.IsOverdue(dueDate) {
 		return fmt.Sprintf("%s %s",
 			fr.labelStyle.Render("Due Date    :"),
-			lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true).Render(dueDate + " (overdue)"),
+			overdueStyle.Render(dueDate + " (overdue)"),
 		)
 	}
 
@@ -0,0 +1,299 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pdxmph/denote-tasks/internal/config"
+)
+
+// keyBinding is a named action and the keys it's bound to by default.
+// Actions are named context.action, where the context is the screen that
+// handles them: list, task (task details), project (project details),
+// board or agenda.
+type keyBinding struct {
+	action  string
+	keys    []string // As tea.KeyMsg.String()
+	section string   // Help screen section; empty to leave it out
+	help    string
+}
+
+// defaultBindings are the built-in keys, in help screen order
+var defaultBindings = []keyBinding{
+	// Task and project list
+	{"list.down", []string{"j", "down"}, "Navigation", "Move down"},
+	{"list.up", []string{"k", "up"}, "Navigation", "Move up"},
+	{"list.page_down", []string{"ctrl+d"}, "Navigation", "Page down"},
+	{"list.page_up", []string{"ctrl+u"}, "Navigation", "Page up"},
+	{"list.top", []string{"g"}, "Navigation", "Go to top (press twice)"},
+	{"list.bottom", []string{"G"}, "Navigation", "Go to bottom"},
+
+	{"list.open", []string{"enter", "u"}, "Actions", "Open task/project details"},
+	{"list.create", []string{"c"}, "Actions", "Create new task/project"},
+	{"list.edit_due", []string{"d"}, "Actions", "Edit due date"},
+	{"list.edit_estimate", []string{"e"}, "Actions", "Edit estimate (tasks only)"},
+	{"list.start_timer", []string{"i"}, "Actions", "Start timer on task (stops any other)"},
+	{"list.log", []string{"l"}, "Actions", "Add log entry (tasks only)"},
+	{"list.stop_timer", []string{"o"}, "Actions", "Stop running timer"},
+	{"list.reverse_sort", []string{"r"}, "Actions", "Toggle sort order"},
+	{"list.state_menu", []string{"s"}, "Actions", "Change task state (open/done/etc)"},
+	{"list.edit_tags", []string{"t"}, "Actions", "Edit tags"},
+	{"list.undo", []string{"U"}, "Actions", "Undo last change"},
+	{"list.delete", []string{"x", "delete"}, "Actions", "Delete task/project"},
+	{"list.search", []string{"/"}, "Actions", "Fuzzy search (use #tag for tag search, or a query\nsuch as \"area=work and due<+7d\")"},
+	{"list.text_search", []string{"F"}, "Actions", "Full-text search of titles, tags and notes"},
+
+	{"list.clear_priority", []string{"0"}, "Priority", "Clear priority"},
+	{"list.set_priority_1", []string{"1"}, "Priority", "Set priority p1"},
+	{"list.set_priority_2", []string{"2"}, "Priority", "Set priority p2"},
+	{"list.set_priority_3", []string{"3"}, "Priority", "Set priority p3"},
+
+	{"list.external_edit", []string{"E"}, "Filters & Views", "Edit in external editor"},
+	{"list.projects", []string{"P"}, "Filters & Views", "Toggle projects view"},
+	{"list.tasks", []string{"T"}, "Filters & Views", "Toggle tasks view"},
+	{"list.sort_menu", []string{"S"}, "Filters & Views", "Sort options menu"},
+	{"list.filter_menu", []string{"f"}, "Filters & Views", "Filter menu (area/priority/state/soon)"},
+	{"list.views", []string{"v"}, "Filters & Views", "Saved views from the config"},
+	{"list.board", []string{"b"}, "Filters & Views", "Board of tasks by status"},
+	{"list.agenda", []string{"a"}, "Filters & Views", "Agenda of due and start dates"},
+
+	{"list.redo", []string{"ctrl+r"}, "Other", "Redo last undone change"},
+	{"list.help", []string{"?"}, "Other", "Toggle this help"},
+	{"list.quit", []string{"q", "ctrl+c"}, "Other", "Quit"},
+
+	// Task details
+	{"task.back", []string{"q", "esc"}, "", "Back"},
+	{"task.external_edit", []string{"E"}, "", "Edit in external editor"},
+	{"task.log", []string{"l"}, "", "Add log entry"},
+	{"task.edit_title", []string{"T"}, "", "Edit title"},
+	{"task.edit_priority", []string{"p"}, "", "Edit priority"},
+	{"task.edit_status", []string{"s"}, "", "Edit status"},
+	{"task.edit_due", []string{"d"}, "", "Edit due date"},
+	{"task.edit_area", []string{"a"}, "", "Edit area"},
+	{"task.edit_estimate", []string{"e"}, "", "Edit estimate"},
+	{"task.edit_recurrence", []string{"R"}, "", "Edit recurrence"},
+	{"task.edit_tags", []string{"t"}, "", "Edit tags"},
+	{"task.set_project", []string{"j"}, "", "Choose project"},
+	{"task.goto_blocker", []string{"b"}, "", "Open first unfinished dependency"},
+	{"task.start_timer", []string{"i"}, "", "Start timer"},
+	{"task.stop_timer", []string{"o"}, "", "Stop timer"},
+	{"task.toggle_item", []string{"c"}, "", "Toggle checklist item by number"},
+	{"task.toggle_item_1", []string{"1"}, "", "Toggle checklist item 1"},
+	{"task.toggle_item_2", []string{"2"}, "", "Toggle checklist item 2"},
+	{"task.toggle_item_3", []string{"3"}, "", "Toggle checklist item 3"},
+	{"task.toggle_item_4", []string{"4"}, "", "Toggle checklist item 4"},
+	{"task.toggle_item_5", []string{"5"}, "", "Toggle checklist item 5"},
+	{"task.toggle_item_6", []string{"6"}, "", "Toggle checklist item 6"},
+	{"task.toggle_item_7", []string{"7"}, "", "Toggle checklist item 7"},
+	{"task.toggle_item_8", []string{"8"}, "", "Toggle checklist item 8"},
+	{"task.toggle_item_9", []string{"9"}, "", "Toggle checklist item 9"},
+	{"task.rename", []string{"r"}, "", "Rename file to match tags"},
+	{"task.help", []string{"?"}, "", "Help"},
+
+	// Project details
+	{"project.back", []string{"q", "esc"}, "", "Back"},
+	{"project.switch_tab", []string{"tab"}, "", "Switch tab"},
+	{"project.external_edit", []string{"E"}, "", "Edit in external editor"},
+	{"project.edit_title", []string{"T"}, "", "Edit title"},
+	{"project.edit_priority", []string{"p"}, "", "Edit priority"},
+	{"project.edit_status", []string{"s"}, "", "Edit status"},
+	{"project.edit_due", []string{"d"}, "", "Edit due date"},
+	{"project.edit_area", []string{"a"}, "", "Edit area"},
+	{"project.edit_tags", []string{"t"}, "", "Edit tags"},
+	{"project.new_task", []string{"n"}, "", "Create task in project"},
+	{"project.down", []string{"j", "down"}, "", "Next task"},
+	{"project.up", []string{"k", "up"}, "", "Previous task"},
+	{"project.page_down", []string{"ctrl+d"}, "", "Page down"},
+	{"project.page_up", []string{"ctrl+u"}, "", "Page up"},
+	{"project.bottom", []string{"G"}, "", "Last task"},
+	{"project.open_task", []string{"enter"}, "", "Open task"},
+	{"project.clear_task_priority", []string{"0"}, "", "Clear task priority"},
+	{"project.set_task_priority_1", []string{"1"}, "", "Set task priority p1"},
+	{"project.set_task_priority_2", []string{"2"}, "", "Set task priority p2"},
+	{"project.set_task_priority_3", []string{"3"}, "", "Set task priority p3"},
+	{"project.delete_task", []string{"x"}, "", "Delete task"},
+	{"project.delete", []string{"X"}, "", "Delete project"},
+	{"project.sort_menu", []string{"S"}, "", "Sort options menu"},
+
+	// Board
+	{"board.back", []string{"q", "esc", "b"}, "", "Back"},
+	{"board.left", []string{"h", "left"}, "", "Previous column"},
+	{"board.right", []string{"l", "right"}, "", "Next column"},
+	{"board.down", []string{"j", "down"}, "", "Next card"},
+	{"board.up", []string{"k", "up"}, "", "Previous card"},
+	{"board.top", []string{"g"}, "", "First card"},
+	{"board.bottom", []string{"G"}, "", "Last card"},
+	{"board.move_left", []string{"H", "<"}, "", "Move card to the previous status"},
+	{"board.move_right", []string{"L", ">"}, "", "Move card to the next status"},
+	{"board.open", []string{"enter"}, "", "Open task"},
+
+	// Agenda
+	{"agenda.back", []string{"q", "esc", "a"}, "", "Back"},
+	{"agenda.prev_day", []string{"h", "left"}, "", "Previous day"},
+	{"agenda.next_day", []string{"l", "right"}, "", "Next day"},
+	{"agenda.prev_period", []string{"["}, "", "Previous week or month"},
+	{"agenda.next_period", []string{"]"}, "", "Next week or month"},
+	{"agenda.down", []string{"j", "down"}, "", "Next item"},
+	{"agenda.up", []string{"k", "up"}, "", "Previous item"},
+	{"agenda.today", []string{"t", "."}, "", "Go to today"},
+	{"agenda.toggle_month", []string{"m"}, "", "Toggle week and month"},
+	{"agenda.earlier", []string{"H", "<"}, "", "Reschedule a day earlier"},
+	{"agenda.later", []string{"L", ">"}, "", "Reschedule a day later"},
+	{"agenda.open", []string{"enter"}, "", "Open task or project"},
+}
+
+// keymap is the active key bindings
+type keymap struct {
+	keys    map[string][]string          // Action -> keys
+	actions map[string]map[string]string // Context -> key -> action
+}
+
+// newKeymap applies config overrides to the default bindings. Every
+// overridden action must exist, and no key may trigger two actions in the
+// same context.
+func newKeymap(overrides config.KeyBindings) (*keymap, error) {
+	km := &keymap{
+		keys:    make(map[string][]string),
+		actions: make(map[string]map[string]string),
+	}
+	for _, b := range defaultBindings {
+		km.keys[b.action] = b.keys
+	}
+
+	var names []string
+	for action := range overrides {
+		names = append(names, action)
+	}
+	sort.Strings(names)
+	for _, action := range names {
+		if _, ok := km.keys[action]; !ok {
+			return nil, fmt.Errorf("unknown action in [keys]: %s", action)
+		}
+		for _, key := range overrides[action] {
+			if key == "" {
+				return nil, fmt.Errorf("empty key for %s in [keys]", action)
+			}
+		}
+		km.keys[action] = overrides[action]
+	}
+
+	// Check in help order so errors name actions predictably
+	for _, b := range defaultBindings {
+		context := actionContext(b.action)
+		if km.actions[context] == nil {
+			km.actions[context] = make(map[string]string)
+		}
+		for _, key := range km.keys[b.action] {
+			if other, ok := km.actions[context][key]; ok && other != b.action {
+				return nil, fmt.Errorf("key %q is bound to both %s and %s", key, other, b.action)
+			}
+			km.actions[context][key] = b.action
+		}
+	}
+
+	return km, nil
+}
+
+// actionContext returns the context part of an action name
+func actionContext(action string) string {
+	context, _, _ := strings.Cut(action, ".")
+	return context
+}
+
+// action returns the action key triggers in context, or "" if none
+func (km *keymap) action(context, key string) string {
+	return km.actions[context][key]
+}
+
+// hint formats the first key of each action, for footers: "j/k:nav".
+// Returns "" if none of the actions are bound.
+func (km *keymap) hint(label string, actions ...string) string {
+	var keys []string
+	for _, action := range actions {
+		if bound := km.keys[action]; len(bound) > 0 {
+			keys = append(keys, bound[0])
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	return strings.Join(keys, "/") + ":" + label
+}
+
+// joinHints joins footer hints, leaving out those of unbound actions
+func joinHints(hints []string) string {
+	var bound []string
+	for _, hint := range hints {
+		if hint != "" {
+			bound = append(bound, hint)
+		}
+	}
+	return strings.Join(bound, " • ")
+}
+
+// keyLabel formats a key for the help screen
+func keyLabel(key string) string {
+	switch key {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case "delete":
+		return "Del"
+	case "enter", "tab", "esc", "backspace", "home", "end":
+		return strings.ToUpper(key[:1]) + key[1:]
+	}
+	if rest, ok := strings.CutPrefix(key, "ctrl+"); ok {
+		return "Ctrl+" + strings.ToUpper(rest)
+	}
+	return key
+}
+
+// renderKeyHelp lists the bound actions of the list context by section
+func (km *keymap) renderKeyHelp() string {
+	labels := make(map[string]string)
+	width := 7
+	for _, binding := range defaultBindings {
+		keys := km.keys[binding.action]
+		if binding.section == "" || len(keys) == 0 {
+			continue
+		}
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = keyLabel(key)
+		}
+		labels[binding.action] = strings.Join(names, "/")
+		if n := utf8.RuneCountInString(labels[binding.action]); n > width {
+			width = n
+		}
+	}
+
+	var b strings.Builder
+	section := ""
+	for _, binding := range defaultBindings {
+		label, ok := labels[binding.action]
+		if !ok {
+			continue
+		}
+		if binding.section != section {
+			if section != "" {
+				b.WriteString("\n")
+			}
+			section = binding.section
+			b.WriteString(section + ":\n")
+		}
+
+		lines := strings.Split(binding.help, "\n")
+		fmt.Fprintf(&b, "  %-*s %s\n", width, label, lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(&b, "  %-*s %s\n", width, "", line)
+		}
+	}
+	return b.String()
+}
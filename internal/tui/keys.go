@@ -2,7 +2,6 @@ package tui
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	
@@ -37,6 +36,14 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleConfirmDeleteKeys(msg)
 	case ModeFilterMenu:
 		return m.handleFilterMenuKeys(msg)
+	case ModeViewMenu:
+		return m.handleViewMenuKeys(msg)
+	case ModeTextSearch:
+		return m.handleTextSearchKeys(msg)
+	case ModeBoard:
+		return m.handleBoardKeys(msg)
+	case ModeAgenda:
+		return m.handleAgendaKeys(msg)
 	case ModePriorityFilter:
 		return m.handlePriorityFilterKeys(msg)
 	case ModeStateFilter:
@@ -69,8 +76,9 @@ func (m Model) handleNormalKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleHelpKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "q", "esc", "?":
+	// The help key toggles help off again, wherever it's bound
+	switch key := msg.String(); {
+	case key == "q", key == "esc", m.keys.action("list", key) == "list.help":
 		m.mode = ModeNormal
 	}
 	return m, nil
@@ -83,6 +91,7 @@ func (m Model) handleSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = ModeNormal
 		m.searchInput = ""
 		m.searchQuery = ""
+		m.updateSearchExpr()
 		m.cursor = 0
 		m.applyFilters()
 		m.sortFiles()
@@ -100,6 +109,7 @@ func (m Model) handleSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(m.searchInput) > 0 {
 			m.searchInput = m.searchInput[:len(m.searchInput)-1]
 			m.searchQuery = m.searchInput
+			m.updateSearchExpr()
 			m.cursor = 0
 			m.applyFilters()
 			m.sortFiles()
@@ -110,6 +120,7 @@ func (m Model) handleSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(msg.String()) == 1 {
 			m.searchInput += msg.String()
 			m.searchQuery = m.searchInput
+			m.updateSearchExpr()
 			m.cursor = 0
 			m.applyFilters()
 			m.sortFiles()
@@ -336,43 +347,49 @@ func (m Model) handleCreateProjectTagsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 }
 
 func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
+	action := m.keys.action("list", msg.String())
+	switch action {
 	// Common navigation keys work the same
-	case "q", "ctrl+c":
+	case "list.quit":
 		return m, tea.Quit
 		
-	case "j", "down", "k", "up", "ctrl+d", "ctrl+u":
+	case "list.down", "list.up", "list.page_down", "list.page_up":
 		// Use navigation handler for main list
 		if len(m.filtered) > 0 {
 			nav := NewNavigationHandler(len(m.filtered), false)
 			nav.cursor = m.cursor
-			newCursor := nav.HandleKey(msg.String())
+			newCursor := nav.HandleAction(action)
 			if newCursor != m.cursor {
 				m.cursor = newCursor
 				m.loadVisibleMetadata()
 			}
 		}
 		
-	case "g":
-		if m.lastKey == "g" {
+	case "list.top":
+		// Pressed twice, like vim's gg
+		if m.lastKey == action {
 			m.cursor = 0
 			m.lastKey = ""
 			m.loadVisibleMetadata()
 		} else {
-			m.lastKey = "g"
+			m.lastKey = action
 		}
 		
-	case "G":
+	case "list.bottom":
 		if len(m.filtered) > 0 {
 			m.cursor = len(m.filtered) - 1
 			m.loadVisibleMetadata()
 		}
 		
-	case "/":
+	case "list.search":
 		m.mode = ModeSearch
 		m.searchInput = m.searchQuery
 		
-	case "enter":
+	case "list.text_search":
+		// Full-text search of titles, tags and bodies
+		m.startTextSearch()
+		
+	case "list.open":
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 			file := m.filtered[m.cursor]
 			
@@ -385,6 +402,7 @@ func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					m.viewingFile = &file
 					m.editingField = ""
 					m.editBuffer = ""
+					m.loadTaskDependencies()
 				} else {
 					m.statusMsg = fmt.Sprintf("Error loading task: %v", err)
 				}
@@ -408,24 +426,24 @@ func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-	case "?":
+	case "list.help":
 		m.mode = ModeHelp
 		
 	// Task-specific keys
-	case "1", "2", "3":
+	case "list.set_priority_1", "list.set_priority_2", "list.set_priority_3":
 		// Set priority
-		priority := "p" + msg.String()
+		priority := "p" + strings.TrimPrefix(action, "list.set_priority_")
 		if err := m.updateTaskPriority(priority); err != nil {
 			m.statusMsg = fmt.Sprintf(ErrorFormat, err)
 		}
 		
-	case "0":
+	case "list.clear_priority":
 		// Clear priority
 		if err := m.updateTaskPriority(""); err != nil {
 			m.statusMsg = fmt.Sprintf(ErrorFormat, err)
 		}
 		
-	case "E":
+	case "list.external_edit":
 		// Edit task in external editor (uppercase for Edit action)
 		if m.config.Editor != "" && m.cursor < len(m.filtered) {
 			file := m.filtered[m.cursor]
@@ -434,40 +452,30 @@ func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.statusMsg = "No editor configured"
 		}
 		
-	case "u":
-		// Update task metadata - same as enter
+	case "list.undo":
+		// Undo the last change
+		m.undoLastChange()
+		
+	case "list.redo":
+		// Redo the last undone change
+		m.redoLastChange()
+		
+	case "list.start_timer":
+		// Start timer (clock in) on the selected task
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 			file := m.filtered[m.cursor]
-			
-			// Always load fresh from disk
 			if file.IsTask() {
-				if task, err := denote.ParseTaskFile(file.Path); err == nil {
-					m.mode = ModeTaskView
-					m.viewingTask = task
-					m.viewingProject = nil
-					m.viewingFile = &file
-					m.editingField = ""
-					m.editBuffer = ""
-				} else {
-					m.statusMsg = fmt.Sprintf("Error loading task: %v", err)
-				}
-			} else if file.IsProject() {
-				if project, err := denote.ParseProjectFile(file.Path); err == nil {
-					m.mode = ModeProjectView
-					m.viewingTask = nil
-					m.viewingProject = project
-					m.viewingFile = &file
-					m.editingField = ""
-					m.editBuffer = ""
-					m.projectViewTab = 0 // Start on overview tab
-					m.loadProjectTasks() // Load tasks assigned to this project
-				} else {
-					m.statusMsg = fmt.Sprintf("Error loading project: %v", err)
-				}
+				m.startTimer(file.Path)
+			} else {
+				m.statusMsg = "Time tracking only available for tasks"
 			}
 		}
 		
-	case "c":
+	case "list.stop_timer":
+		// Stop the running timer (clock out)
+		m.stopTimer("")
+		
+	case "list.create":
 		// Create new task or project depending on current view
 		if m.projectFilter {
 			// In project list, create a project
@@ -480,11 +488,24 @@ func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.resetCreateFields()
 		}
 		
-	case "f":
+	case "list.filter_menu":
 		// Filter menu
 		m.mode = ModeFilterMenu
 		
-	case "s":
+	case "list.views":
+		// Saved views from the config
+		m.mode = ModeViewMenu
+		
+	case "list.board":
+		// Board of tasks by status
+		m.mode = ModeBoard
+		m.loadBoard()
+		
+	case "list.agenda":
+		// Agenda of due and start dates
+		m.startAgenda()
+		
+	case "list.state_menu":
 		// State change menu - only for tasks, not projects
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 			file := m.filtered[m.cursor]
@@ -495,11 +516,11 @@ func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-	case "S":
+	case "list.sort_menu":
 		// Sort mode (uppercase S since lowercase is now for state)
 		m.mode = ModeSort
 		
-	case "x", "delete":
+	case "list.delete":
 		// Delete task confirmation
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 			file := m.filtered[m.cursor]
@@ -508,12 +529,12 @@ func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-	case "r":
+	case "list.reverse_sort":
 		// Toggle reverse sort
 		m.reverseSort = !m.reverseSort
 		m.sortFiles()
 		
-	case "l":
+	case "list.log":
 		// Log entry - only for tasks
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 			file := m.filtered[m.cursor]
@@ -526,7 +547,7 @@ func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-	case "d":
+	case "list.edit_due":
 		// Edit due date
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 			file := m.filtered[m.cursor]
@@ -551,7 +572,7 @@ func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-	case "e":
+	case "list.edit_estimate":
 		// Edit estimate
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 			file := m.filtered[m.cursor]
@@ -572,7 +593,7 @@ func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-	case "t":
+	case "list.edit_tags":
 		// Edit tags (lowercase for action)
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 			file := m.filtered[m.cursor]
@@ -635,7 +656,7 @@ func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-	case "P":
+	case "list.projects":
 		// Toggle project filter (uppercase for filter)
 		m.projectFilter = !m.projectFilter
 		if m.projectFilter {
@@ -652,7 +673,7 @@ func (m Model) handleTaskModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.sortFiles()
 		m.loadVisibleMetadata()
 		
-	case "T":
+	case "list.tasks":
 		// Go to task list (opposite of 'P' for projects, uppercase for filter)
 		if m.projectFilter {
 			// Currently in project list, switch to task list
@@ -829,9 +850,9 @@ func (m Model) handleStateMenuKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Open
 		var err error
 		if returnMode == ModeProjectView {
-			err = m.updateProjectTaskStatus(denote.TaskStatusOpen)
+			_, err = m.updateProjectTaskStatus(denote.TaskStatusOpen)
 		} else {
-			err = m.updateCurrentTaskStatus(denote.TaskStatusOpen)
+			_, err = m.updateCurrentTaskStatus(denote.TaskStatusOpen)
 		}
 		if err != nil {
 			m.statusMsg = fmt.Sprintf(ErrorFormat, err)
@@ -844,9 +865,9 @@ func (m Model) handleStateMenuKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Paused
 		var err error
 		if returnMode == ModeProjectView {
-			err = m.updateProjectTaskStatus(denote.TaskStatusPaused)
+			_, err = m.updateProjectTaskStatus(denote.TaskStatusPaused)
 		} else {
-			err = m.updateCurrentTaskStatus(denote.TaskStatusPaused)
+			_, err = m.updateCurrentTaskStatus(denote.TaskStatusPaused)
 		}
 		if err != nil {
 			m.statusMsg = fmt.Sprintf(ErrorFormat, err)
@@ -857,14 +878,17 @@ func (m Model) handleStateMenuKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		
 	case "d":
 		// Done
+		var next *denote.Task
 		var err error
 		if returnMode == ModeProjectView {
-			err = m.updateProjectTaskStatus(denote.TaskStatusDone)
+			next, err = m.updateProjectTaskStatus(denote.TaskStatusDone)
 		} else {
-			err = m.updateCurrentTaskStatus(denote.TaskStatusDone)
+			next, err = m.updateCurrentTaskStatus(denote.TaskStatusDone)
 		}
 		if err != nil {
 			m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+		} else if next != nil {
+			m.statusMsg = fmt.Sprintf("Task done, next occurrence #%d created", next.IndexID)
 		} else {
 			m.statusMsg = "Task status changed to done"
 		}
@@ -874,9 +898,9 @@ func (m Model) handleStateMenuKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Delegated
 		var err error
 		if returnMode == ModeProjectView {
-			err = m.updateProjectTaskStatus(denote.TaskStatusDelegated)
+			_, err = m.updateProjectTaskStatus(denote.TaskStatusDelegated)
 		} else {
-			err = m.updateCurrentTaskStatus(denote.TaskStatusDelegated)
+			_, err = m.updateCurrentTaskStatus(denote.TaskStatusDelegated)
 		}
 		if err != nil {
 			m.statusMsg = fmt.Sprintf(ErrorFormat, err)
@@ -889,9 +913,9 @@ func (m Model) handleStateMenuKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Dropped
 		var err error
 		if returnMode == ModeProjectView {
-			err = m.updateProjectTaskStatus(denote.TaskStatusDropped)
+			_, err = m.updateProjectTaskStatus(denote.TaskStatusDropped)
 		} else {
-			err = m.updateCurrentTaskStatus(denote.TaskStatusDropped)
+			_, err = m.updateCurrentTaskStatus(denote.TaskStatusDropped)
 		}
 		if err != nil {
 			m.statusMsg = fmt.Sprintf(ErrorFormat, err)
@@ -1179,6 +1203,7 @@ func (m Model) handleLogEntryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					// Reload the task with fresh content
 					if task, err := denote.ParseTaskFile(m.loggingFile.Path); err == nil {
 						m.viewingTask = task
+						m.loadTaskDependencies()
 						// Return to task view
 						m.mode = ModeTaskView
 						m.logInput = ""
@@ -1244,10 +1269,14 @@ func (m Model) handleProjectSelectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.mode = ModeCreate
 			} else if m.projectSelectFor == "update" && m.projectSelectTask != nil {
 				// Clear project assignment
-				m.projectSelectTask.TaskMetadata.ProjectID = ""
-				if err := task.UpdateTaskFile(m.projectSelectTask.File.Path, m.projectSelectTask.TaskMetadata); err != nil {
+				err := task.ModifyTaskFile(m.projectSelectTask.File.Path, func(meta *denote.TaskMetadata) error {
+					meta.ProjectID = ""
+					return nil
+				})
+				if err != nil {
 					m.statusMsg = fmt.Sprintf("Error updating task: %v", err)
 				} else {
+					m.projectSelectTask.TaskMetadata.ProjectID = ""
 					m.statusMsg = "Removed from project"
 					// Reload task if we are viewing it
 					if m.viewingTask != nil && m.viewingTask.File.Path == m.projectSelectTask.File.Path {
@@ -1266,10 +1295,14 @@ func (m Model) handleProjectSelectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.mode = ModeCreate
 			} else if m.projectSelectFor == "update" && m.projectSelectTask != nil {
 				// Update task with selected project
-				m.projectSelectTask.TaskMetadata.ProjectID = selected.File.ID
-				if err := task.UpdateTaskFile(m.projectSelectTask.File.Path, m.projectSelectTask.TaskMetadata); err != nil {
+				err := task.ModifyTaskFile(m.projectSelectTask.File.Path, func(meta *denote.TaskMetadata) error {
+					meta.ProjectID = selected.File.ID
+					return nil
+				})
+				if err != nil {
 					m.statusMsg = fmt.Sprintf("Error updating task: %v", err)
 				} else {
+					m.projectSelectTask.TaskMetadata.ProjectID = selected.File.ID
 					m.statusMsg = fmt.Sprintf("Added to project: %s", selected.ProjectMetadata.Title)
 					// Reload task if we are viewing it
 					if m.viewingTask != nil && m.viewingTask.File.Path == m.projectSelectTask.File.Path {
@@ -1325,38 +1358,28 @@ func (m Model) handleDateEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 			file := m.filtered[m.cursor]
 			
+			var err error
 			if file.IsTask() {
-				// Load fresh metadata from disk
-				if t, err := denote.ParseTaskFile(file.Path); err == nil {
-					t.TaskMetadata.DueDate = parsedDate
-					if err := task.UpdateTaskFile(file.Path, t.TaskMetadata); err != nil {
-						m.statusMsg = fmt.Sprintf(ErrorFormat, err)
-					} else {
-						if parsedDate == "" {
-							m.statusMsg = "Due date removed"
-						} else {
-							m.statusMsg = fmt.Sprintf("Due date set to %s", parsedDate)
-						}
-						// Force UI to refresh
-						m.loadVisibleMetadata()
-					}
-				}
+				err = task.ModifyTaskFile(file.Path, func(meta *denote.TaskMetadata) error {
+					meta.DueDate = parsedDate
+					return nil
+				})
 			} else if file.IsProject() {
-				// Load fresh metadata from disk
-				if project, err := denote.ParseProjectFile(file.Path); err == nil {
-					project.ProjectMetadata.DueDate = parsedDate
-					if err := denote.UpdateProjectFile(file.Path, project.ProjectMetadata); err != nil {
-						m.statusMsg = fmt.Sprintf(ErrorFormat, err)
-					} else {
-						if parsedDate == "" {
-							m.statusMsg = "Due date removed"
-						} else {
-							m.statusMsg = fmt.Sprintf("Due date set to %s", parsedDate)
-						}
-						// Force UI to refresh
-						m.loadVisibleMetadata()
-					}
+				err = task.ModifyProjectFile(file.Path, func(meta *denote.ProjectMetadata) error {
+					meta.DueDate = parsedDate
+					return nil
+				})
+			}
+			if err != nil {
+				m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+			} else if file.IsTask() || file.IsProject() {
+				if parsedDate == "" {
+					m.statusMsg = "Due date removed"
+				} else {
+					m.statusMsg = fmt.Sprintf("Due date set to %s", parsedDate)
 				}
+				// Force UI to refresh
+				m.loadVisibleMetadata()
 			}
 		}
 		m.mode = ModeNormal
@@ -1429,78 +1452,74 @@ func (m Model) handleTagsEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			
 			if file.IsTask() {
-				// Load fresh metadata from disk
-				if t, err := denote.ParseTaskFile(file.Path); err == nil {
-					oldPath := file.Path
-					// Always include "task" in metadata tags
-					t.TaskMetadata.Tags = []string{"task"}
-					t.TaskMetadata.Tags = append(t.TaskMetadata.Tags, newTags...)
+				oldPath := file.Path
+				
+				// First update the metadata, always including "task"
+				err := task.ModifyTaskFile(file.Path, func(meta *denote.TaskMetadata) error {
+					meta.Tags = append([]string{"task"}, newTags...)
+					return nil
+				})
+				if err != nil {
+					m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+				} else {
+					// Now rename the file to reflect new tags
+					allTags := []string{"task"} // Always include task tag
+					for _, tag := range newTags {
+						if tag != "task" {
+							allTags = append(allTags, tag)
+						}
+					}
 					
-					// First update the metadata
-					if err := task.UpdateTaskFile(file.Path, t.TaskMetadata); err != nil {
-						m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+					// Rename file
+					newPath, err := denote.RenameFileForTags(oldPath, allTags)
+					if err != nil {
+						m.statusMsg = fmt.Sprintf("Tags updated but rename failed: %v", err)
 					} else {
-						// Now rename the file to reflect new tags
-						allTags := []string{"task"} // Always include task tag
-						for _, tag := range newTags {
-							if tag != "task" {
-								allTags = append(allTags, tag)
-							}
+						if len(newTags) == 0 {
+							m.statusMsg = "Tags cleared"
+						} else {
+							m.statusMsg = fmt.Sprintf("Tags updated: %s", strings.Join(newTags, " "))
 						}
 						
-						// Rename file
-						newPath, err := denote.RenameFileForTags(oldPath, allTags)
-						if err != nil {
-							m.statusMsg = fmt.Sprintf("Tags updated but rename failed: %v", err)
-						} else {
-							if len(newTags) == 0 {
-								m.statusMsg = "Tags cleared"
-							} else {
-								m.statusMsg = fmt.Sprintf("Tags updated: %s", strings.Join(newTags, " "))
-							}
-							
-							// Trigger a rescan if the file was renamed
-							if newPath != oldPath {
-								m.scanFiles()
-							}
+						// Trigger a rescan if the file was renamed
+						if newPath != oldPath {
+							m.scanFiles()
 						}
 					}
 				}
 			} else if file.IsProject() {
-				// Load fresh metadata from disk
-				if project, err := denote.ParseProjectFile(file.Path); err == nil {
-					oldPath := file.Path
-					// Always include "project" in metadata tags
-					project.ProjectMetadata.Tags = []string{"project"}
-					project.ProjectMetadata.Tags = append(project.ProjectMetadata.Tags, newTags...)
+				oldPath := file.Path
+				
+				// First update the metadata, always including "project"
+				err := task.ModifyProjectFile(file.Path, func(meta *denote.ProjectMetadata) error {
+					meta.Tags = append([]string{"project"}, newTags...)
+					return nil
+				})
+				if err != nil {
+					m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+				} else {
+					// Now rename the file to reflect new tags
+					allTags := []string{"project"} // Always include project tag
+					for _, tag := range newTags {
+						if tag != "project" {
+							allTags = append(allTags, tag)
+						}
+					}
 					
-					// First update the metadata
-					if err := denote.UpdateProjectFile(file.Path, project.ProjectMetadata); err != nil {
-						m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+					// Rename file
+					newPath, err := denote.RenameFileForTags(oldPath, allTags)
+					if err != nil {
+						m.statusMsg = fmt.Sprintf("Tags updated but rename failed: %v", err)
 					} else {
-						// Now rename the file to reflect new tags
-						allTags := []string{"project"} // Always include project tag
-						for _, tag := range newTags {
-							if tag != "project" {
-								allTags = append(allTags, tag)
-							}
+						if len(newTags) == 0 {
+							m.statusMsg = "Tags cleared"
+						} else {
+							m.statusMsg = fmt.Sprintf("Tags updated: %s", strings.Join(newTags, " "))
 						}
 						
-						// Rename file
-						newPath, err := denote.RenameFileForTags(oldPath, allTags)
-						if err != nil {
-							m.statusMsg = fmt.Sprintf("Tags updated but rename failed: %v", err)
-						} else {
-							if len(newTags) == 0 {
-								m.statusMsg = "Tags cleared"
-							} else {
-								m.statusMsg = fmt.Sprintf("Tags updated: %s", strings.Join(newTags, " "))
-							}
-							
-							// Trigger a rescan if the file was renamed
-							if newPath != oldPath {
-								m.scanFiles()
-							}
+						// Trigger a rescan if the file was renamed
+						if newPath != oldPath {
+							m.scanFiles()
 						}
 					}
 				}
@@ -1581,48 +1600,16 @@ func (m Model) handleEstimateEditKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			
 			// Update the task
 			if file.IsTask() {
-				// Read the file content
-				content, err := os.ReadFile(file.Path)
+				err := task.ModifyTaskFile(file.Path, func(meta *denote.TaskMetadata) error {
+					meta.Estimate = estimate
+					return nil
+				})
 				if err != nil {
-					m.statusMsg = fmt.Sprintf("Failed to read file: %v", err)
-					m.mode = ModeNormal
-					m.editingField = ""
-					m.editBuffer = ""
-					m.editCursor = 0
-					return m, nil
-				}
-				
-				// Parse existing frontmatter
-				fm, err := denote.ParseFrontmatterFile(content)
-				if err != nil {
-					m.statusMsg = fmt.Sprintf("Failed to parse frontmatter: %v", err)
-					m.mode = ModeNormal
-					m.editingField = ""
-					m.editBuffer = ""
-					m.editCursor = 0
-					return m, nil
-				}
-				
-				// Update the metadata
-				if taskMeta, ok := fm.Metadata.(denote.TaskMetadata); ok {
-					taskMeta.Estimate = estimate
-					
-					// Write updated content
-					newContent, err := denote.WriteFrontmatterFile(taskMeta, fm.Content)
-					if err != nil {
-						m.statusMsg = fmt.Sprintf("Failed to write frontmatter: %v", err)
-					} else {
-						// Write back to file
-						if err := os.WriteFile(file.Path, newContent, 0644); err != nil {
-							m.statusMsg = fmt.Sprintf("Failed to update estimate: %v", err)
-						} else {
-							if estimate == 0 {
-								m.statusMsg = "Estimate cleared"
-							} else {
-								m.statusMsg = fmt.Sprintf("Estimate set to %d", estimate)
-							}
-						}
-					}
+					m.statusMsg = fmt.Sprintf("Failed to update estimate: %v", err)
+				} else if estimate == 0 {
+					m.statusMsg = "Estimate cleared"
+				} else {
+					m.statusMsg = fmt.Sprintf("Estimate set to %d", estimate)
 				}
 			}
 		}
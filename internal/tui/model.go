@@ -2,7 +2,6 @@ package tui
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
@@ -14,6 +13,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pdxmph/denote-tasks/internal/config"
 	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/query"
+	"github.com/pdxmph/denote-tasks/internal/search"
 	"github.com/pdxmph/denote-tasks/internal/task"
 )
 
@@ -25,6 +26,8 @@ type Model struct {
 	files      []denote.File
 	filtered   []denote.File
 	cursor     int
+	scanErrors []denote.ScanError // files that failed to parse cleanly
+	runningTimers []*denote.Task  // tasks with a running timer
 	
 	// UI State
 	width      int
@@ -36,12 +39,42 @@ type Model struct {
 	// Filters
 	searchQuery    string
 	searchInput    string
+	searchExpr     *query.Query // searchQuery parsed, when it is a query
+	searchErr      string       // Why searchQuery doesn't parse
+	blockedBy      map[string][]string // Unfinished dependencies by Denote ID, as of the last scan
 	areaFilter     string
 	priorityFilter string
 	stateFilter    string
 	soonFilter     bool
 	projectFilter  bool  // Filter to show only projects
 	
+	// Saved view
+	viewName string   // View from the config in effect, if any
+	groupBy  string   // Field the list is grouped by
+	columns  []string // Columns shown on task lines; nil for the default line
+	groups   []string // Group of each filtered file, when grouping
+	
+	// Full-text search mode
+	textIndex      *search.Index // Built on entering the mode; nil after a rescan
+	textInput      string
+	textResults    []search.Result
+	textCursor     int
+	returnToSearch bool // whether to return to search results after viewing one
+	
+	// Board mode
+	boardColumn   int              // Selected column, an index into boardStatuses
+	boardCursors  [5]int           // Selected card in each column
+	boardScroll   [5]int           // First visible card in each column
+	boardColumns  [][]*denote.Task // Cards in each column, built by loadBoard
+	returnToBoard bool             // whether to return to the board after task view
+	
+	// Agenda mode
+	agendaDay      time.Time               // Selected day, at midnight
+	agendaCursor   int                     // Selected item on that day
+	agendaMonth    bool                    // Month grid instead of a week
+	agendaDays     map[string][]agendaItem // Items by YYYY-MM-DD, built by loadAgenda
+	returnToAgenda bool                    // whether to return to the agenda after viewing an item
+	
 	// Preview
 	previewFile     *denote.File
 	previewScroll   int
@@ -67,6 +100,11 @@ type Model struct {
 	editCursor      int    // cursor position in edit buffer
 	returnToProject bool   // whether to return to project view after task view
 	
+	// Task dependencies (loaded when viewing a task)
+	dependencyTasks  []denote.Task // tasks the viewed task depends on
+	dependentTasks   []denote.Task // tasks waiting on the viewed task
+	dependencyCycles [][]string    // cycles involving the viewed task
+	
 	// Project view mode
 	projectViewTab     int // 0 = overview, 1 = tasks
 	projectTasks       []denote.Task // tasks assigned to current project
@@ -76,8 +114,9 @@ type Model struct {
 	// Display
 	err        error
 	statusMsg  string
-	lastKey    string
+	lastKey    string // Action of the previous key, for gg
 	fieldRenderer *FieldRenderer
+	keys          *keymap // Active key bindings
 	
 	// Log entry mode
 	logInput     string // Current log entry being typed
@@ -115,6 +154,10 @@ const (
 	ModeDateEdit
 	ModeTagsEdit
 	ModeEstimateEdit
+	ModeViewMenu
+	ModeTextSearch
+	ModeBoard
+	ModeAgenda
 )
 
 // ViewMode removed - we're always in task mode now
@@ -136,7 +179,21 @@ type fileEditedMsg struct {
 	path string
 }
 
+// timerTickMsg refreshes the elapsed time of running timers
+type timerTickMsg time.Time
+
+// timerTickInterval is how often running timers are redrawn
+const timerTickInterval = 30 * time.Second
+
 func NewModel(cfg *config.Config) (*Model, error) {
+	if err := loadTheme(cfg.TUI); err != nil {
+		return nil, err
+	}
+	keys, err := newKeymap(cfg.Keys)
+	if err != nil {
+		return nil, err
+	}
+	
 	// Use configured defaults for tasks mode (we're task-only now)
 	reverseSort := cfg.Tasks.SortOrder == "reverse"
 	sortBy := cfg.Tasks.SortBy
@@ -150,6 +207,7 @@ func NewModel(cfg *config.Config) (*Model, error) {
 		sortBy:          sortBy,
 		reverseSort:     reverseSort,
 		fieldRenderer:   NewFieldRenderer(),
+		keys:            keys,
 	}
 	
 	// Initial scan
@@ -165,12 +223,21 @@ func NewModel(cfg *config.Config) (*Model, error) {
 
 func (m *Model) scanFiles() error {
 	scanner := denote.NewScanner(m.config.NotesDirectory)
-	files, err := scanner.FindAllTaskAndProjectFiles()
+	result, err := scanner.Scan()
 	if err != nil {
 		return err
 	}
 	
-	m.files = files
+	m.files = result.Files
+	m.scanErrors = result.Errors
+	m.textIndex = nil
+	m.blockedBy = make(map[string][]string)
+	for _, t := range result.Tasks {
+		if t.IsBlocked() {
+			m.blockedBy[t.File.ID] = t.BlockedBy
+		}
+	}
+	m.runningTimers = denote.FindRunningTimers(result.Tasks)
 	
 	m.applyFilters()
 	m.sortFiles()
@@ -190,6 +257,16 @@ func (m *Model) loadVisibleMetadata() {
 func (m *Model) applyFilters() {
 	filtered := make([]denote.File, 0, len(m.files))
 	
+	var projectTitles map[string]string
+	if m.searchExpr != nil {
+		projectTitles = make(map[string]string)
+		for _, f := range m.files {
+			if f.IsProject() {
+				projectTitles[f.ID] = f.Title
+			}
+		}
+	}
+	
 	for _, f := range m.files {
 		// Always in task mode - only show tasks and projects
 		if !f.IsTask() && !f.IsProject() {
@@ -204,7 +281,11 @@ func (m *Model) applyFilters() {
 		}
 		
 		// Apply search filter
-		if m.searchQuery != "" {
+		if m.searchExpr != nil {
+			if !m.matchesSearchExpr(f, projectTitles) {
+				continue
+			}
+		} else if m.searchQuery != "" {
 			if strings.HasPrefix(m.searchQuery, "#") {
 				// Tag search mode
 				tagQuery := strings.TrimPrefix(m.searchQuery, "#")
@@ -304,12 +385,27 @@ func (m *Model) applyFilters() {
 }
 
 func (m *Model) sortFiles() {
-	// Sort without cached metadata - SortTaskFiles will read fresh from disk
-	denote.SortTaskFiles(m.filtered, m.sortBy, m.reverseSort, nil, nil)
+	// Load metadata through the index so comparators don't re-read files;
+	// entries are validated against each file's mtime and size
+	scanner := denote.NewScanner(m.config.NotesDirectory)
+	taskMeta, projectMeta := scanner.LoadMetadata(m.filtered)
+	denote.SortTaskFiles(m.filtered, m.sortBy, m.reverseSort, taskMeta, projectMeta)
+	
+	m.groups = nil
+	if m.groupBy != "" {
+		m.groupFiles(taskMeta, projectMeta)
+	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return nil
+	return timerTick()
+}
+
+// timerTick schedules the next running-timer redraw
+func timerTick() tea.Cmd {
+	return tea.Tick(timerTickInterval, func(t time.Time) tea.Msg {
+		return timerTickMsg(t)
+	})
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -320,7 +416,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 		
 	case tea.KeyMsg:
-		return m.handleKeyPress(msg)
+		return m.handleJournaledKeyPress(msg)
+		
+	case timerTickMsg:
+		// Nothing to update; receiving the message triggers a redraw
+		return m, timerTick()
 		
 	// Removed noteCreatedMsg case - we only create tasks now
 		
@@ -508,6 +608,9 @@ func (m *Model) loadProjectsForSelection() {
 
 func (m Model) createTask() tea.Cmd {
 	return func() tea.Msg {
+		op := denote.BeginOperation(m.config.NotesDirectory, "Create task: "+m.createTitle)
+		defer op.Commit()
+		
 		// Parse tags
 		tags := []string{}
 		if m.createTags != "" {
@@ -515,7 +618,7 @@ func (m Model) createTask() tea.Cmd {
 		}
 		
 		// Create the task
-		newTask, err := task.CreateTask(m.config.NotesDirectory, m.createTitle, "", tags, m.createArea)
+		newTask, err := task.CreateTaskAs(m.config.NotesDirectory, m.config.Tasks.FileType, m.createTitle, "", tags, m.createArea)
 		if err != nil {
 			return err
 		}
@@ -563,6 +666,13 @@ func (m Model) createTask() tea.Cmd {
 
 func (m Model) create() tea.Cmd {
 	return func() tea.Msg {
+		summary := "Create task: " + m.createTitle
+		if m.projectFilter {
+			summary = "Create project: " + m.createTitle
+		}
+		op := denote.BeginOperation(m.config.NotesDirectory, summary)
+		defer op.Commit()
+		
 		// Parse tags
 		tags := []string{}
 		if m.createTags != "" {
@@ -587,7 +697,7 @@ func (m Model) create() tea.Cmd {
 				}
 			}
 			
-			project, err := task.CreateProject(m.config.NotesDirectory, m.createTitle, "", tags)
+			project, err := task.CreateProjectAs(m.config.NotesDirectory, m.config.Tasks.FileType, m.createTitle, "", tags)
 			if err != nil {
 				return err
 			}
@@ -604,7 +714,7 @@ func (m Model) create() tea.Cmd {
 			return projectCreatedMsg{path: project.File.Path}
 		} else {
 			// Create a task
-			task, err := task.CreateTask(m.config.NotesDirectory, m.createTitle, "", tags, m.createArea)
+			task, err := task.CreateTaskAs(m.config.NotesDirectory, m.config.Tasks.FileType, m.createTitle, "", tags, m.createArea)
 			if err != nil {
 				return err
 			}
@@ -613,68 +723,170 @@ func (m Model) create() tea.Cmd {
 	}
 }
 
-// updateTaskPriority updates the priority of the current task or project
-func (m *Model) updateTaskPriority(priority string) error {
-	if m.cursor >= len(m.filtered) {
-		return fmt.Errorf("no item selected")
+// handleJournaledKeyPress handles a key press, recording any files it
+// changes as one undoable operation named after the resulting status message
+func (m Model) handleJournaledKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	op := denote.BeginOperation(m.config.NotesDirectory, "")
+	before := m.statusMsg
+	
+	next, cmd := m.handleKeyPress(msg)
+	
+	op.Summary = "Edit in TUI"
+	nm, ok := next.(Model)
+	if ok && nm.statusMsg != "" && nm.statusMsg != before {
+		op.Summary = nm.statusMsg
+	}
+	if err := op.Commit(); err != nil && ok {
+		nm.statusMsg = fmt.Sprintf("Failed to record undo history: %v", err)
+		return nm, cmd
 	}
 	
-	file := m.filtered[m.cursor]
+	return next, cmd
+}
+
+// undoLastChange reverts the most recent change in the undo journal
+func (m *Model) undoLastChange() {
+	entry, err := denote.Undo(m.config.NotesDirectory, false)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+		return
+	}
+	m.scanFiles()
+	m.statusMsg = fmt.Sprintf("Undid: %s", entry.Summary)
+}
+
+// redoLastChange re-applies the most recently undone change
+func (m *Model) redoLastChange() {
+	entry, err := denote.Redo(m.config.NotesDirectory, false)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+		return
+	}
+	m.scanFiles()
+	m.statusMsg = fmt.Sprintf("Redid: %s", entry.Summary)
+}
+
+// startTimer starts tracking time on a task, stopping any other running
+// timer first
+func (m *Model) startTimer(path string) {
+	t, err := denote.ParseTaskFile(path)
+	if err != nil {
+		m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+		return
+	}
 	
-	// Read the file content
-	content, err := os.ReadFile(file.Path)
+	stopped, err := task.StartTimer(t, m.runningTimers, time.Now())
 	if err != nil {
-		return fmt.Errorf(ErrorFailedTo, "read file", err)
+		m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+		return
+	}
+	m.scanFiles()
+	m.refreshViewingTask()
+	
+	m.statusMsg = fmt.Sprintf("Timer started on #%d", t.IndexID)
+	if len(stopped) > 0 {
+		m.statusMsg += fmt.Sprintf(" (stopped #%d)", stopped[0].IndexID)
+	}
+}
+
+// stopTimer stops the timer on the given task, or every running timer if
+// path is empty
+func (m *Model) stopTimer(path string) {
+	var paths []string
+	for _, t := range m.runningTimers {
+		if path == "" || t.File.Path == path {
+			paths = append(paths, t.File.Path)
+		}
+	}
+	if len(paths) == 0 {
+		m.statusMsg = "No timer running"
+		return
 	}
 	
-	// Parse existing frontmatter
-	fm, err := denote.ParseFrontmatterFile(content)
+	var total time.Duration
+	for _, p := range paths {
+		elapsed, err := task.StopTimer(p, time.Now())
+		if err != nil {
+			m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+			return
+		}
+		total += elapsed
+	}
+	m.scanFiles()
+	m.refreshViewingTask()
+	
+	m.statusMsg = fmt.Sprintf("Timer stopped (%s)", denote.FormatDuration(total))
+}
+
+// toggleChecklistItem checks or unchecks the n-th checklist item of the
+// viewed task
+func (m *Model) toggleChecklistItem(n int) {
+	if m.viewingTask == nil || m.viewingFile == nil {
+		return
+	}
+	
+	item, err := denote.ToggleChecklistItem(m.viewingFile.Path, n)
 	if err != nil {
-		return fmt.Errorf(ErrorFailedTo, "parse frontmatter", err)
+		m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+		return
+	}
+	m.refreshViewingTask()
+	
+	done, total := denote.ChecklistProgress(m.viewingTask.Checklist)
+	verb := "Unchecked"
+	if item.Done {
+		verb = "Checked"
+	}
+	m.statusMsg = fmt.Sprintf("%s item %d: %s (%d/%d)", verb, n, item.Text, done, total)
+}
+
+// refreshViewingTask reloads the viewed task's metadata from disk
+func (m *Model) refreshViewingTask() {
+	if m.viewingTask == nil || m.viewingFile == nil {
+		return
+	}
+	if t, err := denote.ParseTaskFile(m.viewingFile.Path); err == nil {
+		t.BlockedBy = m.viewingTask.BlockedBy
+		m.viewingTask = t
+	}
+}
+
+// updateTaskPriority updates the priority of the current task or project
+func (m *Model) updateTaskPriority(priority string) error {
+	if m.cursor >= len(m.filtered) {
+		return fmt.Errorf("no item selected")
 	}
 	
+	file := m.filtered[m.cursor]
+	
 	// Handle both tasks and projects
 	if file.IsTask() {
-		if taskMeta, ok := fm.Metadata.(denote.TaskMetadata); ok {
-			taskMeta.Priority = priority
-			
-			// Write updated content
-			newContent, err := denote.WriteFrontmatterFile(taskMeta, fm.Content)
-			if err != nil {
-				return fmt.Errorf(ErrorFailedTo, "write frontmatter", err)
-			}
-			
-			// Write to file
-			if err := os.WriteFile(file.Path, newContent, 0644); err != nil {
-				return fmt.Errorf(ErrorFailedTo, "write file", err)
-			}
-			
-			if priority == "" {
-				m.statusMsg = "Task priority removed"
-			} else {
-				m.statusMsg = fmt.Sprintf("Task priority updated to %s", priority)
-			}
+		err := task.ModifyTaskFile(file.Path, func(meta *denote.TaskMetadata) error {
+			meta.Priority = priority
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		
+		if priority == "" {
+			m.statusMsg = "Task priority removed"
+		} else {
+			m.statusMsg = fmt.Sprintf("Task priority updated to %s", priority)
 		}
 	} else if file.IsProject() {
-		if projectMeta, ok := fm.Metadata.(denote.ProjectMetadata); ok {
-			projectMeta.Priority = priority
-			
-			// Write updated content
-			newContent, err := denote.WriteFrontmatterFile(projectMeta, fm.Content)
-			if err != nil {
-				return fmt.Errorf(ErrorFailedTo, "write frontmatter", err)
-			}
-			
-			// Write to file
-			if err := os.WriteFile(file.Path, newContent, 0644); err != nil {
-				return fmt.Errorf(ErrorFailedTo, "write file", err)
-			}
-			
-			if priority == "" {
-				m.statusMsg = "Project priority removed"
-			} else {
-				m.statusMsg = fmt.Sprintf("Project priority updated to %s", priority)
-			}
+		err := task.ModifyProjectFile(file.Path, func(meta *denote.ProjectMetadata) error {
+			meta.Priority = priority
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		
+		if priority == "" {
+			m.statusMsg = "Project priority removed"
+		} else {
+			m.statusMsg = fmt.Sprintf("Project priority updated to %s", priority)
 		}
 	} else {
 		return fmt.Errorf("selected file is neither task nor project")
@@ -689,27 +901,29 @@ func (m *Model) updateTaskField(field, value string) error {
 		return fmt.Errorf("no task selected")
 	}
 	
-	// Read the file content
-	content, err := os.ReadFile(m.viewingFile.Path)
-	if err != nil {
-		return fmt.Errorf(ErrorFailedTo, "read file", err)
-	}
-	
-	// Parse existing frontmatter
-	fm, err := denote.ParseFrontmatterFile(content)
-	if err != nil {
-		return fmt.Errorf(ErrorFailedTo, "parse frontmatter", err)
-	}
-	
-	// Update the metadata
-	if taskMeta, ok := fm.Metadata.(denote.TaskMetadata); ok {
+	// Update the metadata, holding the directory lock across the read and
+	// the write
+	var taskMeta denote.TaskMetadata
+	wasDone := false
+	err := task.ModifyTaskFile(m.viewingFile.Path, func(meta *denote.TaskMetadata) error {
+		wasDone = meta.Status == denote.TaskStatusDone
 		switch field {
 		case "title":
-			taskMeta.Title = value
+			meta.Title = value
 		case "priority":
-			taskMeta.Priority = value
+			meta.Priority = value
 		case "status":
-			taskMeta.Status = value
+			meta.SetStatus(value, time.Now())
+			if value != denote.TaskStatusOpen {
+				meta.StopTimer(time.Now())
+			}
+		case "recurrence":
+			if value != "" {
+				if _, err := denote.ParseRecurrence(value); err != nil {
+					return err
+				}
+			}
+			meta.Recurrence = value
 		case "due_date":
 			// Parse natural language dates
 			if value != "" {
@@ -717,79 +931,87 @@ func (m *Model) updateTaskField(field, value string) error {
 				if err != nil {
 					return fmt.Errorf("invalid date: %s (try: 2d, 1w, friday, jan 15, 2024-01-15)", value)
 				}
-				taskMeta.DueDate = parsed
+				meta.DueDate = parsed
 			} else {
-				taskMeta.DueDate = ""
+				meta.DueDate = ""
 			}
 		case "area":
-			taskMeta.Area = value
+			meta.Area = value
 		case "estimate":
 			// Parse as int
 			var est int
 			fmt.Sscanf(value, "%d", &est)
-			taskMeta.Estimate = est
+			meta.Estimate = est
 		case "tags":
 			// Split by spaces and ensure "task" tag is always present
-			taskMeta.Tags = []string{"task"}
+			meta.Tags = []string{"task"}
 			if value != "" {
 				userTags := strings.Fields(value)
 				for _, tag := range userTags {
 					if tag != "project" && tag != "task" {
-						taskMeta.Tags = append(taskMeta.Tags, tag)
+						meta.Tags = append(meta.Tags, tag)
 					}
 				}
 			}
 		}
-		
-		// Write updated content
-		newContent, err := denote.WriteFrontmatterFile(taskMeta, fm.Content)
-		if err != nil {
-			return fmt.Errorf(ErrorFailedTo, "write frontmatter", err)
-		}
-		
-		// Check if we need to rename the file (for tag changes)
-		oldPath := m.viewingFile.Path
-		newPath := oldPath
-		
-		if field == "tags" {
-			// Combine filename tags with metadata tags, excluding 'task'
-			allTags := []string{"task"} // Always include task tag
-			for _, tag := range taskMeta.Tags {
-				if tag != "task" {
-					allTags = append(allTags, tag)
-				}
-			}
-			
-			// Rename file to reflect new tags
-			renamed, err := denote.RenameFileForTags(oldPath, allTags)
-			if err != nil {
-				return fmt.Errorf("failed to rename file: %w", err)
+		taskMeta = *meta
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	
+	// Check if we need to rename the file (for tag changes)
+	oldPath := m.viewingFile.Path
+	newPath := oldPath
+	
+	if field == "tags" {
+		// Combine filename tags with metadata tags, excluding 'task'
+		allTags := []string{"task"} // Always include task tag
+		for _, tag := range taskMeta.Tags {
+			if tag != "task" {
+				allTags = append(allTags, tag)
 			}
-			newPath = renamed
 		}
 		
-		// Write to file (at potentially new path)
-		if err := os.WriteFile(newPath, newContent, 0644); err != nil {
-			return fmt.Errorf(ErrorFailedTo, "write file", err)
+		// Rename file to reflect new tags
+		renamed, err := denote.RenameFileForTags(oldPath, allTags)
+		if err != nil {
+			return fmt.Errorf("failed to rename file: %w", err)
 		}
+		newPath = renamed
+	}
+	
+	// Update our in-memory copy
+	m.viewingTask.TaskMetadata = taskMeta
+	
+	// Update path references if file was renamed
+	if newPath != oldPath {
+		// Update viewing file path
+		m.viewingFile.Path = newPath
 		
-		// Update our in-memory copy
-		m.viewingTask.TaskMetadata = taskMeta
+		// No cache to update
 		
-		// Update path references if file was renamed
-		if newPath != oldPath {
-			// Update viewing file path
-			m.viewingFile.Path = newPath
-			
-			// No cache to update
-			
-			// Trigger a rescan to update the file list
-			m.scanFiles()
-		} else {
-			// No cache to update - we read fresh from disk
+		// Trigger a rescan to update the file list
+		m.scanFiles()
+	} else {
+		// No cache to update - we read fresh from disk
+	}
+	
+	m.statusMsg = fmt.Sprintf("Updated %s to %s", field, value)
+	
+	// Completing a recurring task creates its next instance
+	if field == "status" && value == denote.TaskStatusDone && !wasDone && taskMeta.Recurrence != "" {
+		current, err := denote.ParseTaskFile(newPath)
+		if err != nil {
+			return fmt.Errorf(ErrorFailedTo, "read task", err)
 		}
-		
-		m.statusMsg = fmt.Sprintf("Updated %s to %s", field, value)
+		next, err := task.CreateNextInstance(current, time.Now())
+		if err != nil {
+			return err
+		}
+		m.scanFiles()
+		m.statusMsg = fmt.Sprintf("Task done, next occurrence #%d created", next.IndexID)
 	}
 	
 	return nil
@@ -801,27 +1023,17 @@ func (m *Model) updateProjectField(field, value string) error {
 		return fmt.Errorf("no project selected")
 	}
 	
-	// Read the file content
-	content, err := os.ReadFile(m.viewingFile.Path)
-	if err != nil {
-		return fmt.Errorf(ErrorFailedTo, "read file", err)
-	}
-	
-	// Parse existing frontmatter
-	fm, err := denote.ParseFrontmatterFile(content)
-	if err != nil {
-		return fmt.Errorf(ErrorFailedTo, "parse frontmatter", err)
-	}
-	
-	// Update the metadata
-	if projectMeta, ok := fm.Metadata.(denote.ProjectMetadata); ok {
+	// Update the metadata, holding the directory lock across the read and
+	// the write
+	var projectMeta denote.ProjectMetadata
+	err := task.ModifyProjectFile(m.viewingFile.Path, func(meta *denote.ProjectMetadata) error {
 		switch field {
 		case "title":
-			projectMeta.Title = value
+			meta.Title = value
 		case "priority":
-			projectMeta.Priority = value
+			meta.Priority = value
 		case "status":
-			projectMeta.Status = value
+			meta.Status = value
 		case "due_date":
 			// Parse natural language dates
 			if value != "" {
@@ -829,122 +1041,112 @@ func (m *Model) updateProjectField(field, value string) error {
 				if err != nil {
 					return fmt.Errorf("invalid date: %s (try: 2d, 1w, friday, jan 15, 2024-01-15)", value)
 				}
-				projectMeta.DueDate = parsed
+				meta.DueDate = parsed
 			} else {
-				projectMeta.DueDate = ""
+				meta.DueDate = ""
 			}
 		case "area":
-			projectMeta.Area = value
+			meta.Area = value
 		case "tags":
 			// Split by spaces and ensure "project" tag is always present
-			projectMeta.Tags = []string{"project"}
+			meta.Tags = []string{"project"}
 			if value != "" {
 				userTags := strings.Fields(value)
 				for _, tag := range userTags {
 					if tag != "project" && tag != "task" {
-						projectMeta.Tags = append(projectMeta.Tags, tag)
+						meta.Tags = append(meta.Tags, tag)
 					}
 				}
 			}
 		}
-		
-		// Write updated content
-		newContent, err := denote.WriteFrontmatterFile(projectMeta, fm.Content)
-		if err != nil {
-			return fmt.Errorf(ErrorFailedTo, "write frontmatter", err)
-		}
-		
-		// Check if we need to rename the file (for tag or title changes)
-		oldPath := m.viewingFile.Path
-		newPath := oldPath
-		
-		if field == "tags" || field == "title" {
-			if field == "title" {
-				// For title changes, we need to update the slug
-				// Parse the current filename to get components
-				parser := denote.NewParser()
-				oldFile, err := parser.ParseFilename(filepath.Base(oldPath))
-				if err != nil {
-					return fmt.Errorf("failed to parse filename: %w", err)
-				}
-				
-				// Create new filename with updated title slug
-				// Convert title to slug (same logic as titleToSlug in create.go)
-				slug := strings.ToLower(projectMeta.Title)
-				slug = strings.Map(func(r rune) rune {
-					if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
-						return r
-					}
-					return '-'
-				}, slug)
-				for strings.Contains(slug, "--") {
-					slug = strings.ReplaceAll(slug, "--", "-")
-				}
-				slug = strings.Trim(slug, "-")
-				
-				newBasename := fmt.Sprintf("%s--%s", oldFile.ID, slug)
-				
-				// Add tags
-				if len(oldFile.Tags) > 0 {
-					newBasename += "__" + strings.Join(oldFile.Tags, "__")
-				}
-				newBasename += ".md"
-				
-				// Create full path
-				dir := filepath.Dir(oldPath)
-				newPath = filepath.Join(dir, newBasename)
-				
-				// Rename the file
-				if newPath != oldPath {
-					if err := os.Rename(oldPath, newPath); err != nil {
-						return fmt.Errorf("failed to rename file: %w", err)
-					}
-				}
-			} else {
-				// Tag changes - use existing logic
-				allTags := []string{"project"} // Always include project tag
-				for _, tag := range projectMeta.Tags {
-					if tag != "project" {
-						allTags = append(allTags, tag)
-					}
-				}
-				
-				// Rename file to reflect new tags
-				renamed, err := denote.RenameFileForTags(oldPath, allTags)
-				if err != nil {
-					return fmt.Errorf("failed to rename file: %w", err)
+		projectMeta = *meta
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	
+	// Check if we need to rename the file (for tag or title changes)
+	oldPath := m.viewingFile.Path
+	newPath := oldPath
+	
+	if field == "tags" || field == "title" {
+		if field == "title" {
+			// For title changes, we need to update the slug
+			// Parse the current filename to get components
+			parser := denote.NewParser()
+			oldFile, err := parser.ParseFilename(filepath.Base(oldPath))
+			if err != nil {
+				return fmt.Errorf("failed to parse filename: %w", err)
+			}
+			
+			// Create new filename with updated title slug
+			// Convert title to slug (same logic as titleToSlug in create.go)
+			slug := strings.ToLower(projectMeta.Title)
+			slug = strings.Map(func(r rune) rune {
+				if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+					return r
 				}
-				newPath = renamed
+				return '-'
+			}, slug)
+			for strings.Contains(slug, "--") {
+				slug = strings.ReplaceAll(slug, "--", "-")
 			}
-		}
-		
-		// Write to file (at potentially new path)
-		if err := os.WriteFile(newPath, newContent, 0644); err != nil {
-			return fmt.Errorf(ErrorFailedTo, "write file", err)
-		}
-		
-		// Update our in-memory copy
-		m.viewingProject.ProjectMetadata = projectMeta
-		
-		// Update path references if file was renamed
-		if newPath != oldPath {
-			// Update viewing file path
-			m.viewingFile.Path = newPath
+			slug = strings.Trim(slug, "-")
 			
-			// No cache to update
+			newBasename := fmt.Sprintf("%s--%s", oldFile.ID, slug)
 			
-			// Trigger a rescan to update the file list
-			m.scanFiles()
+			// Add tags
+			if len(oldFile.Tags) > 0 {
+				newBasename += "__" + strings.Join(oldFile.Tags, "__")
+			}
+			newBasename += filepath.Ext(oldPath)
+			
+			// Create full path
+			dir := filepath.Dir(oldPath)
+			newPath = filepath.Join(dir, newBasename)
+			
+			// Rename the file
+			if newPath != oldPath {
+				if err := denote.RenameFile(oldPath, newPath); err != nil {
+					return fmt.Errorf("failed to rename file: %w", err)
+				}
+			}
 		} else {
-			// No cache to update - we read fresh from disk
+			// Tag changes - use existing logic
+			allTags := []string{"project"} // Always include project tag
+			for _, tag := range projectMeta.Tags {
+				if tag != "project" {
+					allTags = append(allTags, tag)
+				}
+			}
+			
+			// Rename file to reflect new tags
+			renamed, err := denote.RenameFileForTags(oldPath, allTags)
+			if err != nil {
+				return fmt.Errorf("failed to rename file: %w", err)
+			}
+			newPath = renamed
 		}
+	}
+	
+	// Update our in-memory copy
+	m.viewingProject.ProjectMetadata = projectMeta
+	
+	// Update path references if file was renamed
+	if newPath != oldPath {
+		// Update viewing file path
+		m.viewingFile.Path = newPath
 		
-		m.statusMsg = fmt.Sprintf("Updated %s", field)
-		return nil
+		// No cache to update
+		
+		// Trigger a rescan to update the file list
+		m.scanFiles()
 	} else {
-		return fmt.Errorf("file is not a project")
+		// No cache to update - we read fresh from disk
 	}
 	
+	m.statusMsg = fmt.Sprintf("Updated %s", field)
 	return nil
 }
 
@@ -1010,6 +1212,39 @@ func (m *Model) loadProjectTasks() {
 	m.projectTasksCursor = 0
 }
 
+// updateSearchExpr parses the search text when it uses query syntax. While
+// a query doesn't parse, the last one that did stays in effect.
+func (m *Model) updateSearchExpr() {
+	m.searchErr = ""
+	if m.searchQuery == "" || strings.HasPrefix(m.searchQuery, "#") || !query.IsQuery(m.searchQuery) {
+		m.searchExpr = nil
+		return
+	}
+	q, err := query.Parse(m.searchQuery)
+	if err != nil {
+		m.searchErr = err.Error()
+		return
+	}
+	m.searchExpr = q
+}
+
+// matchesSearchExpr evaluates the search query against a file's metadata
+func (m *Model) matchesSearchExpr(f denote.File, projectTitles map[string]string) bool {
+	if f.IsTask() {
+		task, err := denote.ParseTaskFile(f.Path)
+		if err != nil {
+			return false
+		}
+		task.BlockedBy = m.blockedBy[task.File.ID]
+		return m.searchExpr.MatchTask(task, projectTitles)
+	}
+	project, err := denote.ParseProjectFile(f.Path)
+	if err != nil {
+		return false
+	}
+	return m.searchExpr.MatchProject(project)
+}
+
 // taskMatchesSearch performs fuzzy search on task metadata
 func (m *Model) taskMatchesSearch(task *denote.Task, query string) bool {
 	query = strings.ToLower(query)
@@ -1065,31 +1300,83 @@ func (m *Model) taskMatchesSearch(task *denote.Task, query string) bool {
 	return false
 }
 
-// updateCurrentTaskStatus updates the status of the currently selected task
-func (m *Model) updateCurrentTaskStatus(newStatus string) error {
+// loadTaskDependencies resolves dependencies for the task being viewed
+func (m *Model) loadTaskDependencies() {
+	m.dependencyTasks = nil
+	m.dependentTasks = nil
+	m.dependencyCycles = nil
+	if m.viewingTask == nil {
+		return
+	}
+	
+	// The index re-reads only files changed since they were cached
+	taskMeta, _ := denote.NewScanner(m.config.NotesDirectory).LoadMetadata(m.files)
+	var tasks []*denote.Task
+	for _, file := range m.files {
+		if task, ok := taskMeta[file.Path]; ok {
+			tasks = append(tasks, task)
+		}
+	}
+	
+	cycles := denote.ResolveDependencies(tasks)
+	viewingID := m.viewingTask.File.ID
+	
+	byID := make(map[string]*denote.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.File.ID] = task
+		if task.File.ID == viewingID {
+			m.viewingTask.BlockedBy = task.BlockedBy
+		}
+	}
+	
+	for _, depID := range m.viewingTask.TaskMetadata.DependsOn {
+		if dep, ok := byID[depID]; ok {
+			m.dependencyTasks = append(m.dependencyTasks, *dep)
+		}
+	}
+	for _, dependent := range denote.FindDependents(tasks, viewingID) {
+		m.dependentTasks = append(m.dependentTasks, *dependent)
+	}
+	
+	for _, cycle := range cycles {
+		for _, id := range cycle {
+			if id == viewingID {
+				m.dependencyCycles = append(m.dependencyCycles, cycle)
+				break
+			}
+		}
+	}
+}
+
+// updateCurrentTaskStatus updates the status of the currently selected task.
+// If a recurring task was completed, its next instance is returned.
+func (m *Model) updateCurrentTaskStatus(newStatus string) (*denote.Task, error) {
 	if m.cursor >= len(m.filtered) {
-		return fmt.Errorf("no task selected")
+		return nil, fmt.Errorf("no task selected")
 	}
 	
 	file := m.filtered[m.cursor]
 	if !file.IsTask() {
-		return fmt.Errorf("selected item is not a task")
+		return nil, fmt.Errorf("selected item is not a task")
 	}
 	
 	// Update the task status
-	err := denote.UpdateTaskStatus(file.Path, newStatus)
+	next, err := task.UpdateTaskStatus(file.Path, newStatus)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	
-	// No cache to update - we read fresh from disk
+	// Pick up the next instance of a recurring task
+	if next != nil {
+		m.scanFiles()
+	}
 	
-	return nil
+	return next, nil
 }
 
 // deleteFile deletes a file from the filesystem
 func (m *Model) deleteFile(path string) error {
-	return os.Remove(path)
+	return denote.RemoveFile(path)
 }
 
 // findTasksAffectedByProjectDeletion finds all tasks that reference the current project
@@ -1116,65 +1403,42 @@ func (m *Model) findTasksAffectedByProjectDeletion() {
 
 // clearProjectFromTask removes the project_id from a task
 func (m *Model) clearProjectFromTask(taskPath string) error {
-	// Read the file content
-	content, err := os.ReadFile(taskPath)
-	if err != nil {
-		return fmt.Errorf(ErrorFailedTo, "read file", err)
-	}
-	
-	// Parse existing frontmatter
-	fm, err := denote.ParseFrontmatterFile(content)
-	if err != nil {
-		return fmt.Errorf(ErrorFailedTo, "parse frontmatter", err)
-	}
-	
-	// Update the metadata
-	if taskMeta, ok := fm.Metadata.(denote.TaskMetadata); ok {
-		// Clear the project ID
-		taskMeta.ProjectID = ""
-		
-		// Write updated content
-		newContent, err := denote.WriteFrontmatterFile(taskMeta, fm.Content)
-		if err != nil {
-			return fmt.Errorf(ErrorFailedTo, "write frontmatter", err)
-		}
-		
-		// Write to file
-		if err := os.WriteFile(taskPath, newContent, 0644); err != nil {
-			return fmt.Errorf(ErrorFailedTo, "write file", err)
-		}
-		
-		// No cache to update
-	}
-	
-	return nil
+	return task.ModifyTaskFile(taskPath, func(meta *denote.TaskMetadata) error {
+		meta.ProjectID = ""
+		return nil
+	})
 }
 
-// updateProjectTaskStatus updates the status of the currently selected task in project view
-func (m *Model) updateProjectTaskStatus(newStatus string) error {
+// updateProjectTaskStatus updates the status of the currently selected task in project view.
+// If a recurring task was completed, its next instance is returned.
+func (m *Model) updateProjectTaskStatus(newStatus string) (*denote.Task, error) {
 	if m.projectTasksCursor >= len(m.projectTasks) {
-		return fmt.Errorf("no task selected")
+		return nil, fmt.Errorf("no task selected")
 	}
 	
-	task := &m.projectTasks[m.projectTasksCursor]
+	projectTask := &m.projectTasks[m.projectTasksCursor]
 	
 	// Update the task status
-	err := denote.UpdateTaskStatus(task.File.Path, newStatus)
+	next, err := task.UpdateTaskStatus(projectTask.File.Path, newStatus)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	
 	// Update the in-memory task (but no cache to update)
-	task.TaskMetadata.Status = newStatus
+	projectTask.TaskMetadata.Status = newStatus
 	
-	return nil
+	// Pick up the next instance of a recurring task
+	if next != nil {
+		m.scanFiles()
+		m.loadProjectTasks()
+	}
+	
+	return next, nil
 }
 
 func (m Model) View() string {
 	if m.err != nil {
-		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Render(fmt.Sprintf(ErrorFormat, m.err))
+		return errorStyle.Render(fmt.Sprintf(ErrorFormat, m.err))
 	}
 	
 	switch m.mode {
@@ -1200,6 +1464,14 @@ func (m Model) View() string {
 		return m.renderConfirmDelete()
 	case ModeFilterMenu:
 		return m.renderFilterMenu()
+	case ModeViewMenu:
+		return m.renderViewMenu()
+	case ModeTextSearch:
+		return m.renderTextSearch()
+	case ModeBoard:
+		return m.renderBoard()
+	case ModeAgenda:
+		return m.renderAgenda()
 	case ModePriorityFilter:
 		return m.renderPriorityFilter()
 	case ModeStateFilter:
@@ -1228,76 +1500,54 @@ func (m *Model) addLogEntry() error {
 		return fmt.Errorf("no file selected or empty log input")
 	}
 	
-	// Read the file
-	content, err := os.ReadFile(m.loggingFile.Path)
-	if err != nil {
-		return fmt.Errorf(ErrorFailedTo, "read file", err)
-	}
-	
-	// Find the end of frontmatter
-	lines := strings.Split(string(content), "\n")
-	frontmatterEnd := -1
-	inFrontmatter := false
-	
-	for i, line := range lines {
-		if i == 0 && line == "---" {
-			inFrontmatter = true
-			continue
+	return denote.RewriteFile(m.loggingFile.Path, func(content string) (string, error) {
+		// Find the end of frontmatter
+		lines := strings.Split(content, "\n")
+		frontmatterEnd := denote.FrontmatterLines(lines) - 1
+		
+		if frontmatterEnd < 0 {
+			return "", fmt.Errorf("no frontmatter found in file")
 		}
-		if inFrontmatter && line == "---" {
-			frontmatterEnd = i
-			break
+		
+		// Format the log entry with timestamp
+		now := time.Now()
+		// Use reference time to get day name: Mon Jan 2 15:04:05 MST 2006
+		timestamp := now.Format("[2006-01-02 Mon]")
+		logEntry := fmt.Sprintf("%s: %s", timestamp, m.logInput)
+		
+		// Build the new content
+		var newLines []string
+		
+		// Add frontmatter
+		newLines = append(newLines, lines[:frontmatterEnd+1]...)
+		
+		// Find where to insert the log entry
+		insertPos := frontmatterEnd + 1
+		
+		// Skip any existing blank lines after frontmatter
+		for insertPos < len(lines) && lines[insertPos] == "" {
+			insertPos++
 		}
-	}
-	
-	if frontmatterEnd == -1 {
-		return fmt.Errorf("no frontmatter found in file")
-	}
-	
-	// Format the log entry with timestamp
-	now := time.Now()
-	// Use reference time to get day name: Mon Jan 2 15:04:05 MST 2006
-	timestamp := now.Format("[2006-01-02 Mon]")
-	logEntry := fmt.Sprintf("%s: %s", timestamp, m.logInput)
-	
-	// Build the new content
-	var newLines []string
-	
-	// Add frontmatter
-	newLines = append(newLines, lines[:frontmatterEnd+1]...)
-	
-	// Find where to insert the log entry
-	insertPos := frontmatterEnd + 1
-	
-	// Skip any existing blank lines after frontmatter
-	for insertPos < len(lines) && lines[insertPos] == "" {
-		insertPos++
-	}
-	
-	// Add a blank line if needed
-	if insertPos == frontmatterEnd+1 || (insertPos < len(lines) && lines[insertPos-1] != "") {
-		newLines = append(newLines, "")
-	}
-	
-	// Add the log entry
-	newLines = append(newLines, logEntry)
-	
-	// Add the rest of the content
-	if insertPos < len(lines) {
-		// If there's existing content, ensure there's a blank line after our log entry
-		if lines[insertPos] != "" {
+		
+		// Add a blank line if needed
+		if insertPos == frontmatterEnd+1 || (insertPos < len(lines) && lines[insertPos-1] != "") {
 			newLines = append(newLines, "")
 		}
-		newLines = append(newLines, lines[insertPos:]...)
-	}
-	
-	// Write back to file
-	newContent := strings.Join(newLines, "\n")
-	if err := os.WriteFile(m.loggingFile.Path, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-	
-	return nil
+		
+		// Add the log entry
+		newLines = append(newLines, logEntry)
+		
+		// Add the rest of the content
+		if insertPos < len(lines) {
+			// If there's existing content, ensure there's a blank line after our log entry
+			if lines[insertPos] != "" {
+				newLines = append(newLines, "")
+			}
+			newLines = append(newLines, lines[insertPos:]...)
+		}
+		
+		return strings.Join(newLines, "\n"), nil
+	})
 }
 func (m Model) renderDateEditPopup() string {
 	// Render the normal view as background
@@ -1330,7 +1580,6 @@ func (m Model) renderDateEditPopup() string {
 		if err == nil {
 			content = append(content, fmt.Sprintf("→ %s", parsed))
 		} else {
-			errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
 			content = append(content, errorStyle.Render("→ Invalid date"))
 		}
 	} else {
@@ -1341,16 +1590,10 @@ func (m Model) renderDateEditPopup() string {
 	content = append(content, "Enter to save, Esc to cancel")
 	
 	// Style the popup with background color
-	popupStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("214")).
-		Background(lipgloss.Color("235")).
-		Foreground(lipgloss.Color("252")).
-		Padding(1, 2).
+	popup := popupStyle.Copy().
 		Width(50).
-		Align(lipgloss.Center)
-	
-	popup := popupStyle.Render(strings.Join(content, "\n"))
+		Align(lipgloss.Center).
+		Render(strings.Join(content, "\n"))
 	
 	// Center the popup over the background
 	return m.overlayPopup(bg, popup)
@@ -1382,16 +1625,11 @@ func (m Model) renderTagsEditPopup() string {
 	content = append(content, "Enter to save, Esc to cancel")
 	
 	// Style the popup with background color
-	popupStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("135")). // Purple for tags
-		Background(lipgloss.Color("235")).
-		Foreground(lipgloss.Color("252")).
-		Padding(1, 2).
+	popup := popupStyle.Copy().
+		BorderForeground(projectStyle.GetForeground()). // Project color for tags
 		Width(50).
-		Align(lipgloss.Center)
-	
-	popup := popupStyle.Render(strings.Join(content, "\n"))
+		Align(lipgloss.Center).
+		Render(strings.Join(content, "\n"))
 	
 	// Center the popup over the background
 	return m.overlayPopup(bg, popup)
@@ -1443,13 +1681,6 @@ func (m Model) renderEstimateEditPopup() string {
 	content = append(content, "Enter to save, Esc to cancel")
 	
 	// Style the popup with background color
-	popupStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("214")). // Orange for estimates
-		Background(lipgloss.Color("235")).
-		Foreground(lipgloss.Color("252")).
-		Padding(1, 2)
-	
 	popup := popupStyle.Render(strings.Join(content, "\n"))
 	
 	// Overlay popup on background
@@ -1,5 +1,7 @@
 package tui
 
+import "strings"
+
 // NavigationHandler handles common navigation patterns
 type NavigationHandler struct {
 	cursor int
@@ -35,6 +37,26 @@ func (n *NavigationHandler) HandleKey(key string) int {
 	return n.cursor
 }
 
+// HandleAction processes a navigation action such as list.down, whatever
+// its context, and returns the new cursor position
+func (n *NavigationHandler) HandleAction(action string) int {
+	switch strings.TrimPrefix(action, actionContext(action)+".") {
+	case "down":
+		return n.moveDown()
+	case "up":
+		return n.moveUp()
+	case "top":
+		return 0
+	case "bottom":
+		return n.max - 1
+	case "page_down":
+		return n.pageDown()
+	case "page_up":
+		return n.pageUp()
+	}
+	return n.cursor
+}
+
 func (n *NavigationHandler) moveDown() int {
 	if n.cursor < n.max-1 {
 		return n.cursor + 1
@@ -8,20 +8,6 @@ import (
 	"github.com/pdxmph/denote-tasks/internal/denote"
 )
 
-var (
-	tabStyle = lipgloss.NewStyle().
-		Padding(0, 2).
-		Border(lipgloss.NormalBorder(), false, false, true, false)
-		
-	activeTabStyle = tabStyle.Copy().
-		Foreground(lipgloss.Color("214")).
-		BorderForeground(lipgloss.Color("214")).
-		Bold(true)
-		
-	inactiveTabStyle = tabStyle.Copy().
-		Foreground(lipgloss.Color("241"))
-)
-
 func (m Model) renderProjectView() string {
 	if m.viewingProject == nil {
 		return MsgNoProjectSelected
@@ -62,7 +48,7 @@ func (m Model) renderProjectView() string {
 	
 	// Footer with hints
 	hints := m.getProjectViewHints()
-	footer := "\n" + hintStyle.Render(strings.Join(hints, " • "))
+	footer := "\n" + hintStyle.Render(joinHints(hints))
 	sections = append(sections, footer)
 	
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
@@ -104,34 +90,30 @@ func (m Model) renderProjectMain() string {
 	if statusValue == "" {
 		statusValue = "active"
 	}
-	var statusColor string
+	valueStyle := fieldValueStyle
 	switch statusValue {
 	case denote.ProjectStatusCompleted:
-		statusColor = "70" // green
+		valueStyle = doneStyle
 	case denote.ProjectStatusPaused:
-		statusColor = "214" // orange
+		valueStyle = pausedStyle
 	case denote.ProjectStatusCancelled:
-		statusColor = "241" // gray
-	default:
-		statusColor = "230" // default
+		valueStyle = droppedStyle
 	}
-	statusStyled := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor)).Render(statusValue)
+	statusStyled := valueStyle.Render(statusValue)
 	lines = append(lines, m.renderFieldWithHotkey("Status", statusStyled, "active", "s"))
 	
 	// Priority with color
 	if meta.Priority != "" {
-		var priorityColor string
+		priorityStyle := fieldValueStyle
 		switch meta.Priority {
 		case "p1":
-			priorityColor = "196" // red
+			priorityStyle = priorityHighStyle
 		case "p2":
-			priorityColor = "214" // orange
+			priorityStyle = priorityMediumStyle
 		case "p3":
-			priorityColor = "245" // gray
-		default:
-			priorityColor = "230"
+			priorityStyle = priorityLowStyle
 		}
-		priorityStyled := lipgloss.NewStyle().Foreground(lipgloss.Color(priorityColor)).Bold(true).Render(meta.Priority)
+		priorityStyled := priorityStyle.Copy().Bold(true).Render(meta.Priority)
 		lines = append(lines, m.renderFieldWithHotkey("Priority", priorityStyled, "none", "p"))
 	} else {
 		lines = append(lines, m.renderFieldWithHotkey("Priority", "", "not set", "p"))
@@ -143,7 +125,7 @@ func (m Model) renderProjectMain() string {
 		if denote.IsOverdue(meta.DueDate) {
 			dueValue = overdueStyle.Render(dueValue + " (OVERDUE!)")
 		} else if denote.IsDueThisWeek(meta.DueDate) {
-			dueValue = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(dueValue + " (this week)")
+			dueValue = soonStyle.Render(dueValue + " (this week)")
 		}
 		lines = append(lines, m.renderFieldWithHotkey("Due Date", dueValue, "not set", "d"))
 	} else {
@@ -269,7 +251,7 @@ func (m Model) renderProjectTaskLine(index int, task denote.Task) string {
 			due = overdueStyle.Render(dateStr)
 			isOverdue = true
 		} else if denote.IsDueSoon(task.TaskMetadata.DueDate, m.config.SoonHorizon) {
-			due = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(dateStr)
+			due = soonStyle.Render(dateStr)
 			isDueSoon = true
 		} else {
 			due = dateStr
@@ -308,7 +290,7 @@ func (m Model) renderProjectTaskLine(index int, task denote.Task) string {
 		status,
 		priority,
 		due,
-		truncate(title, ColumnWidthTitle),
+		titleWithChecklist(title, &task, ColumnWidthTitle),
 		truncate(tags, ColumnWidthTags),
 		truncate(area, ColumnWidthArea))
 	
@@ -350,57 +332,45 @@ func (m Model) getProjectBodyContent() string {
 		// Extract body from content (content after frontmatter)
 		content := m.viewingProject.Content
 		
-		// Find the end of frontmatter
-		if strings.HasPrefix(content, "---\n") {
-			// Find the closing ---
-			parts := strings.SplitN(content, "\n---\n", 3)
-			if len(parts) >= 2 {
-				body := strings.TrimSpace(parts[1])
-				if len(parts) == 3 {
-					body = strings.TrimSpace(parts[2])
-				}
-				return body
-			}
-		}
-		
-		// If no frontmatter, return the whole content
-		return strings.TrimSpace(content)
+		// Content after frontmatter, or all of it if there is none
+		return strings.TrimSpace(denote.Body(content))
 	}
 	
 	return ""
 }
 
 func (m Model) getProjectViewHints() []string {
+	k := m.keys
 	hints := []string{
-		"tab:switch tabs",
-		"q/esc:back",
-		"E:edit file",
-		"n:new task",
+		k.hint("switch tabs", "project.switch_tab"),
+		k.hint("back", "project.back"),
+		k.hint("edit file", "project.external_edit"),
+		k.hint("new task", "project.new_task"),
 	}
 	
 	if m.projectViewTab == 0 {
 		// Main tab (metadata + tasks) hints
 		// Metadata editing hints
 		hints = append(hints,
-			"T:title",
-			"p:priority",
-			"s:status",
-			"d:due date",
-			"a:area",
-			"t:tags",
+			k.hint("title", "project.edit_title"),
+			k.hint("priority", "project.edit_priority"),
+			k.hint("status", "project.edit_status"),
+			k.hint("due date", "project.edit_due"),
+			k.hint("area", "project.edit_area"),
+			k.hint("tags", "project.edit_tags"),
 		)
 		// Task list hints (if there are tasks)
 		if len(m.projectTasks) > 0 {
 			hints = append(hints,
-				"j/k:nav",
-				"enter:view task",
-				"1/2/3:task priority",
-				"S:sort",
-				"x:delete task",
+				k.hint("nav", "project.down", "project.up"),
+				k.hint("view task", "project.open_task"),
+				k.hint("task priority", "project.set_task_priority_1", "project.set_task_priority_2", "project.set_task_priority_3"),
+				k.hint("sort", "project.sort_menu"),
+				k.hint("delete task", "project.delete_task"),
 			)
 		}
 		// Project deletion
-		hints = append(hints, "X:delete project")
+		hints = append(hints, k.hint("delete project", "project.delete"))
 	}
 	// Tab 1 (Notes) has no special operations
 	
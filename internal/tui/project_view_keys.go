@@ -2,11 +2,11 @@ package tui
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/task"
 )
 
 func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -122,8 +122,28 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 	
 	// Normal navigation when not editing
-	switch msg.String() {
-	case "q", "esc":
+	switch action := m.keys.action("project", msg.String()); action {
+	case "project.back":
+		if m.returnToSearch {
+			m.projectTasks = nil
+			m.projectTasksCursor = 0
+			m.projectViewTab = 0
+			m.returnFromTextSearch()
+			return m, nil
+		}
+		if m.returnToAgenda {
+			m.mode = ModeAgenda
+			m.returnToAgenda = false
+			m.viewingProject = nil
+			m.viewingFile = nil
+			m.projectTasks = nil
+			m.projectTasksCursor = 0
+			m.projectViewTab = 0
+			m.applyFilters()
+			m.sortFiles()
+			m.loadAgenda()
+			return m, nil
+		}
 		m.mode = ModeNormal
 		m.viewingProject = nil
 		m.viewingFile = nil
@@ -136,7 +156,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.sortFiles()
 		m.loadVisibleMetadata()
 		
-	case "tab":
+	case "project.switch_tab":
 		// Switch between tabs
 		if m.projectViewTab == 0 {
 			m.projectViewTab = 1
@@ -144,7 +164,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.projectViewTab = 0
 		}
 		
-	case "E":
+	case "project.external_edit":
 		// Edit project file in external editor (uppercase for Edit action)
 		if m.config.Editor != "" && m.viewingFile != nil {
 			return m, m.editFile(m.viewingFile.Path)
@@ -153,7 +173,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		
 	// Field edit hotkeys - work on overview tab
-	case "T":
+	case "project.edit_title":
 		// Title field (uppercase - different from tags)
 		if m.projectViewTab == 0 {
 			m.editingField = "T"  // Title
@@ -162,7 +182,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.statusMsg = "Enter title:"
 		}
 		
-	case "p":
+	case "project.edit_priority":
 		if m.projectViewTab == 0 {
 			m.editingField = "p"  // Use single letter like renderField expects
 			m.editBuffer = strings.TrimPrefix(m.viewingProject.ProjectMetadata.Priority, "p")
@@ -170,7 +190,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.statusMsg = "Enter priority (0 to clear, 1/2/3):"
 		}
 		
-	case "s":
+	case "project.edit_status":
 		if m.projectViewTab == 0 {
 			m.editingField = "s"  // Use single letter
 			m.editBuffer = m.viewingProject.ProjectMetadata.Status
@@ -182,7 +202,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		
-	case "d":
+	case "project.edit_due":
 		if m.projectViewTab == 0 {
 			m.editingField = "d"  // Use single letter
 			m.editBuffer = m.viewingProject.ProjectMetadata.DueDate
@@ -190,7 +210,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.statusMsg = "Enter due date (YYYY-MM-DD or relative: 1d, 1w, tomorrow):"
 		}
 		
-	case "a":
+	case "project.edit_area":
 		if m.projectViewTab == 0 {
 			m.editingField = "a"  // Use single letter
 			m.editBuffer = m.viewingProject.ProjectMetadata.Area
@@ -198,7 +218,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.statusMsg = "Enter area:"
 		}
 		
-	case "t":
+	case "project.edit_tags":
 		// Tags field (lowercase for action)
 		if m.projectViewTab == 0 {
 			m.editingField = "t"  // Tags
@@ -226,7 +246,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.statusMsg = "Enter tags (space-separated):"
 		}
 		
-	case "n":
+	case "project.new_task":
 		// Create new task with this project pre-selected
 		m.mode = ModeCreate
 		m.resetCreateFields()
@@ -243,15 +263,15 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 		
 	// Keys for task navigation (on main tab)
-	case "j", "down", "k", "up", "G", "ctrl+d", "ctrl+u":
+	case "project.down", "project.up", "project.bottom", "project.page_down", "project.page_up":
 		if m.projectViewTab == 0 && len(m.projectTasks) > 0 {
 			// Use navigation handler for task list in project view
 			nav := NewNavigationHandler(len(m.projectTasks), false)
 			nav.cursor = m.projectTasksCursor
-			m.projectTasksCursor = nav.HandleKey(msg.String())
+			m.projectTasksCursor = nav.HandleAction(action)
 		}
 		
-	case "enter":
+	case "project.open_task":
 		if m.projectViewTab == 0 && len(m.projectTasks) > 0 && m.projectTasksCursor < len(m.projectTasks) {
 			// Open the selected task
 			task := m.projectTasks[m.projectTasksCursor]
@@ -262,10 +282,11 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.editBuffer = ""
 			m.editCursor = 0
 			m.returnToProject = true // Remember to return to project view
+			m.loadTaskDependencies()
 			// Keep the project reference!
 		}
 		
-	case "0":
+	case "project.clear_task_priority":
 		if m.projectViewTab == 0 && len(m.projectTasks) > 0 {
 			// Clear priority on selected task
 			task := &m.projectTasks[m.projectTasksCursor]
@@ -276,11 +297,11 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-	case "1", "2", "3":
+	case "project.set_task_priority_1", "project.set_task_priority_2", "project.set_task_priority_3":
 		if m.projectViewTab == 0 && len(m.projectTasks) > 0 {
 			// Set priority on selected task
 			task := &m.projectTasks[m.projectTasksCursor]
-			priority := "p" + msg.String()
+			priority := "p" + strings.TrimPrefix(action, "project.set_task_priority_")
 			if err := m.updateTaskPriorityFromProject(task, priority); err != nil {
 				m.statusMsg = fmt.Sprintf(ErrorFormat, err)
 			} else {
@@ -288,7 +309,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-	case "x":
+	case "project.delete_task":
 		// On main tab (0): delete selected task if cursor is on a task
 		if m.projectViewTab == 0 && len(m.projectTasks) > 0 && m.projectTasksCursor < len(m.projectTasks) {
 			// Deleting a task
@@ -296,7 +317,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		
-	case "X":
+	case "project.delete":
 		// Capital X to delete the project itself (only on main tab)
 		if m.projectViewTab == 0 {
 			// Deleting the project itself - find affected tasks first
@@ -306,7 +327,7 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		
 	// Sorting keys (same as task mode - uppercase S since lowercase s is for status)
-	case "S":
+	case "project.sort_menu":
 		// Open sort menu
 		m.mode = ModeSort
 		return m, nil
@@ -316,38 +337,19 @@ func (m Model) handleProjectViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 // updateTaskPriorityFromProject updates a task priority from the project view
-func (m *Model) updateTaskPriorityFromProject(task *denote.Task, priority string) error {
-	// Read the file content
-	content, err := os.ReadFile(task.File.Path)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	// Parse existing frontmatter
-	fm, err := denote.ParseFrontmatterFile(content)
+func (m *Model) updateTaskPriorityFromProject(t *denote.Task, priority string) error {
+	var updated denote.TaskMetadata
+	err := task.ModifyTaskFile(t.File.Path, func(meta *denote.TaskMetadata) error {
+		meta.Priority = priority
+		updated = *meta
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to parse frontmatter: %w", err)
-	}
-	
-	// Update the metadata
-	if taskMeta, ok := fm.Metadata.(denote.TaskMetadata); ok {
-		taskMeta.Priority = priority
-		
-		// Write updated content
-		newContent, err := denote.WriteFrontmatterFile(taskMeta, fm.Content)
-		if err != nil {
-			return fmt.Errorf("failed to write frontmatter: %w", err)
-		}
-		
-		// Write to file
-		if err := os.WriteFile(task.File.Path, newContent, 0644); err != nil {
-			return fmt.Errorf("failed to write file: %w", err)
-		}
-		
-		// Update our in-memory copy
-		task.TaskMetadata = taskMeta
+		return err
 	}
 	
+	// Update our in-memory copy
+	t.TaskMetadata = updated
 	return nil
 }
 
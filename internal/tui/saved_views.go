@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/pdxmph/denote-tasks/internal/denote"
+)
+
+// applyView replaces the filters, sort and layout with those of a view
+// from the config
+func (m *Model) applyView(name string) {
+	view, err := m.config.View(name)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return
+	}
+
+	m.viewName = name
+	m.areaFilter = view.Area
+	m.priorityFilter = view.Priority
+	m.stateFilter = view.State
+	m.soonFilter = view.Soon
+	m.searchInput = ""
+	m.searchQuery = view.Where
+	m.updateSearchExpr()
+
+	m.sortBy, m.reverseSort = m.defaultSort()
+	if view.Sort != "" {
+		m.sortBy = view.Sort
+	}
+	if view.Order != "" {
+		m.reverseSort = view.Order == "reverse"
+	}
+	m.groupBy = view.Group
+	m.columns = view.Columns
+
+	m.cursor = 0
+	m.applyFilters()
+	m.sortFiles()
+	m.loadVisibleMetadata()
+}
+
+// clearView drops the current view and its filters, going back to the
+// configured sort
+func (m *Model) clearView() {
+	m.viewName = ""
+	m.areaFilter = ""
+	m.priorityFilter = ""
+	m.stateFilter = ""
+	m.soonFilter = false
+	m.searchInput = ""
+	m.searchQuery = ""
+	m.updateSearchExpr()
+	m.sortBy, m.reverseSort = m.defaultSort()
+	m.groupBy = ""
+	m.columns = nil
+
+	m.cursor = 0
+	m.statusMsg = "View cleared"
+	m.applyFilters()
+	m.sortFiles()
+	m.loadVisibleMetadata()
+}
+
+// defaultSort returns the sort from the [tasks] config
+func (m *Model) defaultSort() (string, bool) {
+	sortBy := m.config.Tasks.SortBy
+	if sortBy == "" {
+		sortBy = "due"
+	}
+	return sortBy, m.config.Tasks.SortOrder == "reverse"
+}
+
+func (m Model) handleViewMenuKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	names := m.config.ViewNames()
+
+	switch key := msg.String(); key {
+	case "esc", "ctrl+c", "q":
+		m.mode = ModeNormal
+
+	case "c", "x":
+		m.mode = ModeNormal
+		m.clearView()
+
+	default:
+		if n, err := strconv.Atoi(key); err == nil && n >= 1 && n <= len(names) {
+			m.mode = ModeNormal
+			m.applyView(names[n-1])
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) renderViewMenu() string {
+	prompt := titleStyle.Render("Views")
+
+	current := "\n\nCurrent: None"
+	if m.viewName != "" {
+		current = "\n\nCurrent: " + m.viewName
+	}
+
+	names := m.config.ViewNames()
+	var options strings.Builder
+	if len(names) == 0 {
+		options.WriteString("\n\nNo views configured. Add [views.NAME] sections to config.toml.")
+	} else {
+		options.WriteString("\n\nSwitch to:")
+		for i, name := range names {
+			if i >= 9 {
+				break
+			}
+			options.WriteString(fmt.Sprintf("\n  (%d) %s", i+1, name))
+		}
+	}
+	options.WriteString("\n\n  (c) Clear view and filters\n\n  Esc to close")
+
+	return prompt + baseStyle.Render(current) + helpStyle.Render(options.String())
+}
+
+// fileGroup returns the value a file is grouped by, "" if it has none
+func (m *Model) fileGroup(f denote.File, taskMeta map[string]*denote.Task, projectMeta map[string]*denote.Project) string {
+	if t := taskMeta[f.Path]; t != nil {
+		switch m.groupBy {
+		case "area":
+			return t.Area
+		case "project":
+			if t.ProjectID == "" {
+				return ""
+			}
+			for _, file := range m.files {
+				if file.ID == t.ProjectID && file.IsProject() {
+					if p := projectMeta[file.Path]; p != nil && p.ProjectMetadata.Title != "" {
+						return p.ProjectMetadata.Title
+					}
+					return file.Title
+				}
+			}
+			return t.ProjectID
+		case "priority":
+			return t.Priority
+		case "status":
+			if t.Status == "" {
+				return denote.TaskStatusOpen
+			}
+			return t.Status
+		}
+	} else if p := projectMeta[f.Path]; p != nil {
+		switch m.groupBy {
+		case "area":
+			return p.Area
+		case "project":
+			return p.ProjectMetadata.Title
+		case "priority":
+			return p.Priority
+		case "status":
+			if p.Status == "" {
+				return denote.ProjectStatusActive
+			}
+			return p.Status
+		}
+	}
+	return ""
+}
+
+// groupFiles keeps files of the same group together, in the order of the
+// current sort. Groups are alphabetical with the empty group last.
+func (m *Model) groupFiles(taskMeta map[string]*denote.Task, projectMeta map[string]*denote.Project) {
+	m.groups = make([]string, len(m.filtered))
+	for i, f := range m.filtered {
+		m.groups[i] = m.fileGroup(f, taskMeta, projectMeta)
+	}
+
+	order := make([]int, len(m.filtered))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := m.groups[order[i]], m.groups[order[j]]
+		if a == "" || b == "" {
+			return a != "" && b == ""
+		}
+		return strings.ToLower(a) < strings.ToLower(b)
+	})
+
+	files := make([]denote.File, len(order))
+	groups := make([]string, len(order))
+	for i, idx := range order {
+		files[i] = m.filtered[idx]
+		groups[i] = m.groups[idx]
+	}
+	m.filtered = files
+	m.groups = groups
+}
+
+// groupHeading returns the heading shown above a group
+func (m Model) groupHeading(value string) string {
+	if value == "" {
+		value = "No " + m.groupBy
+	}
+	return titleStyle.Render(value)
+}
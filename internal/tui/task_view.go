@@ -3,29 +3,12 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 	
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pdxmph/denote-tasks/internal/denote"
 )
 
-var (
-	fieldLabelStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("99"))
-		
-	fieldValueStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("230"))
-		
-	editingStyle = lipgloss.NewStyle().
-		Background(lipgloss.Color("62")).
-		Foreground(lipgloss.Color("230")).
-		Bold(true)
-		
-	hintStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241")).
-		Italic(true)
-)
-
 func (m Model) renderTaskView() string {
 	if m.viewingTask == nil && m.viewingProject == nil {
 		return "No task or project selected"
@@ -71,7 +54,7 @@ func (m Model) renderTaskView() string {
 			// Highlight log entries
 			if strings.Contains(line, "]:") && strings.HasPrefix(line, "[") {
 				// This looks like a log entry
-				styledLines = append(styledLines, lipgloss.NewStyle().Foreground(lipgloss.Color("99")).Render(line))
+				styledLines = append(styledLines, logEntryStyle.Render(line))
 			} else {
 				styledLines = append(styledLines, line)
 			}
@@ -89,23 +72,36 @@ func (m Model) renderTaskView() string {
 		sections = append(sections, "\n"+helpStyle.Render("(no notes)"))
 	}
 	
-	// Footer with hints
+	// Footer with hints, as currently bound
+	k := m.keys
 	hints := []string{
-		"q/esc:back",
-		"E:edit file",
-		"p:priority",
-		"s:status",
-		"d:due date",
-		"a:area",
-		"t:tags",
-		"r:rename",
+		k.hint("back", "task.back"),
+		k.hint("edit file", "task.external_edit"),
+		k.hint("priority", "task.edit_priority"),
+		k.hint("status", "task.edit_status"),
+		k.hint("due date", "task.edit_due"),
+		k.hint("area", "task.edit_area"),
+		k.hint("tags", "task.edit_tags"),
+		k.hint("rename", "task.rename"),
 	}
 	if m.viewingTask != nil {
-		hints = append(hints, "j:project")
-		hints = append(hints, "e:estimate")
-		hints = append(hints, "l:log")
+		hints = append(hints, k.hint("project", "task.set_project"))
+		hints = append(hints, k.hint("estimate", "task.edit_estimate"))
+		hints = append(hints, k.hint("recurrence", "task.edit_recurrence"))
+		hints = append(hints, k.hint("log", "task.log"))
+		if len(m.viewingTask.Checklist) > 0 {
+			hints = append(hints, k.hint("check item", "task.toggle_item"))
+		}
+		if m.viewingTask.RunningTimer() != nil {
+			hints = append(hints, k.hint("stop timer", "task.stop_timer"))
+		} else {
+			hints = append(hints, k.hint("start timer", "task.start_timer"))
+		}
+		if len(m.dependencyTasks) > 0 {
+			hints = append(hints, k.hint("go to blocker", "task.goto_blocker"))
+		}
 	}
-	footer := "\n" + hintStyle.Render(strings.Join(hints, " • "))
+	footer := "\n" + hintStyle.Render(joinHints(hints))
 	sections = append(sections, footer)
 	
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
@@ -125,18 +121,18 @@ func (m Model) renderTaskDetails() string {
 	if statusValue == "" {
 		statusValue = "open"
 	}
-	var statusColor string
+	valueStyle := fieldValueStyle
 	switch statusValue {
 	case denote.TaskStatusDone:
-		statusColor = "70" // green
+		valueStyle = doneStyle
 	case denote.TaskStatusPaused:
-		statusColor = "214" // orange
+		valueStyle = pausedStyle
+	case denote.TaskStatusDelegated:
+		valueStyle = delegatedStyle
 	case denote.TaskStatusDropped:
-		statusColor = "241" // gray
-	default:
-		statusColor = "230" // default
+		valueStyle = droppedStyle
 	}
-	statusStyled := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor)).Render(statusValue)
+	statusStyled := valueStyle.Render(statusValue)
 	lines = append(lines, m.renderFieldWithHotkey("Status", statusStyled, "open", "s"))
 	
 	// Priority - use specialized renderer
@@ -177,6 +173,12 @@ func (m Model) renderTaskDetails() string {
 		lines = append(lines, m.renderFieldWithHotkey("Estimate", "", "not set", "e"))
 	}
 	
+	// Recurrence
+	lines = append(lines, m.renderFieldWithHotkey("Recurrence", meta.Recurrence, "not set", "R"))
+	
+	// Tracked time
+	lines = append(lines, m.renderFieldWithHotkey("Time Spent", renderTrackedTime(meta), "none", ""))
+	
 	// Tags (editable) - filter out system tags
 	tagsDisplay := ""
 	var displayTags []string
@@ -232,6 +234,18 @@ func (m Model) renderTaskDetails() string {
 		lines = append(lines, m.renderFieldWithHotkey("Assignee", meta.Assignee, "not set", ""))
 	}
 	
+	// Checklist
+	if len(task.Checklist) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, m.renderChecklist())
+	}
+	
+	// Dependencies
+	if len(m.dependencyTasks) > 0 || len(m.dependentTasks) > 0 || len(m.dependencyCycles) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, m.renderDependencies())
+	}
+	
 	// File info
 	lines = append(lines, "")
 	lines = append(lines, m.renderFieldWithHotkey("File", m.viewingFile.Path, "", ""))
@@ -241,6 +255,104 @@ func (m Model) renderTaskDetails() string {
 	return strings.Join(lines, "\n")
 }
 
+// renderChecklist renders the task's checklist items with their numbers
+func (m Model) renderChecklist() string {
+	items := m.viewingTask.Checklist
+	done, total := denote.ChecklistProgress(items)
+	
+	lines := []string{"  " + fieldLabelStyle.Render(fmt.Sprintf("Checklist (%d/%d):", done, total))}
+	for i, item := range items {
+		line := fmt.Sprintf("%s%2d. [ ] %s", strings.Repeat(" ", item.Indent), i+1, item.Text)
+		if item.Done {
+			line = doneStyle.Render(fmt.Sprintf("%s%2d. [x] %s", strings.Repeat(" ", item.Indent), i+1, item.Text))
+		}
+		lines = append(lines, "    "+line)
+	}
+	
+	return strings.Join(lines, "\n")
+}
+
+// renderTrackedTime summarizes logged time against the estimate
+func renderTrackedTime(meta denote.TaskMetadata) string {
+	if len(meta.TimeLog) == 0 {
+		return ""
+	}
+	
+	now := time.Now()
+	value := denote.FormatDuration(meta.TrackedTime(now))
+	if meta.Estimate > 0 {
+		value += fmt.Sprintf(" (estimate %d)", meta.Estimate)
+	}
+	if entry := meta.RunningTimer(); entry != nil {
+		value += " " + overdueStyle.Render(fmt.Sprintf("⏱ running %s", denote.FormatDuration(entry.Duration(now))))
+	}
+	return value
+}
+
+// renderDependencies renders the blocking and blocked task lists
+func (m Model) renderDependencies() string {
+	var lines []string
+	
+	if task := m.viewingTask; task.IsBlocked() {
+		lines = append(lines, "  "+overdueStyle.Render(fmt.Sprintf("Blocked by %d unfinished task(s)", len(task.BlockedBy))))
+	}
+	
+	if len(m.dependencyTasks) > 0 {
+		lines = append(lines, "  "+fieldLabelStyle.Render("Depends on:"))
+		for _, dep := range m.dependencyTasks {
+			lines = append(lines, "    "+renderDependencyLine(dep))
+		}
+	}
+	
+	if len(m.dependentTasks) > 0 {
+		lines = append(lines, "  "+fieldLabelStyle.Render("Blocks:"))
+		for _, dep := range m.dependentTasks {
+			lines = append(lines, "    "+renderDependencyLine(dep))
+		}
+	}
+	
+	for _, cycle := range m.dependencyCycles {
+		lines = append(lines, "  "+overdueStyle.Render(fmt.Sprintf("⚠ Dependency cycle: %s", m.formatCycle(cycle))))
+	}
+	
+	return strings.Join(lines, "\n")
+}
+
+// renderDependencyLine renders a single dependency with its status
+func renderDependencyLine(task denote.Task) string {
+	line := fmt.Sprintf("#%d %s", task.TaskMetadata.IndexID, task.TaskMetadata.Title)
+	switch task.TaskMetadata.Status {
+	case denote.TaskStatusDone:
+		return doneStyle.Render("✓ " + line)
+	case denote.TaskStatusDropped:
+		return droppedStyle.Render("⨯ " + line)
+	default:
+		return "○ " + line
+	}
+}
+
+// formatCycle renders a dependency cycle using index IDs where known
+func (m Model) formatCycle(cycle []string) string {
+	known := make(map[string]int)
+	known[m.viewingTask.File.ID] = m.viewingTask.TaskMetadata.IndexID
+	for _, t := range m.dependencyTasks {
+		known[t.File.ID] = t.TaskMetadata.IndexID
+	}
+	for _, t := range m.dependentTasks {
+		known[t.File.ID] = t.TaskMetadata.IndexID
+	}
+	
+	parts := make([]string, len(cycle))
+	for i, id := range cycle {
+		if indexID, ok := known[id]; ok {
+			parts[i] = fmt.Sprintf("#%d", indexID)
+		} else {
+			parts[i] = id
+		}
+	}
+	return strings.Join(parts, " → ")
+}
+
 func (m Model) renderProjectDetails() string {
 	project := m.viewingProject
 	meta := project.ProjectMetadata
@@ -325,40 +437,14 @@ func (m Model) getBodyContent() string {
 		// Extract body from content (content after frontmatter)
 		content := m.viewingTask.Content
 		
-		// Find the end of frontmatter
-		if strings.HasPrefix(content, "---\n") {
-			// Find the closing ---
-			parts := strings.SplitN(content, "\n---\n", 3)
-			if len(parts) >= 2 {
-				body := strings.TrimSpace(parts[1])
-				if len(parts) == 3 {
-					body = strings.TrimSpace(parts[2])
-				}
-				return body
-			}
-		}
-		
-		// If no frontmatter, return the whole content
-		return strings.TrimSpace(content)
+		// Content after frontmatter, or all of it if there is none
+		return strings.TrimSpace(denote.Body(content))
 	} else if m.viewingProject != nil {
 		// Same for projects
 		content := m.viewingProject.Content
 		
-		// Find the end of frontmatter
-		if strings.HasPrefix(content, "---\n") {
-			// Find the closing ---
-			parts := strings.SplitN(content, "\n---\n", 3)
-			if len(parts) >= 2 {
-				body := strings.TrimSpace(parts[1])
-				if len(parts) == 3 {
-					body = strings.TrimSpace(parts[2])
-				}
-				return body
-			}
-		}
-		
-		// If no frontmatter, return the whole content
-		return strings.TrimSpace(content)
+		// Content after frontmatter, or all of it if there is none
+		return strings.TrimSpace(denote.Body(content))
 	}
 	
 	return ""
@@ -412,6 +498,7 @@ func (m Model) renderFieldWithHotkey(label, value, emptyText, hotkey string) str
 		"t": "estimate",
 		"g": "tags",
 		"j": "project",
+		"R": "recurrence",
 	}
 	
 	fieldName := hotkey
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	
 	"github.com/charmbracelet/bubbletea"
@@ -65,6 +66,16 @@ func (m Model) handleTaskViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					if err := m.updateTaskField("tags", m.editBuffer); err != nil {
 						m.statusMsg = fmt.Sprintf(ErrorFormat, err)
 					}
+				case "recurrence":
+					if err := m.updateTaskField("recurrence", m.editBuffer); err != nil {
+						m.statusMsg = fmt.Sprintf(ErrorFormat, err)
+					}
+				case "check":
+					if n, err := strconv.Atoi(m.editBuffer); err == nil {
+						m.toggleChecklistItem(n)
+					} else {
+						m.statusMsg = "Enter a checklist item number"
+					}
 				}
 			} else if m.viewingProject != nil {
 				// Handle project updates
@@ -152,9 +163,27 @@ func (m Model) handleTaskViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 	
 	// Normal task view navigation
-	switch msg.String() {
-	case "q", "esc":
-		if m.returnToProject && m.viewingProject != nil {
+	switch action := m.keys.action("task", msg.String()); action {
+	case "task.back":
+		if m.returnToSearch {
+			m.returnFromTextSearch()
+		} else if m.returnToAgenda {
+			m.mode = ModeAgenda
+			m.returnToAgenda = false
+			m.viewingTask = nil
+			m.viewingFile = nil
+			m.applyFilters()
+			m.sortFiles()
+			m.loadAgenda()
+		} else if m.returnToBoard {
+			m.mode = ModeBoard
+			m.returnToBoard = false
+			m.viewingTask = nil
+			m.viewingFile = nil
+			m.applyFilters()
+			m.sortFiles()
+			m.loadBoard()
+		} else if m.returnToProject && m.viewingProject != nil {
 			// Return to project view
 			m.mode = ModeProjectView
 			m.viewingTask = nil
@@ -174,13 +203,13 @@ func (m Model) handleTaskViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.loadVisibleMetadata()
 		}
 		
-	case "E":
+	case "task.external_edit":
 		// Edit in external editor (uppercase for Edit action)
 		if m.config.Editor != "" && m.viewingFile != nil {
 			return m, m.editFile(m.viewingFile.Path)
 		}
 		
-	case "l":
+	case "task.log":
 		// Log entry - only for tasks
 		if m.viewingTask != nil && m.viewingFile != nil {
 			m.mode = ModeLogEntry
@@ -189,7 +218,7 @@ func (m Model) handleTaskViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		
 	// Field edit hotkeys
-	case "T":
+	case "task.edit_title":
 		// Title field (uppercase - different from tags)
 		m.editingField = "title"
 		if m.viewingTask != nil {
@@ -200,13 +229,13 @@ func (m Model) handleTaskViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.editCursor = len(m.editBuffer)
 		m.statusMsg = "Enter title:"
 		
-	case "p":
+	case "task.edit_priority":
 		m.editingField = "priority"
 		m.editBuffer = ""
 		m.editCursor = 0
 		m.statusMsg = "Enter priority (1/2/3):"
 		
-	case "s":
+	case "task.edit_status":
 		m.editingField = "status"
 		m.editBuffer = ""
 		m.editCursor = 0
@@ -216,19 +245,19 @@ func (m Model) handleTaskViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.statusMsg = "Enter status (active/completed/paused/cancelled):"
 		}
 		
-	case "d":
+	case "task.edit_due":
 		m.editingField = "due"
 		m.editBuffer = ""
 		m.editCursor = 0
 		m.statusMsg = "Enter due date (e.g. 2d, 1w, friday, jan 15, 2024-01-15):"
 		
-	case "a":
+	case "task.edit_area":
 		m.editingField = "area"
 		m.editBuffer = ""
 		m.editCursor = 0
 		m.statusMsg = "Enter area (work/personal/etc):"
 		
-	case "e":
+	case "task.edit_estimate":
 		// Estimate field (lowercase for action)
 		if m.viewingTask != nil {
 			m.editingField = "estimate"
@@ -237,7 +266,64 @@ func (m Model) handleTaskViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.statusMsg = "Enter time estimate (1/2/3/5/8/13):"
 		}
 		
-	case "j":
+	case "task.goto_blocker":
+		// Jump to the first unfinished dependency (or the first dependency)
+		if m.viewingTask != nil && len(m.dependencyTasks) > 0 {
+			target := m.dependencyTasks[0]
+			for _, dep := range m.dependencyTasks {
+				if dep.TaskMetadata.Status != denote.TaskStatusDone && dep.TaskMetadata.Status != denote.TaskStatusDropped {
+					target = dep
+					break
+				}
+			}
+			m.viewingTask = &target
+			m.viewingFile = &target.File
+			m.editingField = ""
+			m.editBuffer = ""
+			m.loadTaskDependencies()
+			m.statusMsg = fmt.Sprintf("Viewing blocking task #%d", target.TaskMetadata.IndexID)
+		}
+		
+	case "task.start_timer":
+		// Start timer - only for tasks
+		if m.viewingTask != nil {
+			m.startTimer(m.viewingFile.Path)
+		}
+		
+	case "task.stop_timer":
+		// Stop timer - only for tasks
+		if m.viewingTask != nil {
+			m.stopTimer(m.viewingFile.Path)
+		}
+		
+	case "task.toggle_item":
+		// Toggle a checklist item by number - only for tasks
+		if m.viewingTask != nil && len(m.viewingTask.Checklist) > 0 {
+			m.editingField = "check"
+			m.editBuffer = ""
+			m.editCursor = 0
+			m.statusMsg = fmt.Sprintf("Toggle checklist item (1-%d):", len(m.viewingTask.Checklist))
+		}
+		
+	case "task.toggle_item_1", "task.toggle_item_2", "task.toggle_item_3",
+		"task.toggle_item_4", "task.toggle_item_5", "task.toggle_item_6",
+		"task.toggle_item_7", "task.toggle_item_8", "task.toggle_item_9":
+		// Quick toggle of the first nine checklist items
+		if m.viewingTask != nil && len(m.viewingTask.Checklist) > 0 {
+			n, _ := strconv.Atoi(strings.TrimPrefix(action, "task.toggle_item_"))
+			m.toggleChecklistItem(n)
+		}
+		
+	case "task.edit_recurrence":
+		// Recurrence field - only for tasks
+		if m.viewingTask != nil {
+			m.editingField = "recurrence"
+			m.editBuffer = m.viewingTask.TaskMetadata.Recurrence
+			m.editCursor = len(m.editBuffer)
+			m.statusMsg = "Enter recurrence (e.g. every 2w, monthly on 15, weekdays, 3d after done):"
+		}
+		
+	case "task.set_project":
 		// Project selection - only for tasks
 		if m.viewingTask != nil {
 			// Load projects and switch to selection mode
@@ -251,7 +337,7 @@ func (m Model) handleTaskViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-	case "t":
+	case "task.edit_tags":
 		// Tags field (lowercase for action)
 		m.editingField = "tags"
 		// Pre-fill with current tags, filtering out system tags
@@ -301,7 +387,7 @@ func (m Model) handleTaskViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.editCursor = len(m.editBuffer)
 		m.statusMsg = "Enter tags (" + MsgSpaceSeparated + "):"
 		
-	case "r":
+	case "task.rename":
 		// Rename file to match current metadata tags
 		if m.viewingTask != nil {
 			// Build tag list including 'task' tag
@@ -351,7 +437,7 @@ func (m Model) handleTaskViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
-	case "?":
+	case "task.help":
 		m.mode = ModeHelp
 	}
 	
@@ -0,0 +1,226 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pdxmph/denote-tasks/internal/denote"
+	"github.com/pdxmph/denote-tasks/internal/search"
+)
+
+// startTextSearch enters full-text search mode, indexing the notes
+// directory if it hasn't been since the last scan
+func (m *Model) startTextSearch() {
+	m.mode = ModeTextSearch
+	if m.textIndex == nil {
+		m.buildTextIndex()
+	}
+	m.runTextSearch()
+}
+
+// buildTextIndex indexes the bodies of every task and project
+func (m *Model) buildTextIndex() {
+	m.textIndex = search.New()
+	for _, f := range m.files {
+		if f.IsTask() {
+			if task, err := denote.ParseTaskFile(f.Path); err == nil {
+				m.textIndex.AddTask(task)
+			}
+		} else if f.IsProject() {
+			if project, err := denote.ParseProjectFile(f.Path); err == nil {
+				m.textIndex.AddProject(project)
+			}
+		}
+	}
+}
+
+// runTextSearch searches for textInput, keeping the cursor in range
+func (m *Model) runTextSearch() {
+	m.textResults = m.textIndex.Search(m.textInput)
+	if m.textCursor >= len(m.textResults) {
+		m.textCursor = len(m.textResults) - 1
+	}
+	if m.textCursor < 0 {
+		m.textCursor = 0
+	}
+}
+
+// returnFromTextSearch goes back to the search results after viewing a
+// result, re-indexing in case it was edited
+func (m *Model) returnFromTextSearch() {
+	m.returnToSearch = false
+	m.viewingTask = nil
+	m.viewingProject = nil
+	m.viewingFile = nil
+	m.textIndex = nil
+	m.startTextSearch()
+}
+
+func (m Model) handleTextSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.mode = ModeNormal
+		m.textInput = ""
+		m.textResults = nil
+		m.textCursor = 0
+
+	case "up", "ctrl+p":
+		if m.textCursor > 0 {
+			m.textCursor--
+		}
+
+	case "down", "ctrl+n":
+		if m.textCursor < len(m.textResults)-1 {
+			m.textCursor++
+		}
+
+	case "enter":
+		if m.textCursor >= len(m.textResults) {
+			return m, nil
+		}
+		file := m.textResults[m.textCursor].Doc.File
+		if file.IsTask() {
+			task, err := denote.ParseTaskFile(file.Path)
+			if err != nil {
+				m.statusMsg = fmt.Sprintf("Error loading task: %v", err)
+				return m, nil
+			}
+			m.mode = ModeTaskView
+			m.viewingTask = task
+			m.viewingProject = nil
+			m.viewingFile = &file
+			m.editingField = ""
+			m.editBuffer = ""
+			m.loadTaskDependencies()
+		} else {
+			project, err := denote.ParseProjectFile(file.Path)
+			if err != nil {
+				m.statusMsg = fmt.Sprintf("Error loading project: %v", err)
+				return m, nil
+			}
+			m.mode = ModeProjectView
+			m.viewingTask = nil
+			m.viewingProject = project
+			m.viewingFile = &file
+			m.editingField = ""
+			m.editBuffer = ""
+			m.projectViewTab = 0
+			m.loadProjectTasks()
+		}
+		m.returnToSearch = true
+
+	case "backspace":
+		if len(m.textInput) > 0 {
+			m.textInput = m.textInput[:len(m.textInput)-1]
+			m.textCursor = 0
+			m.runTextSearch()
+		}
+
+	default:
+		if len(msg.String()) == 1 {
+			m.textInput += msg.String()
+			m.textCursor = 0
+			m.runTextSearch()
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) renderTextSearch() string {
+	var sections []string
+
+	title := titleStyle.Render("Full-Text Search")
+	status := fmt.Sprintf("%d matches in %d files", len(m.textResults), m.textIndex.Len())
+	if m.statusMsg != "" {
+		status += " | " + m.statusMsg
+	}
+	sections = append(sections, title, statusStyle.Render(status), "")
+
+	// Each result takes two lines: the title and the snippet
+	visible := (m.height - HeaderFooterHeight) / 2
+	if visible < 1 {
+		visible = DefaultVisibleHeight / 2
+	}
+	start := 0
+	if m.textCursor >= visible {
+		start = m.textCursor - visible + 1
+	}
+	end := start + visible
+	if end > len(m.textResults) {
+		end = len(m.textResults)
+	}
+
+	var lines []string
+	for i := start; i < end; i++ {
+		lines = append(lines, m.renderTextResult(i)...)
+	}
+	if len(m.textResults) == 0 && m.textInput != "" {
+		lines = append(lines, helpStyle.Render("No matches"))
+	}
+	sections = append(sections, strings.Join(lines, "\n"))
+
+	prompt := fmt.Sprintf("\nSearch: %s█", m.textInput)
+	help := helpStyle.Render("  (all words must match • ↑/↓ to move • Enter to open • Esc to close)")
+	sections = append(sections, prompt+help)
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+// renderTextResult renders a result's title line and, unless the match
+// is in the title, the snippet with the matching words highlighted
+func (m Model) renderTextResult(index int) []string {
+	r := m.textResults[index]
+
+	selector := " "
+	if index == m.textCursor {
+		selector = ">"
+	}
+	kind := "task"
+	if r.Doc.File.IsProject() {
+		kind = "project"
+	}
+
+	var where string
+	switch r.Snippet.Field {
+	case search.FieldTags:
+		where = "tags"
+	case search.FieldBody:
+		where = fmt.Sprintf("line %d", r.Snippet.Line)
+	}
+
+	style := baseStyle
+	if r.Doc.File.IsProject() {
+		style = projectStyle
+	}
+	prefix := fmt.Sprintf("%s %-7s ", selector, kind)
+	var line string
+	switch {
+	case index == m.textCursor:
+		line = selectedStyle.Render(prefix + r.Doc.Title)
+	case where == "":
+		line = style.Render(prefix) + highlightSnippet(r.Snippet, style)
+	default:
+		line = style.Render(prefix + r.Doc.Title)
+	}
+
+	if where == "" {
+		return []string{line, ""}
+	}
+	return []string{line, "          " + helpStyle.Render(where+": ") + highlightSnippet(r.Snippet, helpStyle)}
+}
+
+// highlightSnippet renders a snippet in style with its matches picked out
+func highlightSnippet(s search.Snippet, style lipgloss.Style) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range s.Matches {
+		b.WriteString(style.Render(s.Text[last:match[0]]))
+		b.WriteString(matchStyle.Render(s.Text[match[0]:match[1]]))
+		last = match[1]
+	}
+	b.WriteString(style.Render(s.Text[last:]))
+	return b.String()
+}
@@ -0,0 +1,362 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pdxmph/denote-tasks/internal/config"
+)
+
+// Palette assigns a color to each role in the TUI. A color is an ANSI 256
+// color number such as "214", a hex color such as "#ff8700", or empty for
+// the terminal's default.
+type Palette struct {
+	Text               string // Normal text
+	Title              string // Screen titles
+	Selected           string // Selected row
+	SelectedBackground string
+	Help               string // Key hints and menus
+	Status             string // Status line and field labels
+	Muted              string // Hints, empty values, inactive tabs
+	Label              string // Task detail labels and log entries
+	Value              string // Task detail values
+	Done               string
+	Paused             string
+	Delegated          string
+	Dropped            string
+	Overdue            string
+	Soon               string // Due within soon_horizon days
+	Priority1          string
+	Priority2          string
+	Priority3          string
+	Project            string
+	Active             string // Active projects
+	Accent             string // Active tabs, popup borders, today
+	Match              string // Full-text search matches
+	Error              string // Errors and delete confirmations
+	EditBackground     string // Field being edited
+	PopupBackground    string
+}
+
+// themes are the built-in palettes, by the name used for tui.theme
+var themes = map[string]Palette{
+	"default": {
+		Text:            "252",
+		Title:           "99",
+		Selected:        "214",
+		Help:            "248",
+		Status:          "245",
+		Muted:           "241",
+		Label:           "99",
+		Value:           "230",
+		Done:            "70",
+		Paused:          "243",
+		Delegated:       "33",
+		Dropped:         "240",
+		Overdue:         "196",
+		Soon:            "214",
+		Priority1:       "196",
+		Priority2:       "214",
+		Priority3:       "248",
+		Project:         "135",
+		Active:          "51",
+		Accent:          "214",
+		Match:           "226",
+		Error:           "196",
+		EditBackground:  "62",
+		PopupBackground: "235",
+	},
+	// Brighter, softer colors for dark terminals
+	"dark": {
+		Text:            "255",
+		Title:           "141",
+		Selected:        "220",
+		Help:            "250",
+		Status:          "247",
+		Muted:           "244",
+		Label:           "141",
+		Value:           "230",
+		Done:            "114",
+		Paused:          "246",
+		Delegated:       "75",
+		Dropped:         "242",
+		Overdue:         "203",
+		Soon:            "215",
+		Priority1:       "203",
+		Priority2:       "215",
+		Priority3:       "250",
+		Project:         "177",
+		Active:          "87",
+		Accent:          "215",
+		Match:           "226",
+		Error:           "203",
+		EditBackground:  "60",
+		PopupBackground: "236",
+	},
+	// Darker colors that read on light terminals
+	"light": {
+		Text:            "235",
+		Title:           "55",
+		Selected:        "166",
+		Help:            "240",
+		Status:          "242",
+		Muted:           "246",
+		Label:           "55",
+		Value:           "236",
+		Done:            "28",
+		Paused:          "244",
+		Delegated:       "25",
+		Dropped:         "248",
+		Overdue:         "160",
+		Soon:            "130",
+		Priority1:       "160",
+		Priority2:       "130",
+		Priority3:       "240",
+		Project:         "90",
+		Active:          "30",
+		Accent:          "166",
+		Match:           "127",
+		Error:           "160",
+		EditBackground:  "153",
+		PopupBackground: "254",
+	},
+	// The 16 basic colors, bright variants, with a solid selection bar
+	"high-contrast": {
+		Text:               "15",
+		Title:              "14",
+		Selected:           "0",
+		SelectedBackground: "11",
+		Help:               "15",
+		Status:             "15",
+		Muted:              "7",
+		Label:              "14",
+		Value:              "15",
+		Done:               "10",
+		Paused:             "7",
+		Delegated:          "12",
+		Dropped:            "7",
+		Overdue:            "9",
+		Soon:               "11",
+		Priority1:          "9",
+		Priority2:          "11",
+		Priority3:          "15",
+		Project:            "13",
+		Active:             "14",
+		Accent:             "11",
+		Match:              "11",
+		Error:              "9",
+		EditBackground:     "4",
+		PopupBackground:    "0",
+	},
+	// Terminal colors only; emphasis comes from bold and reverse video
+	"minimal": {},
+}
+
+// paletteRoles maps the keys of the [tui.colors] config table to palette
+// fields
+var paletteRoles = map[string]func(p *Palette) *string{
+	"text":                func(p *Palette) *string { return &p.Text },
+	"title":               func(p *Palette) *string { return &p.Title },
+	"selected":            func(p *Palette) *string { return &p.Selected },
+	"selected_background": func(p *Palette) *string { return &p.SelectedBackground },
+	"help":                func(p *Palette) *string { return &p.Help },
+	"status":              func(p *Palette) *string { return &p.Status },
+	"muted":               func(p *Palette) *string { return &p.Muted },
+	"label":               func(p *Palette) *string { return &p.Label },
+	"value":               func(p *Palette) *string { return &p.Value },
+	"done":                func(p *Palette) *string { return &p.Done },
+	"paused":              func(p *Palette) *string { return &p.Paused },
+	"delegated":           func(p *Palette) *string { return &p.Delegated },
+	"dropped":             func(p *Palette) *string { return &p.Dropped },
+	"overdue":             func(p *Palette) *string { return &p.Overdue },
+	"soon":                func(p *Palette) *string { return &p.Soon },
+	"p1":                  func(p *Palette) *string { return &p.Priority1 },
+	"p2":                  func(p *Palette) *string { return &p.Priority2 },
+	"p3":                  func(p *Palette) *string { return &p.Priority3 },
+	"project":             func(p *Palette) *string { return &p.Project },
+	"active":              func(p *Palette) *string { return &p.Active },
+	"accent":              func(p *Palette) *string { return &p.Accent },
+	"match":               func(p *Palette) *string { return &p.Match },
+	"error":               func(p *Palette) *string { return &p.Error },
+	"edit_background":     func(p *Palette) *string { return &p.EditBackground },
+	"popup_background":    func(p *Palette) *string { return &p.PopupBackground },
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Styles used throughout the TUI, set from the palette by applyTheme
+var (
+	baseStyle           lipgloss.Style
+	titleStyle          lipgloss.Style
+	selectedStyle       lipgloss.Style
+	helpStyle           lipgloss.Style
+	statusStyle         lipgloss.Style
+	hintStyle           lipgloss.Style
+	errorStyle          lipgloss.Style
+	dangerStyle         lipgloss.Style // Delete confirmations
+	soonStyle           lipgloss.Style
+	doneStyle           lipgloss.Style
+	pausedStyle         lipgloss.Style
+	delegatedStyle      lipgloss.Style
+	droppedStyle        lipgloss.Style
+	overdueStyle        lipgloss.Style
+	priorityHighStyle   lipgloss.Style
+	priorityMediumStyle lipgloss.Style
+	priorityLowStyle    lipgloss.Style
+	projectStyle        lipgloss.Style
+	cyanStyle           lipgloss.Style // Active projects
+
+	// Task and project detail views
+	fieldLabelStyle lipgloss.Style
+	fieldValueStyle lipgloss.Style
+	editingStyle    lipgloss.Style
+	logEntryStyle   lipgloss.Style
+
+	// Project view tabs
+	activeTabStyle   lipgloss.Style
+	inactiveTabStyle lipgloss.Style
+
+	// Date, tag and estimate popups
+	popupStyle lipgloss.Style
+
+	boardHeaderStyle       lipgloss.Style
+	boardActiveHeaderStyle lipgloss.Style
+
+	agendaTodayStyle      lipgloss.Style
+	agendaOtherMonthStyle lipgloss.Style
+
+	// Matched words in full-text search snippets
+	matchStyle lipgloss.Style
+)
+
+func init() {
+	applyTheme(themes["default"])
+}
+
+// loadTheme applies the configured theme with any [tui.colors] overrides.
+// With NO_COLOR set, or --no-color, colors are dropped altogether.
+func loadTheme(cfg config.TUIConfig) error {
+	name := cfg.Theme
+	if name == "" {
+		name = "default"
+	}
+	palette, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme: %s", name)
+	}
+
+	roles := make([]string, 0, len(cfg.Colors))
+	for role := range cfg.Colors {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		field, ok := paletteRoles[role]
+		if !ok {
+			return fmt.Errorf("unknown color role in tui.colors: %s", role)
+		}
+		color := cfg.Colors[role]
+		if !validColor(color) {
+			return fmt.Errorf("invalid color for tui.colors.%s: %q (use 0-255 or #rrggbb)", role, color)
+		}
+		*field(&palette) = color
+	}
+
+	if cfg.NoColor || os.Getenv("NO_COLOR") != "" {
+		palette = Palette{}
+	}
+
+	applyTheme(palette)
+	return nil
+}
+
+// validColor reports whether c is empty, an ANSI 256 color number or a
+// hex color
+func validColor(c string) bool {
+	if c == "" || hexColor.MatchString(c) {
+		return true
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// color converts a palette color, leaving empty colors to the terminal
+func color(c string) lipgloss.TerminalColor {
+	if c == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(c)
+}
+
+// foreground is a style with just a foreground color
+func foreground(c string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(color(c))
+}
+
+// applyTheme sets every style from p
+func applyTheme(p Palette) {
+	baseStyle = foreground(p.Text)
+	titleStyle = foreground(p.Title).Bold(true)
+	helpStyle = foreground(p.Help)
+	statusStyle = foreground(p.Status)
+	hintStyle = foreground(p.Muted).Italic(true)
+	errorStyle = foreground(p.Error)
+	dangerStyle = foreground(p.Error).Bold(true)
+	soonStyle = foreground(p.Soon)
+
+	// Without selection colors, the selection is shown in reverse video
+	selectedStyle = foreground(p.Selected).Background(color(p.SelectedBackground)).Bold(true)
+	if p.Selected == "" && p.SelectedBackground == "" {
+		selectedStyle = selectedStyle.Reverse(true)
+	}
+
+	doneStyle = foreground(p.Done)
+	pausedStyle = foreground(p.Paused)
+	delegatedStyle = foreground(p.Delegated)
+	droppedStyle = foreground(p.Dropped)
+	overdueStyle = foreground(p.Overdue).Bold(true)
+
+	priorityHighStyle = foreground(p.Priority1).Bold(true)
+	priorityMediumStyle = foreground(p.Priority2)
+	priorityLowStyle = foreground(p.Priority3)
+
+	projectStyle = foreground(p.Project).Bold(true)
+	cyanStyle = foreground(p.Active)
+
+	fieldLabelStyle = foreground(p.Label).Bold(true)
+	fieldValueStyle = foreground(p.Value)
+	editingStyle = foreground(p.Value).Background(color(p.EditBackground)).Bold(true)
+	logEntryStyle = foreground(p.Label)
+
+	tabStyle := lipgloss.NewStyle().
+		Padding(0, 2).
+		Border(lipgloss.NormalBorder(), false, false, true, false)
+	activeTabStyle = tabStyle.Copy().
+		Foreground(color(p.Accent)).
+		BorderForeground(color(p.Accent)).
+		Bold(true)
+	inactiveTabStyle = tabStyle.Copy().
+		Foreground(color(p.Muted))
+
+	popupStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color(p.Accent)).
+		Background(color(p.PopupBackground)).
+		Foreground(color(p.Text)).
+		Padding(1, 2)
+
+	boardHeaderStyle = foreground(p.Text).Bold(true).Underline(true)
+	boardActiveHeaderStyle = foreground(p.Accent).Bold(true).Underline(true)
+
+	agendaTodayStyle = foreground(p.Accent).Bold(true)
+	agendaOtherMonthStyle = foreground(p.Muted)
+
+	matchStyle = foreground(p.Match).Bold(true)
+	if p.Match == "" {
+		matchStyle = matchStyle.Underline(true)
+	}
+}
@@ -10,60 +10,6 @@ import (
 	"github.com/pdxmph/denote-tasks/internal/denote"
 )
 
-var (
-	// Base style for normal text - brighter for better readability
-	baseStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("252"))  // Light gray, almost white
-		
-	titleStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("99"))
-		
-	selectedStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("214")). // Orange for selected
-		Bold(true)
-		
-	helpStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("248"))  // Brighter for better readability in menus
-		
-	statusStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("245"))
-		
-	// Task-specific styles
-	doneStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("70"))  // Green for done tasks
-		
-	pausedStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("243"))  // Dim gray for paused tasks
-		
-	delegatedStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("33"))   // Blue for delegated tasks
-		
-	droppedStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240"))  // Dark gray for dropped tasks
-		
-	overdueStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("196")). // Red for overdue
-		Bold(true)
-		
-	priorityHighStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("196")). // Red for p1
-		Bold(true)
-		
-	priorityMediumStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("214"))  // Orange for p2
-		
-	priorityLowStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("248"))  // Lighter gray for p3, better contrast
-		
-	projectStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("135")). // Purple for projects
-		Bold(true)
-		
-	cyanStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("51"))   // Cyan for active projects
-)
-
 func (m Model) renderNormal() string {
 	var sections []string
 	
@@ -89,12 +35,17 @@ func (m Model) renderHeader() string {
 		titleText = "Denote Projects"
 	}
 	title := titleStyle.Render(titleText)
+	if timer := m.renderRunningTimer(); timer != "" {
+		title += "  " + timer
+	}
 	
 	// Filter info
 	filterInfo := []string{}
 	if m.searchQuery != "" {
 		if strings.HasPrefix(m.searchQuery, "#") {
 			filterInfo = append(filterInfo, fmt.Sprintf("Tag: %s", strings.TrimPrefix(m.searchQuery, "#")))
+		} else if m.searchExpr != nil {
+			filterInfo = append(filterInfo, fmt.Sprintf("Query: %s", m.searchExpr.Source))
 		} else {
 			filterInfo = append(filterInfo, fmt.Sprintf("Search: %s", m.searchQuery))
 		}
@@ -112,6 +63,10 @@ func (m Model) renderHeader() string {
 		filterInfo = append(filterInfo, fmt.Sprintf("Soon: %dd", m.config.SoonHorizon))
 	}
 	
+	if m.viewName != "" {
+		filterInfo = append([]string{fmt.Sprintf("View: %s", m.viewName)}, filterInfo...)
+	}
+	
 	// Sort info
 	sortInfo := fmt.Sprintf(SortFormatString, m.sortBy)
 	if m.reverseSort {
@@ -130,6 +85,9 @@ func (m Model) renderHeader() string {
 		status += " | " + strings.Join(filterInfo, " | ")
 	}
 	status += " | " + sortInfo
+	if len(m.scanErrors) > 0 {
+		status += " | " + overdueStyle.Render(fmt.Sprintf("⚠ %d parse errors", len(m.scanErrors)))
+	}
 	if m.statusMsg != "" {
 		status += " | " + m.statusMsg
 	}
@@ -139,6 +97,24 @@ func (m Model) renderHeader() string {
 	return lipgloss.JoinVertical(lipgloss.Left, title, statusLine, "")
 }
 
+// renderRunningTimer renders the running-timer indicator for the header
+func (m Model) renderRunningTimer() string {
+	if len(m.runningTimers) == 0 {
+		return ""
+	}
+	
+	t := m.runningTimers[0]
+	entry := t.RunningTimer()
+	if entry == nil {
+		return ""
+	}
+	text := fmt.Sprintf("⏱ #%d %s %s", t.IndexID, truncate(t.TaskMetadata.Title, ColumnWidthTitleAlt), denote.FormatDuration(entry.Duration(time.Now())))
+	if len(m.runningTimers) > 1 {
+		text += fmt.Sprintf(" (+%d)", len(m.runningTimers)-1)
+	}
+	return overdueStyle.Render(text)
+}
+
 func (m Model) renderFileList() string {
 	if len(m.filtered) == 0 {
 		msg := "No tasks found"
@@ -202,6 +178,11 @@ func (m Model) renderFileList() string {
 			lines = append(lines, helpStyle.Render(divider))
 		}
 		
+		// Show a heading where a new group starts
+		if m.groups != nil && i < len(m.groups) && (i == start || m.groups[i] != m.groups[i-1]) {
+			lines = append(lines, m.groupHeading(m.groups[i]))
+		}
+		
 		line := m.renderFileLine(i)
 		lines = append(lines, line)
 	}
@@ -344,7 +325,7 @@ func (m Model) renderTaskLine(index int, file denote.File, task *denote.Task) st
 			isOverdue = true
 		} else if denote.IsDueSoon(task.TaskMetadata.DueDate, m.config.SoonHorizon) {
 			// Orange for soon
-			due = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(dateStr)
+			due = soonStyle.Render(dateStr)
 		} else {
 			// Normal color for future
 			due = dateStr
@@ -371,16 +352,36 @@ func (m Model) renderTaskLine(index int, file denote.File, task *denote.Task) st
 	// Build the line with proper spacing
 	// Note: priority and due already have color codes, so we use %s instead of fixed width
 	// Format: selector status priority estimate due title tags area project
-	line := fmt.Sprintf("%s %s %s %s %s  %*s %*s %*s %s", 
+	var line string
+	if len(m.columns) > 0 {
+		cells := map[string]string{
+			"id":       fmt.Sprintf("%3d", task.IndexID),
+			"status":   status,
+			"priority": priority,
+			"estimate": estimate,
+			"due":      due,
+			"title":    fmt.Sprintf("%*s", -ColumnWidthTitle, titleWithChecklist(title, task, ColumnWidthTitle)),
+			"tags":     fmt.Sprintf("%*s", -ColumnWidthTags, truncate(tagStr, ColumnWidthTags)),
+			"area":     fmt.Sprintf("%*s", -ColumnWidthArea, truncate(area, ColumnWidthArea)),
+			"project":  projectName,
+		}
+		parts := []string{selector}
+		for _, column := range m.columns {
+			parts = append(parts, cells[column])
+		}
+		line = strings.TrimRight(strings.Join(parts, " "), " ")
+	} else {
+		line = fmt.Sprintf("%s %s %s %s %s  %*s %*s %*s %s", 
 		selector,
 		status, 
 		priority, 
 		estimate,                                      // Right after priority
 		due,                                           // After estimate
-		-ColumnWidthTitle, truncate(title, ColumnWidthTitle),     // Good room for title (with 2 spaces before)
+		-ColumnWidthTitle, titleWithChecklist(title, task, ColumnWidthTitle), // Good room for title (with 2 spaces before)
 		-ColumnWidthTags, truncate(tagStr, ColumnWidthTags),    // Tags
 		-ColumnWidthArea, truncate(area, ColumnWidthArea),      // Area (truncated for consistency)
 		projectName)                                   // Project at the very end
+	}
 	
 	// Apply overall styling
 	if index == m.cursor {
@@ -524,7 +525,7 @@ func (m Model) renderProjectLine(index int, file denote.File, project *denote.Pr
 		if denote.IsOverdue(project.ProjectMetadata.DueDate) {
 			dueDisplay = overdueStyle.Render(dateStr)
 		} else if denote.IsDueSoon(project.ProjectMetadata.DueDate, m.config.SoonHorizon) {
-			dueDisplay = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(dateStr)
+			dueDisplay = soonStyle.Render(dateStr)
 		} else if isActive {
 			dueDisplay = cyanStyle.Render(dateStr)
 		} else {
@@ -596,94 +597,76 @@ func (m Model) renderFooter() string {
 		// Show search input at bottom when in search mode
 		prompt := "Search: " + m.searchInput + "█"
 		help := MsgFuzzyMatch
+		if m.searchErr != "" {
+			help = " (" + m.searchErr + ")"
+		} else if m.searchExpr != nil {
+			help = MsgQueryMatch
+		}
 		return "\n" + prompt + helpStyle.Render(help)
 	}
 	
-	// Show appropriate hotkeys based on current view
-	var help []string
+	// Show appropriate hotkeys based on current view, as currently bound
+	k := m.keys
+	var hints []string
 	if m.projectFilter {
 		// Project mode hotkeys
-		help = []string{
-			"j/k:nav",
-			"/:search",
-			"enter:view",
-			"c:create project",
-			"0-3:priority",
-			"d:due date",
-			"t:tags",
-			"x:delete",
-			"E:edit",
-			"f:filter",
-			"T:tasks",
-			"S:sort",
-			"?:help",
-			"q:quit",
+		hints = []string{
+			k.hint("nav", "list.down", "list.up"),
+			k.hint("search", "list.search"),
+			k.hint("view", "list.open"),
+			k.hint("create project", "list.create"),
+			k.hint("priority", "list.clear_priority", "list.set_priority_1", "list.set_priority_2", "list.set_priority_3"),
+			k.hint("due date", "list.edit_due"),
+			k.hint("tags", "list.edit_tags"),
+			k.hint("delete", "list.delete"),
+			k.hint("undo", "list.undo"),
+			k.hint("edit", "list.external_edit"),
+			k.hint("filter", "list.filter_menu"),
+			k.hint("views", "list.views"),
+			k.hint("full-text", "list.text_search"),
+			k.hint("board", "list.board"),
+			k.hint("agenda", "list.agenda"),
+			k.hint("tasks", "list.tasks"),
+			k.hint("sort", "list.sort_menu"),
+			k.hint("help", "list.help"),
+			k.hint("quit", "list.quit"),
 		}
 	} else {
 		// Task mode hotkeys
-		help = []string{
-			"j/k:nav",
-			"/:search",
-			"enter:preview",
-			"c:create task",
-			"0-3:priority",
-			"s:state",
-			"d:due date",
-			"t:tags",
-			"x:delete",
-			"E:edit",
-			"l:log",
-			"f:filter",
-			"P:projects",
-			"S:sort",
-			"?:help",
-			"q:quit",
+		hints = []string{
+			k.hint("nav", "list.down", "list.up"),
+			k.hint("search", "list.search"),
+			k.hint("preview", "list.open"),
+			k.hint("create task", "list.create"),
+			k.hint("priority", "list.clear_priority", "list.set_priority_1", "list.set_priority_2", "list.set_priority_3"),
+			k.hint("state", "list.state_menu"),
+			k.hint("due date", "list.edit_due"),
+			k.hint("tags", "list.edit_tags"),
+			k.hint("delete", "list.delete"),
+			k.hint("undo", "list.undo"),
+			k.hint("edit", "list.external_edit"),
+			k.hint("log", "list.log"),
+			k.hint("timer", "list.start_timer", "list.stop_timer"),
+			k.hint("filter", "list.filter_menu"),
+			k.hint("views", "list.views"),
+			k.hint("full-text", "list.text_search"),
+			k.hint("board", "list.board"),
+			k.hint("agenda", "list.agenda"),
+			k.hint("projects", "list.projects"),
+			k.hint("sort", "list.sort_menu"),
+			k.hint("help", "list.help"),
+			k.hint("quit", "list.quit"),
 		}
 	}
 	
-	return "\n" + helpStyle.Render(strings.Join(help, " • "))
+	return "\n" + helpStyle.Render(joinHints(hints))
 }
 
 func (m Model) renderHelp() string {
-	help := `
-Denote Tasks - Keyboard Shortcuts
-
-Navigation:
-  j/↓     Move down
-  k/↑     Move up
-  gg      Go to top
-  G       Go to bottom
-
-Actions (lowercase):
-  Enter   Open task/project details
-  c       Create new task/project
-  d       Edit due date
-  e       Edit estimate (tasks only)
-  l       Add log entry (tasks only)
-  r       Toggle sort order
-  s       Change task state (open/done/etc)
-  t       Edit tags
-  u       Update task metadata
-  x       Delete task/project
-  /       Fuzzy search (use #tag for tag search)
-
-Priority:
-  0       Clear priority
-  1/2/3   Set priority (p1/p2/p3)
-
-Filters & Views (uppercase):
-  E       Edit in external editor
-  P       Toggle projects view
-  T       Toggle tasks view
-  S       Sort options menu
-  f       Filter menu (area/priority/state/soon)
-  
-Other:
-  ?       Toggle this help
-  q       Quit
-
-Press any key to continue...`
-
+	help := "\nDenote Tasks - Keyboard Shortcuts\n\n" +
+		m.keys.renderKeyHelp() +
+		"\nPress any key to continue..."
+	
 	return titleStyle.Render("Help") + help
 }
 
@@ -904,13 +887,7 @@ func (m Model) renderConfirmDelete() string {
 		options := `
 
   (y) Yes, delete project and clear task associations
-  (n) No, cancel
-  
-  This action cannot be undone!`
-		
-		dangerStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Bold(true)
+  (n) No, cancel` + m.undoNote()
 		
 		return prompt + warning + affectedInfo + "\n" + dangerStyle.Render(options)
 	}
@@ -925,13 +902,7 @@ func (m Model) renderConfirmDelete() string {
 		options := `
 
   (y) Yes, delete
-  (n) No, cancel
-  
-  This action cannot be undone!`
-		
-		dangerStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Bold(true)
+  (n) No, cancel` + m.undoNote()
 		
 		return prompt + warning + fileName + "\n" + dangerStyle.Render(options)
 	}
@@ -958,17 +929,20 @@ func (m Model) renderConfirmDelete() string {
 	options := `
 
   (y) Yes, delete
-  (n) No, cancel
-  
-  This action cannot be undone!`
-	
-	dangerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("196")).
-		Bold(true)
+  (n) No, cancel` + m.undoNote()
 	
 	return prompt + warning + fileName + "\n" + dangerStyle.Render(options)
 }
 
+// undoNote reminds delete confirmations how to undo, if undo is bound
+func (m Model) undoNote() string {
+	keys := m.keys.keys["list.undo"]
+	if len(keys) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n  \n  Press %s afterwards to undo.", keyLabel(keys[0]))
+}
+
 func (m Model) renderFilterMenu() string {
 	prompt := titleStyle.Render("Filter Options")
 	
@@ -1068,6 +1042,17 @@ func truncate(s string, max int) string {
 	return s[:max-3] + "..."
 }
 
+// titleWithChecklist truncates a task title to max, keeping room for the
+// task's checklist progress (e.g. "[3/7]") at the end
+func titleWithChecklist(title string, task *denote.Task, max int) string {
+	done, total := denote.ChecklistProgress(task.Checklist)
+	if total == 0 {
+		return truncate(title, max)
+	}
+	progress := fmt.Sprintf(" [%d/%d]", done, total)
+	return truncate(title, max-len(progress)) + progress
+}
+
 func (m Model) renderLogEntry() string {
 	if m.loggingFile == nil {
 		return MsgNoTaskSelected